@@ -0,0 +1,152 @@
+package adaptive
+
+import (
+	"fmt"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// ChallengeResult summarizes one finished challenge
+type ChallengeResult struct {
+	Attempts  int
+	HintsUsed int
+	Solved    bool // False when skipped or out of attempts or time
+}
+
+// fluent reports a first-try answer without hints
+func (r ChallengeResult) fluent() bool {
+	return r.Solved && r.Attempts == 1 && r.HintsUsed == 0
+}
+
+// Performance summarizes one completed exercise
+type Performance struct {
+	ExerciseID           string
+	Score                float64
+	TimeRatio            float64 // Time spent over EstimatedTime; 0 if there is no estimate
+	AttemptsPerChallenge float64
+	HintsPerChallenge    float64
+}
+
+// PerformanceOf summarizes a completed exercise's progress
+func PerformanceOf(exercise models.Exercise, progress models.LearningProgress) Performance {
+	perf := Performance{ExerciseID: exercise.ID, Score: progress.Score}
+	if exercise.EstimatedTime > 0 {
+		perf.TimeRatio = progress.TimeSpent.Minutes() / float64(exercise.EstimatedTime)
+	}
+	if n := len(exercise.Challenges); n > 0 {
+		perf.AttemptsPerChallenge = float64(progress.Attempts) / float64(n)
+		perf.HintsPerChallenge = float64(progress.HintsUsed) / float64(n)
+	}
+	return perf
+}
+
+// Thresholds for moving between cognitive levels
+const (
+	levelUpWindow   = 2    // Consecutive strong exercises needed to level up
+	levelUpScore    = 85.0 // Minimum score of each of them
+	levelDownScore  = 60.0 // A score below this lowers the level
+	levelDownTries  = 2.5  // As do this many attempts per challenge
+	levelDownHints  = 1.0  // Or this many hints per challenge
+	maxTimeRatioUp  = 1.0  // Leveling up also requires finishing within the estimate
+	struggleAttempt = 3    // Attempts that count as struggling above beginner level
+)
+
+// Engine decides how to pace a learner from their recent performance. Its
+// thresholds depend on the learner's current cognitive level: beginners get
+// support sooner, advanced learners skip ahead sooner.
+type Engine struct {
+	Level models.CognitiveLevel
+
+	changedAt int // Performances seen when Level last changed
+}
+
+// New creates an engine for a learner at the given level
+func New(level models.CognitiveLevel) *Engine {
+	return &Engine{Level: level}
+}
+
+// Support decides after a challenge whether the learner should study an
+// extra worked example before the next one
+func (e *Engine) Support(result ChallengeResult) (string, bool) {
+	switch {
+	case !result.Solved:
+		return "didn't solve the last challenge", true
+	case e.Level == models.Beginner && result.HintsUsed > 0:
+		return fmt.Sprintf("needed %d hint(s) at beginner level", result.HintsUsed), true
+	case e.Level == models.Beginner && result.Attempts >= 2:
+		return fmt.Sprintf("took %d attempts at beginner level", result.Attempts), true
+	case e.Level != models.Advanced && result.Attempts >= struggleAttempt:
+		return fmt.Sprintf("took %d attempts", result.Attempts), true
+	}
+	return "", false
+}
+
+// Skip decides after a challenge how many of the remaining challenges a
+// fluent learner can skip. The final challenge is always kept.
+func (e *Engine) Skip(results []ChallengeResult, remaining int) (int, string) {
+	run := e.fluentRun()
+	if remaining < 2 || len(results) < run {
+		return 0, ""
+	}
+	for _, result := range results[len(results)-run:] {
+		if !result.fluent() {
+			return 0, ""
+		}
+	}
+	return remaining - 1, fmt.Sprintf("solved %d challenge(s) in a row on the first try without hints", run)
+}
+
+// fluentRun returns how many fluent answers in a row justify skipping ahead
+func (e *Engine) fluentRun() int {
+	switch e.Level {
+	case models.Beginner:
+		return 3
+	case models.Intermediate:
+		return 2
+	}
+	return 1
+}
+
+// Adjust decides after an exercise whether to move the learner's level,
+// given every completed exercise oldest first. Only exercises completed
+// since the last change count towards leveling up. On a change it updates
+// Level and returns the action taken.
+func (e *Engine) Adjust(recent []Performance) (models.AdaptiveAction, string, bool) {
+	if len(recent) == 0 {
+		return "", "", false
+	}
+	if e.changedAt > len(recent) {
+		e.changedAt = 0
+	}
+
+	latest := recent[len(recent)-1]
+	if e.Level > models.Beginner {
+		var reason string
+		switch {
+		case latest.Score < levelDownScore:
+			reason = fmt.Sprintf("scored %.0f on %s", latest.Score, latest.ExerciseID)
+		case latest.AttemptsPerChallenge >= levelDownTries:
+			reason = fmt.Sprintf("averaged %.1f attempts per challenge on %s", latest.AttemptsPerChallenge, latest.ExerciseID)
+		case latest.HintsPerChallenge >= levelDownHints:
+			reason = fmt.Sprintf("averaged %.1f hints per challenge on %s", latest.HintsPerChallenge, latest.ExerciseID)
+		}
+		if reason != "" {
+			e.Level--
+			e.changedAt = len(recent)
+			return models.ActionLevelDown, reason, true
+		}
+	}
+
+	if e.Level < models.Advanced && len(recent)-e.changedAt >= levelUpWindow {
+		window := recent[len(recent)-levelUpWindow:]
+		for _, perf := range window {
+			if perf.Score < levelUpScore || perf.HintsPerChallenge > 0 || perf.TimeRatio > maxTimeRatioUp {
+				return "", "", false
+			}
+		}
+		e.Level++
+		e.changedAt = len(recent)
+		return models.ActionLevelUp, fmt.Sprintf("scored %.0f or more within the estimated time and without hints on the last %d exercises", levelUpScore, levelUpWindow), true
+	}
+	return "", "", false
+}
@@ -0,0 +1,290 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Period is the length of time completions are counted over in a timeline
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week" // Starting on Monday
+	Month Period = "month"
+)
+
+// ParsePeriod returns the period with the given name
+func ParsePeriod(name string) (Period, error) {
+	switch period := Period(name); period {
+	case Day, Week, Month:
+		return period, nil
+	}
+	return "", fmt.Errorf("unknown period %q (want day, week or month)", name)
+}
+
+// start returns the start of the period containing t
+func (p Period) start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch p {
+	case Week:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// add moves the start of a period n periods on
+func (p Period) add(start time.Time, n int) time.Time {
+	switch p {
+	case Week:
+		return start.AddDate(0, 0, 7*n)
+	case Month:
+		return start.AddDate(0, n, 0)
+	}
+	return start.AddDate(0, 0, n)
+}
+
+// Options controls what a report covers
+type Options struct {
+	Now     time.Time
+	Period  Period // Length of each timeline entry; Week if empty
+	Periods int    // Timeline entries, ending with the period containing Now
+	Weakest int    // Exercises to rank as weakest
+}
+
+// Report aggregates a learner's training history across sessions
+type Report struct {
+	UserID             string                       `json:"user"`
+	Sessions           int                          `json:"sessions"`
+	ByStatus           map[models.SessionStatus]int `json:"by_status"`
+	ExercisesCompleted int                          `json:"exercises_completed"`
+	AverageScore       float64                      `json:"average_score"`
+	MinutesSpent       float64                      `json:"minutes_spent"`
+	HintDependency     float64                      `json:"hint_dependency"` // Share of challenges posed where a hint was taken
+	Period             Period                       `json:"period"`
+	Timeline           []PeriodCount                `json:"timeline"`
+	Exercises          []ExerciseStats              `json:"exercises"` // Curriculum order, then exercises no longer offered
+	Weakest            []string                     `json:"weakest"`   // Exercise IDs, weakest first
+}
+
+// PeriodCount is the number of exercises completed in one period
+type PeriodCount struct {
+	Start     time.Time `json:"start"`
+	Completed int       `json:"completed"`
+}
+
+// ExerciseStats summarizes every session's work on one exercise
+type ExerciseStats struct {
+	ExerciseID       string    `json:"exercise_id"`
+	Title            string    `json:"title"`
+	Started          int       `json:"started"`
+	Completed        int       `json:"completed"`
+	Scores           []float64 `json:"scores"` // One per completion, oldest first
+	AverageScore     float64   `json:"average_score"`
+	ScoreTrend       float64   `json:"score_trend"` // Latest score less the first
+	HintDependency   float64   `json:"hint_dependency"`
+	AverageMinutes   float64   `json:"average_minutes"` // Per completion
+	EstimatedMinutes int       `json:"estimated_minutes,omitempty"`
+	TimeRatio        float64   `json:"time_ratio,omitempty"` // Average time over the estimate
+}
+
+// exerciseTotals accumulates one exercise's progress before averaging
+type exerciseTotals struct {
+	stats        *ExerciseStats
+	completions  []models.LearningProgress
+	posed, hints int
+}
+
+// Compute builds a report from a learner's sessions. exercises supplies
+// titles and time estimates; exercises practiced that are no longer offered
+// are reported by ID.
+func Compute(userID string, sessions []*models.TrainingSession, exercises []models.Exercise, opts Options) *Report {
+	if opts.Period == "" {
+		opts.Period = Week
+	}
+	report := &Report{
+		UserID:    userID,
+		Sessions:  len(sessions),
+		ByStatus:  make(map[models.SessionStatus]int),
+		Period:    opts.Period,
+		Exercises: []ExerciseStats{},
+		Weakest:   []string{},
+	}
+
+	totals := make(map[string]*exerciseTotals)
+	var order []string
+	total := func(id string) *exerciseTotals {
+		if totals[id] == nil {
+			totals[id] = &exerciseTotals{stats: &ExerciseStats{ExerciseID: id, Title: id, Scores: []float64{}}}
+			order = append(order, id)
+		}
+		return totals[id]
+	}
+	for _, exercise := range exercises {
+		t := total(exercise.ID)
+		t.stats.Title = exercise.Title
+		t.stats.EstimatedMinutes = exercise.EstimatedTime
+	}
+
+	var timeSpent time.Duration
+	var posed, hinted int
+	for _, session := range sessions {
+		report.ByStatus[session.Status]++
+		for _, progress := range session.Progress {
+			if progress.ExerciseID == "" {
+				continue // Never reached
+			}
+			t := total(progress.ExerciseID)
+			t.stats.Started++
+			timeSpent += progress.TimeSpent
+
+			p, h := hintUse(progress)
+			t.posed += p
+			t.hints += h
+			posed += p
+			hinted += h
+
+			if progress.CompletedAt != nil {
+				t.completions = append(t.completions, progress)
+			}
+		}
+	}
+
+	report.MinutesSpent = timeSpent.Minutes()
+	report.HintDependency = ratio(hinted, posed)
+	report.Timeline = timeline(totals, opts)
+
+	var scoreSum float64
+	for _, id := range order {
+		t := totals[id]
+		t.summarize()
+		report.ExercisesCompleted += t.stats.Completed
+		for _, score := range t.stats.Scores {
+			scoreSum += score
+		}
+		report.Exercises = append(report.Exercises, *t.stats)
+	}
+	if report.ExercisesCompleted > 0 {
+		report.AverageScore = scoreSum / float64(report.ExercisesCompleted)
+	}
+
+	report.Weakest = weakest(report.Exercises, opts.Weakest)
+	return report
+}
+
+// summarize averages an exercise's completions
+func (t *exerciseTotals) summarize() {
+	sort.SliceStable(t.completions, func(i, j int) bool {
+		return t.completions[i].CompletedAt.Before(*t.completions[j].CompletedAt)
+	})
+
+	stats := t.stats
+	stats.Completed = len(t.completions)
+	stats.HintDependency = ratio(t.hints, t.posed)
+	if stats.Completed == 0 {
+		return
+	}
+
+	var scoreSum float64
+	var timeSum time.Duration
+	for _, progress := range t.completions {
+		stats.Scores = append(stats.Scores, progress.Score)
+		scoreSum += progress.Score
+		timeSum += progress.TimeSpent
+	}
+	stats.AverageScore = scoreSum / float64(stats.Completed)
+	stats.ScoreTrend = stats.Scores[len(stats.Scores)-1] - stats.Scores[0]
+	stats.AverageMinutes = timeSum.Minutes() / float64(stats.Completed)
+	if stats.EstimatedMinutes > 0 {
+		stats.TimeRatio = stats.AverageMinutes / float64(stats.EstimatedMinutes)
+	}
+}
+
+// hintUse counts the challenges posed in one exercise's progress and those
+// where the learner took a hint. Sessions saved before challenges were
+// tracked count the exercise as a single challenge.
+func hintUse(progress models.LearningProgress) (posed, hinted int) {
+	if len(progress.Challenges) == 0 {
+		if progress.Attempts == 0 && progress.HintsUsed == 0 {
+			return 0, 0
+		}
+		if progress.HintsUsed > 0 {
+			return 1, 1
+		}
+		return 1, 0
+	}
+	for _, challenge := range progress.Challenges {
+		posed++
+		if challenge.HintsUsed > 0 {
+			hinted++
+		}
+	}
+	return posed, hinted
+}
+
+// timeline counts completions in each of the periods ending with the one
+// containing opts.Now
+func timeline(totals map[string]*exerciseTotals, opts Options) []PeriodCount {
+	if opts.Periods <= 0 {
+		return []PeriodCount{}
+	}
+	last := opts.Period.start(opts.Now)
+	first := opts.Period.add(last, 1-opts.Periods)
+	counts := make([]PeriodCount, opts.Periods)
+	for i := range counts {
+		counts[i].Start = opts.Period.add(first, i)
+	}
+
+	for _, t := range totals {
+		for _, progress := range t.completions {
+			for i := range counts {
+				end := opts.Period.add(counts[i].Start, 1)
+				if !progress.CompletedAt.Before(counts[i].Start) && progress.CompletedAt.Before(end) {
+					counts[i].Completed++
+					break
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// weakest ranks completed exercises from weakest: lowest average score,
+// then most dependent on hints, then slowest against the estimate
+func weakest(exercises []ExerciseStats, n int) []string {
+	var ranked []ExerciseStats
+	for _, stats := range exercises {
+		if stats.Completed > 0 {
+			ranked = append(ranked, stats)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.AverageScore != b.AverageScore {
+			return a.AverageScore < b.AverageScore
+		}
+		if a.HintDependency != b.HintDependency {
+			return a.HintDependency > b.HintDependency
+		}
+		return a.TimeRatio > b.TimeRatio
+	})
+
+	ids := []string{}
+	for i := 0; i < n && i < len(ranked); i++ {
+		ids = append(ids, ranked[i].ExerciseID)
+	}
+	return ids
+}
+
+// ratio divides, returning 0 for an empty whole
+func ratio(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
@@ -0,0 +1,416 @@
+// Package cli implements the trainer's command line: a tree of subcommands
+// sharing flags, a config file and documented exit codes.
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/mastery"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// Exit codes returned by Run, for scripts driving the trainer
+const (
+	ExitOK       = 0
+	ExitError    = 1 // The command failed
+	ExitUsage    = 2 // Unknown command or flag, bad arguments or an invalid config file
+	ExitNotFound = 3 // The user, session or exercise named doesn't exist
+	ExitInUse    = 4 // The session is open in another trainer process
+	ExitDenied   = 5 // Wrong profile passphrase
+)
+
+// App runs trainer commands against the given streams and environment
+type App struct {
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Getenv  func(key string) string
+	HomeDir string           // Holds the default config file and storage directory
+	Now     func() time.Time // Clock for retention and date filters; time.Now if nil
+
+	HTTPClient *http.Client // Sends statements to an LRS; a client with a timeout if nil
+
+	in *bufio.Reader // Stdin, shared by prompts and the trainer
+}
+
+// Main runs the command line in args against the process's streams and
+// environment, returning the exit code
+func Main(args []string) int {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
+		return ExitError
+	}
+	app := &App{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr, Getenv: os.Getenv, HomeDir: homeDir}
+	return app.Run(args)
+}
+
+// Run runs one command line and returns its exit code. Errors are reported
+// on Stderr.
+func (a *App) Run(args []string) int {
+	a.in = bufio.NewReader(a.Stdin)
+	err := a.run(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if exit := (*exitError)(nil); !errors.As(err, &exit) || !exit.reported {
+		fmt.Fprintf(a.Stderr, "Error: %v\n", err)
+	}
+	return exitCode(err)
+}
+
+// command is one subcommand of the trainer
+type command struct {
+	name    string
+	args    string // Positional arguments, for usage
+	summary string
+	flags   func(fs *flag.FlagSet, opts *options)
+	run     func(a *App, opts *options, args []string) error
+}
+
+// commands lists the subcommands in the order usage shows them
+func commands() []command {
+	return []command{
+		{name: "start", args: "", summary: "start a new training session", flags: startFlags, run: (*App).start},
+		{name: "resume", args: "[<session-id>]", summary: "resume a paused or interrupted session", run: (*App).resume},
+		{name: "practice", args: "<exercise-id>", summary: "train a single exercise", flags: trainingFlags, run: (*App).practice},
+		{name: "review", args: "", summary: "review past challenges that are due", run: (*App).review},
+		{name: "list", args: "", summary: "list your sessions", flags: listFlags, run: (*App).list},
+		{name: "delete", args: "[<session-id>...]", summary: "delete sessions by ID or by status and age", flags: deleteFlags, run: (*App).delete},
+		{name: "stats", args: "", summary: "report on your training history", flags: statsFlags, run: (*App).stats},
+		{name: "exercises", args: "", summary: "list the exercises available", flags: jsonFlag, run: (*App).exercises},
+		{name: "config", args: "[init|path]", summary: "show the effective configuration, or write a config file", flags: trainingFlags, run: (*App).config},
+		{name: "user", args: "add|list|switch|set [<user>]", summary: "manage user profiles", flags: userFlags, run: (*App).user},
+		{name: "report", args: "", summary: "render a progress report and certificate for a session", flags: reportFlags, run: (*App).report},
+		{name: "xapi", args: "export|flush [<id>...]", summary: "export sessions as xAPI statements, or send buffered ones", flags: xapiFlags, run: (*App).xapi},
+		{name: "serve", args: "", summary: "serve the trainer in the browser and as a JSON API", flags: serveFlags, run: (*App).serve},
+		{name: "migrate", args: "", summary: "upgrade saved sessions to the current file format", run: (*App).migrate},
+		{name: "help", args: "", summary: "show this help", run: (*App).help},
+	}
+}
+
+// options holds every flag a command line may set
+type options struct {
+	// Global
+	user       string
+	storageDir string
+	configPath string
+
+	// Training settings
+	maxAttempts      int
+	noHints          bool
+	level            string
+	timeLimit        time.Duration
+	timeFactor       float64
+	masteryThreshold float64
+	from             string
+
+	json bool
+
+	// Session selection
+	status    string
+	since     string
+	until     string
+	sortBy    string
+	reverse   bool
+	archived  bool
+	olderThan Age
+	dryRun    bool
+
+	// Stats
+	period  string
+	periods int
+
+	// Reports
+	session  string
+	format   string
+	output   string
+	template string
+	verify   string
+
+	// xAPI
+	send bool
+
+	// Server
+	addr        string
+	allowRemote bool
+
+	// Profile settings
+	name       string
+	passphrase bool
+
+	set map[string]bool // Flags given explicitly
+}
+
+// globalFlags registers the flags every command accepts, before or after
+// its name. Values already given before the name are kept as defaults.
+func globalFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.user, "user", opts.user, "run as this user (default $TRAINER_USER, or the user chosen with 'user switch')")
+	fs.StringVar(&opts.storageDir, "storage-dir", opts.storageDir, "directory for profiles and sessions (default ~/.claude-trainer)")
+	fs.StringVar(&opts.configPath, "config", opts.configPath, "config file (default $TRAINER_CONFIG or ~/.claude-trainer/config.json)")
+}
+
+// trainingFlags registers the flags that adjust trainer settings
+func trainingFlags(fs *flag.FlagSet, opts *options) {
+	fs.IntVar(&opts.maxAttempts, "max-attempts", 3, "attempts allowed per challenge")
+	fs.BoolVar(&opts.noHints, "no-hints", false, "don't offer hints")
+	fs.StringVar(&opts.level, "level", "", "cognitive level: beginner, intermediate or advanced")
+	fs.DurationVar(&opts.timeLimit, "time-limit", time.Hour, "active time per sitting before auto-pause; 0 for no limit")
+	fs.Float64Var(&opts.timeFactor, "time-factor", 2, "time allowed per exercise, as a multiple of its estimated time")
+	fs.Float64Var(&opts.masteryThreshold, "mastery-threshold", mastery.DefaultThreshold, "mastery each practiced concept must reach before moving on; 0 to only track mastery")
+}
+
+// startFlags registers the flags of start
+func startFlags(fs *flag.FlagSet, opts *options) {
+	trainingFlags(fs, opts)
+	fs.StringVar(&opts.from, "from", "", "start the curriculum at this exercise")
+}
+
+// jsonFlag registers --json for commands with machine-readable output
+func jsonFlag(fs *flag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.json, "json", false, "print JSON instead of text")
+}
+
+// listFlags registers the flags of list
+func listFlags(fs *flag.FlagSet, opts *options) {
+	jsonFlag(fs, opts)
+	fs.StringVar(&opts.status, "status", "", "only sessions with this status: active, paused, completed or abandoned")
+	fs.StringVar(&opts.since, "since", "", "only sessions started on or after this date (2006-01-02) or age ago (7d)")
+	fs.StringVar(&opts.until, "until", "", "only sessions started before this date (2006-01-02) or age ago (7d)")
+	fs.StringVar(&opts.sortBy, "sort", "started", "order by started, activity, progress or status")
+	fs.BoolVar(&opts.reverse, "reverse", false, "reverse the order")
+	fs.BoolVar(&opts.archived, "archived", false, "list archived sessions instead")
+}
+
+// deleteFlags registers the flags of delete
+func deleteFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.status, "status", "", "delete sessions with this status")
+	fs.Var(&opts.olderThan, "older-than", "delete sessions started longer ago than this, such as 30d or 12h")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "show what would be deleted without deleting it")
+}
+
+// statsFlags registers the flags of stats
+func statsFlags(fs *flag.FlagSet, opts *options) {
+	jsonFlag(fs, opts)
+	fs.StringVar(&opts.period, "period", "week", "count completions per day, week or month")
+	fs.IntVar(&opts.periods, "periods", 12, "periods of completions to show")
+}
+
+// reportFlags registers the flags of report
+func reportFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.session, "session", "", "session to report on")
+	fs.StringVar(&opts.format, "format", "html", "html, md or pdf-ready-html")
+	fs.StringVar(&opts.output, "output", "", "write the report to this file instead of printing it")
+	fs.StringVar(&opts.template, "template", "", "template file customizing the report (default from the config file)")
+	fs.StringVar(&opts.verify, "verify", "", "check a certificate's hash against the session instead of rendering")
+}
+
+// xapiFlags registers the flags of xapi
+func xapiFlags(fs *flag.FlagSet, opts *options) {
+	fs.BoolVar(&opts.send, "send", false, "export to the spool and LRS in the config file instead of printing")
+}
+
+// serveFlags registers the flags of serve
+func serveFlags(fs *flag.FlagSet, opts *options) {
+	fs.StringVar(&opts.addr, "addr", "127.0.0.1:8080", "address to listen on")
+	fs.BoolVar(&opts.allowRemote, "allow-remote", false, "allow listening on an address other machines can reach; anyone who reaches it can run code on this one")
+}
+
+// userFlags registers the flags of user add and user set
+func userFlags(fs *flag.FlagSet, opts *options) {
+	trainingFlags(fs, opts)
+	fs.StringVar(&opts.name, "name", "", "display name")
+	fs.BoolVar(&opts.passphrase, "passphrase", false, "protect the profile with a passphrase (prompted; enter nothing to remove it)")
+}
+
+// run parses a command line and runs its command
+func (a *App) run(args []string) error {
+	opts := &options{set: make(map[string]bool)}
+	global := a.flagSet("trainer", opts)
+	global.Usage = func() { a.usage(a.Stderr) }
+	if err := global.Parse(args); err != nil {
+		return flagError(err)
+	}
+	args = global.Args()
+
+	name := "start"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		return usagef("unknown command %q; run 'trainer help' for the list", name)
+	}
+
+	fs := a.flagSet("trainer "+cmd.name, opts)
+	if cmd.flags != nil {
+		cmd.flags(fs, opts)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: trainer %s [flags] %s\n\n%s\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return flagError(err)
+	}
+	global.Visit(func(f *flag.Flag) { opts.set[f.Name] = true })
+	fs.Visit(func(f *flag.Flag) { opts.set[f.Name] = true })
+
+	return cmd.run(a, opts, positional)
+}
+
+// flagSet creates a flag set reporting to Stderr, with the global flags
+func (a *App) flagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	globalFlags(fs, opts)
+	return fs
+}
+
+// parseInterspersed parses flags that may come before, between or after
+// positional arguments, returning the positional ones
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// help shows usage
+func (a *App) help(opts *options, args []string) error {
+	a.usage(a.Stdout)
+	return nil
+}
+
+// usage lists the commands, global flags and exit codes
+func (a *App) usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: trainer [--user <user>] [--storage-dir <dir>] [--config <file>] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10s %-28s %s\n", cmd.name, cmd.args, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "With no command, start runs. Run 'trainer <command> -h' for a command's flags.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes:")
+	for _, code := range []struct {
+		code int
+		text string
+	}{
+		{ExitOK, "success"},
+		{ExitError, "the command failed"},
+		{ExitUsage, "unknown command or flag, bad arguments or an invalid config file"},
+		{ExitNotFound, "the user, session or exercise named doesn't exist"},
+		{ExitInUse, "the session is open in another trainer process"},
+		{ExitDenied, "wrong profile passphrase"},
+	} {
+		fmt.Fprintf(w, "  %d  %s\n", code.code, code.text)
+	}
+}
+
+// exitError carries the exit code a failure should end the process with
+type exitError struct {
+	code     int
+	err      error
+	reported bool // Already shown to the user
+}
+
+// Error implements error
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// usagef reports a bad command line
+func usagef(format string, args ...any) error {
+	return &exitError{code: ExitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// notFoundf reports something named on the command line that doesn't exist
+func notFoundf(format string, args ...any) error {
+	return &exitError{code: ExitNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// flagError reports a flag parsing failure; the flag package has already
+// printed the details and usage
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return &exitError{code: ExitUsage, err: err, reported: true}
+}
+
+// exitCode picks the exit code for an error
+func exitCode(err error) int {
+	var exit *exitError
+	var noProfile *storage.ProfileNotFoundError
+	switch {
+	case errors.As(err, &exit):
+		return exit.code
+	case errors.As(err, &noProfile), errors.Is(err, storage.ErrSessionNotFound):
+		return ExitNotFound
+	case errors.Is(err, storage.ErrSessionInUse):
+		return ExitInUse
+	case errors.Is(err, profile.ErrWrongPassphrase):
+		return ExitDenied
+	}
+	return ExitError
+}
+
+// now returns the current time from the App's clock
+func (a *App) now() time.Time {
+	if a.Now != nil {
+		return a.Now()
+	}
+	return time.Now()
+}
+
+// printf writes formatted output
+func (a *App) printf(format string, args ...any) {
+	fmt.Fprintf(a.Stdout, format, args...)
+}
+
+// println writes a line of output
+func (a *App) println(args ...any) {
+	fmt.Fprintln(a.Stdout, args...)
+}
+
+// prompt asks a question and returns the trimmed line answered
+func (a *App) prompt(question string) string {
+	fmt.Fprint(a.Stdout, question)
+	line, _ := a.in.ReadString('\n')
+	return strings.TrimSpace(line)
+}
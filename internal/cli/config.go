@@ -0,0 +1,357 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/mastery"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/report"
+	"github.com/cmyers78/claude/internal/sandbox"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// Session store names for Config.SessionStore
+const (
+	FileStore = "file"
+	LogStore  = "log"
+)
+
+// Config is the machine-wide config file. Settings are applied in order:
+// built-in defaults, this file, the learner's profile, then flags.
+type Config struct {
+	StorageDir   string               `json:"storage_dir,omitempty"`   // Profiles and sessions; default ~/.claude-trainer
+	SessionStore string               `json:"session_store,omitempty"` // "file" (default), or "log" for large histories
+	ContentPath  []string             `json:"content_path,omitempty"`  // Content pack directories, before $TRAINER_CONTENT_PATH
+	Trainer      models.TrainerConfig `json:"trainer"`                 // Settings for learners who haven't chosen their own
+	Retention    Retention            `json:"retention"`               // How long completed sessions are kept
+	XAPI         XAPI                 `json:"xapi"`                    // Where to record learning activity as xAPI statements
+	Report       Report               `json:"report"`                  // How progress reports are rendered
+	Sandbox      Sandbox              `json:"sandbox"`                 // How answers are run
+}
+
+// Sandbox configures how learners' programs are run
+type Sandbox struct {
+	// RequireIsolation refuses to run answers on a host that can't cut them
+	// off from the network, instead of running them with a warning
+	RequireIsolation bool `json:"require_isolation,omitempty"`
+}
+
+// Report customizes progress reports
+type Report struct {
+	Templates map[string]string `json:"templates,omitempty"` // Template file per format: "html", "md" or "pdf-ready-html"
+}
+
+// XAPI chooses where training sessions record xAPI statements: a local
+// spool file, a Learning Record Store, or both. Statements for an LRS that
+// can't be reached are buffered in the storage directory until it can.
+type XAPI struct {
+	Spool        string  `json:"spool,omitempty"`         // File to append statements to, one JSON statement per line
+	Endpoint     string  `json:"endpoint,omitempty"`      // LRS base URL; statements are posted to <endpoint>/statements
+	Username     string  `json:"username,omitempty"`      // LRS basic auth user; the password is read from $TRAINER_XAPI_PASSWORD
+	ActivityBase string  `json:"activity_base,omitempty"` // IRI prefix of activity IDs
+	PassingScore float64 `json:"passing_score,omitempty"` // Exercise score out of 100 that passes; default 70
+}
+
+// enabled reports whether statements are recorded anywhere
+func (x XAPI) enabled() bool {
+	return x.Spool != "" || x.Endpoint != ""
+}
+
+// Retention is the config file's retention policy for completed sessions,
+// measured from when they started. Unset ages keep sessions forever.
+type Retention struct {
+	ArchiveAfter Age `json:"archive_after,omitempty"` // Move completed sessions to the user's archive
+	DeleteAfter  Age `json:"delete_after,omitempty"`  // Delete completed sessions, archived or not
+}
+
+// policy returns the storage policy the settings describe
+func (r Retention) policy() storage.RetentionPolicy {
+	return storage.RetentionPolicy{ArchiveAfter: time.Duration(r.ArchiveAfter), DeleteAfter: time.Duration(r.DeleteAfter)}
+}
+
+// Age is a length of time that may be written in days or weeks as well as
+// Go's duration units, such as "30d", "2w" or "36h"
+type Age time.Duration
+
+// ParseAge parses an Age, which must not be negative
+func ParseAge(text string) (Age, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, ok := strings.CutSuffix(text, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (want a whole number of days or weeks, such as 30d)", text)
+			}
+			return Age(time.Duration(n) * unit), nil
+		}
+	}
+
+	d, err := time.ParseDuration(text)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (want a length such as 30d, 2w or 36h)", text)
+	}
+	return Age(d), nil
+}
+
+// String formats whole days as days, and anything else as a Go duration
+func (a Age) String() string {
+	d := time.Duration(a)
+	if day := 24 * time.Hour; d > 0 && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
+
+// Set implements flag.Value
+func (a *Age) Set(text string) error {
+	age, err := ParseAge(text)
+	if err != nil {
+		return err
+	}
+	*a = age
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (a Age) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (a *Age) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("age must be a string like \"30d\": %w", err)
+	}
+	return a.Set(text)
+}
+
+// DefaultTrainerConfig is the training configuration built into the trainer
+func DefaultTrainerConfig() models.TrainerConfig {
+	return models.TrainerConfig{
+		MaxAttempts:        3,
+		TimeLimit:          time.Hour,
+		ExerciseTimeFactor: 2,
+		TimeWarnings:       []time.Duration{10 * time.Minute, time.Minute},
+		ShowHints:          true,
+		AdaptivePacing:     true,
+		CognitiveLoad:      models.Beginner,
+
+		MasteryThreshold: mastery.DefaultThreshold,
+	}
+}
+
+// DefaultConfig returns the configuration used without a config file
+func DefaultConfig() *Config {
+	return &Config{SessionStore: FileStore, Trainer: DefaultTrainerConfig()}
+}
+
+// LoadConfig reads a config file over the defaults. A missing file yields
+// the defaults; settings the file leaves out keep their default values.
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, &exitError{code: ExitUsage, err: fmt.Errorf("%s: %w", path, err)}
+	}
+	if err := config.validate(); err != nil {
+		return nil, &exitError{code: ExitUsage, err: fmt.Errorf("%s: %w", path, err)}
+	}
+	return config, nil
+}
+
+// validate checks settings that decoding can't
+func (c *Config) validate() error {
+	switch c.SessionStore {
+	case FileStore, LogStore:
+	default:
+		return fmt.Errorf("session_store: unknown store %q (want %q or %q)", c.SessionStore, FileStore, LogStore)
+	}
+	if c.Trainer.MaxAttempts < 1 {
+		return fmt.Errorf("trainer.max_attempts: must be at least 1")
+	}
+	if c.Trainer.ExerciseTimeFactor <= 0 {
+		return fmt.Errorf("trainer.exercise_time_factor: must be greater than 0")
+	}
+	if c.Trainer.MasteryThreshold < 0 || c.Trainer.MasteryThreshold >= 1 {
+		return fmt.Errorf("trainer.mastery_threshold: must be at least 0 and below 1")
+	}
+	if c.XAPI.Endpoint != "" {
+		endpoint, err := url.Parse(c.XAPI.Endpoint)
+		if err != nil || endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+			return fmt.Errorf("xapi.endpoint: want an http or https URL, got %q", c.XAPI.Endpoint)
+		}
+	}
+	for format := range c.Report.Templates {
+		if _, err := report.ParseFormat(format); err != nil {
+			return fmt.Errorf("report.templates: %w", err)
+		}
+	}
+	if c.XAPI.PassingScore < 0 || c.XAPI.PassingScore > 100 {
+		return fmt.Errorf("xapi.passing_score: must be between 0 and 100")
+	}
+	return nil
+}
+
+// Save writes the config file, creating its directory
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// configPath returns the config file to use: --config, $TRAINER_CONFIG or
+// config.json in the default storage directory
+func (a *App) configPath(opts *options) string {
+	if opts.configPath != "" {
+		return opts.configPath
+	}
+	if path := a.Getenv("TRAINER_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(a.HomeDir, ".claude-trainer", "config.json")
+}
+
+// loadConfig reads the config file and applies --storage-dir
+func (a *App) loadConfig(opts *options) (*Config, error) {
+	config, err := LoadConfig(a.configPath(opts))
+	if err != nil {
+		return nil, err
+	}
+	if opts.storageDir != "" {
+		config.StorageDir = opts.storageDir
+	}
+	if config.StorageDir == "" {
+		config.StorageDir = filepath.Join(a.HomeDir, ".claude-trainer")
+	}
+	if rest, ok := strings.CutPrefix(config.StorageDir, "~/"); ok {
+		config.StorageDir = filepath.Join(a.HomeDir, rest)
+	}
+	if rest, ok := strings.CutPrefix(config.XAPI.Spool, "~/"); ok {
+		config.XAPI.Spool = filepath.Join(a.HomeDir, rest)
+	}
+	for format, path := range config.Report.Templates {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			config.Report.Templates[format] = filepath.Join(a.HomeDir, rest)
+		}
+	}
+
+	// Challenge validators share the process's runner
+	runner := sandbox.Default()
+	runner.RequireIsolation = config.Sandbox.RequireIsolation
+	runner.Warnings = a.Stderr
+	return config, nil
+}
+
+// trainingSettings applies the training flags given to a configuration
+func trainingSettings(config models.TrainerConfig, opts *options) (models.TrainerConfig, error) {
+	if opts.set["max-attempts"] {
+		if opts.maxAttempts < 1 {
+			return config, usagef("--max-attempts must be at least 1")
+		}
+		config.MaxAttempts = opts.maxAttempts
+	}
+	if opts.set["no-hints"] {
+		config.ShowHints = !opts.noHints
+	}
+	if opts.set["time-limit"] {
+		config.TimeLimit = opts.timeLimit
+	}
+	if opts.set["time-factor"] {
+		if opts.timeFactor <= 0 {
+			return config, usagef("--time-factor must be greater than 0")
+		}
+		config.ExerciseTimeFactor = opts.timeFactor
+	}
+	if opts.set["mastery-threshold"] {
+		if opts.masteryThreshold < 0 || opts.masteryThreshold >= 1 {
+			return config, usagef("--mastery-threshold must be at least 0 and below 1")
+		}
+		config.MasteryThreshold = opts.masteryThreshold
+	}
+	if opts.set["level"] {
+		level, err := models.ParseCognitiveLevel(opts.level)
+		if err != nil {
+			return config, &exitError{code: ExitUsage, err: err}
+		}
+		config.CognitiveLoad = level
+	}
+	return config, nil
+}
+
+// config shows the effective configuration, or with "init" writes the
+// defaults to the config file, or with "path" prints where it is read from
+func (a *App) config(opts *options, args []string) error {
+	path := a.configPath(opts)
+	if len(args) > 1 {
+		return usagef("config takes at most one argument")
+	}
+
+	if len(args) == 1 {
+		switch args[0] {
+		case "path":
+			a.println(path)
+			return nil
+		case "init":
+			if _, err := os.Stat(path); err == nil {
+				return fmt.Errorf("%s already exists", path)
+			}
+			if err := DefaultConfig().Save(path); err != nil {
+				return err
+			}
+			a.printf("Wrote the default configuration to %s\n", path)
+			return nil
+		default:
+			return usagef("unknown config command %q (want init or path)", args[0])
+		}
+	}
+
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	user, err := a.openLearner(opts, config)
+	if err != nil {
+		return err
+	}
+	trainer, err := user.trainerConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	// Show what training as this user would use, as a config file would
+	// spell it
+	effective := *config
+	effective.Trainer = trainer
+	data, err := json.MarshalIndent(effective, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	a.printf("# Config file: %s\n# User: %s\n%s\n", path, user.profile.ID, data)
+	return nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"strings"
+)
+
+// exerciseSummary describes an exercise for 'exercises --json'
+type exerciseSummary struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Level         string   `json:"level"`
+	Type          string   `json:"type"`
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	Challenges    int      `json:"challenges"`
+	EstimatedTime int      `json:"estimated_minutes"`
+}
+
+// exercises lists the curriculum in learning order
+func (a *App) exercises(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("exercises takes no arguments")
+	}
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	summaries := []exerciseSummary{}
+	for _, exercise := range registry.GetAll() {
+		summaries = append(summaries, exerciseSummary{
+			ID:            exercise.ID,
+			Title:         exercise.Title,
+			Level:         exercise.CognitiveLevel.String(),
+			Type:          exercise.ExerciseType.String(),
+			Prerequisites: exercise.Prerequisites,
+			Challenges:    len(exercise.Challenges),
+			EstimatedTime: exercise.EstimatedTime,
+		})
+	}
+	if opts.json {
+		return a.printJSON(summaries)
+	}
+
+	a.printf("%-18s %-28s %-13s %10s  %s\n", "ID", "TITLE", "LEVEL", "MINUTES", "REQUIRES")
+	for _, summary := range summaries {
+		a.printf("%-18s %-28s %-13s %10d  %s\n", summary.ID, summary.Title, summary.Level,
+			summary.EstimatedTime, strings.Join(summary.Prerequisites, ", "))
+	}
+	return nil
+}
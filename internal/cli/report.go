@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cmyers78/claude/internal/report"
+)
+
+// report renders a progress report with a completion certificate for one
+// session, live or archived, or with --verify checks a certificate's hash
+// against the session
+func (a *App) report(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("report takes no arguments; name the session with --session")
+	}
+	if opts.session == "" {
+		return usagef("report needs --session <id>; run 'trainer list' for yours")
+	}
+	format, err := report.ParseFormat(opts.format)
+	if err != nil {
+		return &exitError{code: ExitUsage, err: err}
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	sessions, err := a.exportedSessions(user, sessionStorage, []string{opts.session})
+	if err != nil {
+		return err
+	}
+	session := sessions[0]
+	key, err := report.LoadKey(filepath.Join(config.StorageDir, "report.key"))
+	if err != nil {
+		return err
+	}
+
+	if opts.verify != "" {
+		ok, err := report.Verify(session, opts.verify, key)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("hash doesn't match session '%s'; the report was made from different data or on another install", session.SessionID)
+		}
+		a.printf("✅ Hash matches session '%s' (%s).\n", session.SessionID, session.Status)
+		return nil
+	}
+
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+	templatePath := opts.template
+	if templatePath == "" {
+		templatePath = config.Report.Templates[string(format)]
+	}
+	tmpl, err := report.Load(format, templatePath)
+	if err != nil {
+		return err
+	}
+	doc, err := report.Build(session, registry.GetAll(), user.profile.DisplayName(), a.now(), key)
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Render(&out, doc); err != nil {
+		return err
+	}
+	if opts.output == "" {
+		_, err := a.Stdout.Write(out.Bytes())
+		return err
+	}
+	if err := os.WriteFile(opts.output, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	a.printf("Wrote the %s report for session '%s' to %s\n", format, session.SessionID, opts.output)
+	return nil
+}
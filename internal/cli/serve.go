@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cmyers78/claude/internal/server"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+	"github.com/cmyers78/claude/internal/web"
+	"github.com/cmyers78/claude/internal/xapi"
+)
+
+// shutdownTimeout is how long serve waits for sessions to save and
+// requests to finish once asked to stop
+const shutdownTimeout = 30 * time.Second
+
+// serve runs the trainer as a JSON API over HTTP, with the browser
+// front-end at the root, until interrupted, then pauses and saves every
+// session in training
+func (a *App) serve(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("serve takes no arguments")
+	}
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+	profiles, err := a.openProfiles(config)
+	if err != nil {
+		return err
+	}
+	learners := &servedLearners{app: a, opts: opts, config: config, profiles: profiles, served: make(map[string]*servedLearner)}
+	defer learners.close()
+
+	listener, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", opts.addr, err)
+	}
+	// Answers run as code on this machine, and the server has no login of
+	// its own, so it stays on loopback unless told otherwise
+	loopback := isLoopback(listener.Addr())
+	if !loopback && !opts.allowRemote {
+		listener.Close()
+		return usagef("%s can be reached from other machines, which could then run code on this one; listen on 127.0.0.1 or pass --allow-remote", listener.Addr())
+	}
+	srv := server.New(registry, learners)
+	mux := http.NewServeMux()
+	mux.Handle("/api/", srv.Handler())
+	mux.Handle("/", web.Handler())
+	var handler http.Handler = mux
+	if loopback {
+		handler = localHostOnly(mux)
+	}
+	httpServer := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	a.printf("Serving the trainer on http://%s/ and its API under /api/ (Ctrl-C to stop)\n", listener.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() { served <- httpServer.Serve(listener) }()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Stop the sessions first, so requests waiting on them can finish
+	shutdown, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return fmt.Errorf("failed to save every session: %w", err)
+	}
+	if err := httpServer.Shutdown(shutdown); err != nil {
+		return err
+	}
+	a.println("Paused and saved every session in training.")
+	return nil
+}
+
+// isLoopback reports whether addr only accepts connections from this
+// machine
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
+
+// localHostOnly refuses requests naming a host other than this machine, so
+// a site that points its own name at 127.0.0.1 can't reach the server
+func localHostOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if ip := net.ParseIP(strings.Trim(host, "[]")); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// servedLearners opens learners' profiles for the server, keeping each
+// one's profile and session storage open while it serves
+type servedLearners struct {
+	app      *App
+	opts     *options
+	config   *Config
+	profiles *storage.FileProfileStorage
+
+	mu     sync.Mutex
+	served map[string]*servedLearner // By user ID
+}
+
+// servedLearner is a learner the server has opened
+type servedLearner struct {
+	user          learner
+	sessions      storage.SessionStorage
+	closeSessions func()
+
+	mu       sync.Mutex
+	unlocked [sha256.Size]byte // Digest of the passphrase that unlocked a protected profile
+}
+
+// Learner implements server.Learners. Passphrases are slow to check by
+// design, so they're checked without holding up other learners.
+func (l *servedLearners) Learner(userID, passphrase string) (*server.Learner, error) {
+	served, err := l.open(userID, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	user := served.user
+	l.mu.Lock()
+	config, err := user.profile.TrainerConfig(l.config.Trainer)
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &server.Learner{
+		ID:       user.profile.ID,
+		Config:   config,
+		Sessions: served.sessions,
+		Attach:   func(t *trainer.CLTTrainer) func() { return l.attach(user, t) },
+	}, nil
+}
+
+// open returns a learner the server has opened, opening them the first
+// time, once passphrase unlocks their profile
+func (l *servedLearners) open(userID, passphrase string) (*servedLearner, error) {
+	digest := sha256.Sum256([]byte(passphrase))
+	l.mu.Lock()
+	served, ok := l.served[userID]
+	l.mu.Unlock()
+	if ok {
+		if err := served.unlock(passphrase, digest); err != nil {
+			return nil, err
+		}
+		return served, nil
+	}
+
+	p, err := loadProfile(l.profiles, l.config, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Unlock(passphrase); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if served, ok := l.served[userID]; ok {
+		return served, nil // Opened by another request meanwhile
+	}
+	user := learner{profile: p, profiles: l.profiles, config: l.config}
+	sessions, closeSessions, err := l.app.openSessions(user, l.opts)
+	if err != nil {
+		return nil, err
+	}
+	served = &servedLearner{user: user, sessions: sessions, closeSessions: closeSessions, unlocked: digest}
+	l.served[userID] = served
+	return served, nil
+}
+
+// unlock checks a passphrase against the learner's profile, unless it's
+// the one that last unlocked it
+func (s *servedLearner) unlock(passphrase string, digest [sha256.Size]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if subtle.ConstantTimeCompare(digest[:], s.unlocked[:]) == 1 {
+		return nil
+	}
+	if err := s.user.profile.Unlock(passphrase); err != nil {
+		return err
+	}
+	s.unlocked = digest
+	return nil
+}
+
+// attach records a session's xAPI statements if the config file asks, and
+// returns a function recording the cognitive level it left the learner at
+func (l *servedLearners) attach(user learner, t *trainer.CLTTrainer) func() {
+	var recorder *xapi.Recorder
+	if user.config.XAPI.enabled() {
+		recorder = xapi.NewRecorder(xapiBuilder(user), l.app.xapiSink(user.config))
+		t.AddListener(recorder)
+	}
+	return func() {
+		if recorder != nil {
+			if err := recorder.Close(); err != nil {
+				fmt.Fprintf(l.app.Stderr, "Warning: failed to record xAPI statements for %s: %v\n", user.profile.ID, err)
+			}
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if err := user.saveLevel(t.Level()); err != nil {
+			fmt.Fprintf(l.app.Stderr, "Warning: failed to save %s's level: %v\n", user.profile.ID, err)
+		}
+	}
+}
+
+// close closes every learner's session storage
+func (l *servedLearners) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, served := range l.served {
+		served.closeSessions()
+	}
+}
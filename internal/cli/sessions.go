@@ -0,0 +1,629 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+	"github.com/cmyers78/claude/internal/xapi"
+)
+
+// setup loads the config file and opens the learner's profile
+func (a *App) setup(opts *options) (*Config, learner, error) {
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return nil, learner{}, err
+	}
+	user, err := a.openLearner(opts, config)
+	if err != nil {
+		return nil, learner{}, err
+	}
+	return config, user, nil
+}
+
+// loadRegistry returns the built-in curriculum plus the content packs in
+// the config file's content_path and $TRAINER_CONTENT_PATH
+func (a *App) loadRegistry(config *Config) (*exercises.Registry, error) {
+	dirs := append([]string(nil), config.ContentPath...)
+	for _, dir := range filepath.SplitList(a.Getenv("TRAINER_CONTENT_PATH")) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	registry, err := exercises.LoadRegistry(dirs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exercises: %w", err)
+	}
+	return registry, nil
+}
+
+// train runs a trainer on the App's streams, recording xAPI statements if
+// the config file asks, and records the cognitive level it left the
+// learner at
+func (a *App) train(user learner, t *trainer.CLTTrainer) error {
+	terminal := trainer.NewTerminal(a.in, a.Stdout)
+	t.SetIO(terminal, terminal)
+
+	var recorder *xapi.Recorder
+	if user.config.XAPI.enabled() {
+		recorder = xapi.NewRecorder(xapiBuilder(user), a.xapiSink(user.config))
+		t.AddListener(recorder)
+	}
+	t.Start()
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			fmt.Fprintf(a.Stderr, "Warning: failed to record xAPI statements: %v\n", err)
+		}
+		a.reportPending(a.xapiClient(user.config))
+	}
+	return user.saveLevel(t.Level())
+}
+
+// start begins a new session over the curriculum, or the part of it from
+// --from on
+func (a *App) start(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("start takes no arguments")
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	list := registry.GetAll()
+	if opts.from != "" {
+		from := -1
+		for i, exercise := range list {
+			if exercise.ID == opts.from {
+				from = i
+			}
+		}
+		if from < 0 {
+			return notFoundf("no exercise %q; run 'trainer exercises' for the list", opts.from)
+		}
+		list = list[from:]
+	}
+	return a.startSession(user, opts, list)
+}
+
+// practice begins a session with a single exercise
+func (a *App) practice(opts *options, args []string) error {
+	if len(args) != 1 {
+		return usagef("usage: trainer practice <exercise-id>")
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	exercise, ok := registry.GetByID(args[0])
+	if !ok {
+		return notFoundf("no exercise %q; run 'trainer exercises' for the list", args[0])
+	}
+	return a.startSession(user, opts, []models.Exercise{exercise})
+}
+
+// startSession trains the learner on a list of exercises in a new session
+func (a *App) startSession(user learner, opts *options, list []models.Exercise) error {
+	config, err := user.trainerConfig(opts)
+	if err != nil {
+		return err
+	}
+	sessions, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	if user.profile.ID != profile.DefaultID {
+		a.printf("Training as %s\n", user.profile.DisplayName())
+	}
+	return a.train(user, trainer.NewCLTTrainer(list, config, user.profile.ID, sessions))
+}
+
+// resume continues a paused session, or one a crash interrupted: the one
+// named, or the learner's choice if there are several
+func (a *App) resume(opts *options, args []string) error {
+	if len(args) > 1 {
+		return usagef("usage: trainer resume [<session-id>]")
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	if len(args) == 1 {
+		session, err := a.loadSession(user, sessionStorage, args[0])
+		if err != nil {
+			return err
+		}
+		if session.Status != models.SessionPaused && session.Status != models.SessionActive {
+			return fmt.Errorf("session '%s' is %s and can't be resumed", session.SessionID, session.Status)
+		}
+		// Naming an interrupted session is taken as asking to recover it
+		return a.resumeSession(user, config, session, sessionStorage, false)
+	}
+
+	// Paused sessions, plus sessions a crash or closed terminal interrupted
+	sessions, err := trainer.ResumableSessions(user.profile.ID, sessionStorage)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		a.println("No paused training sessions found.")
+		return nil
+	}
+
+	session := sessions[0]
+	if len(sessions) > 1 {
+		// Multiple sessions - let user choose
+		a.println("Multiple paused sessions found:")
+		for i, session := range sessions {
+			if session.Status == models.SessionActive {
+				a.printf("%d. Session from %s (Exercise %d, interrupted)\n",
+					i+1, session.LastActivity.Format("2006-01-02 15:04"), session.CurrentIndex+1)
+				continue
+			}
+			a.printf("%d. Session from %s (Exercise %d)\n",
+				i+1, session.PausedAt.Format("2006-01-02 15:04"), session.CurrentIndex+1)
+		}
+
+		choice, err := strconv.Atoi(a.prompt(fmt.Sprintf("Choose session to resume (1-%d): ", len(sessions))))
+		if err != nil || choice < 1 || choice > len(sessions) {
+			return usagef("invalid choice")
+		}
+		session = sessions[choice-1]
+	}
+
+	return a.resumeSession(user, config, session, sessionStorage, true)
+}
+
+// resumeSession continues a specific training session. If it was
+// interrupted it is recovered from its last checkpoint, after asking first
+// if confirm is set.
+func (a *App) resumeSession(user learner, config *Config, session *models.TrainingSession, sessionStorage storage.SessionStorage, confirm bool) error {
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	resume := trainer.ResumeSession
+	if session.Status == models.SessionActive {
+		if confirm {
+			answer := a.prompt(fmt.Sprintf("Session '%s' was interrupted at %s. Recover it from its last checkpoint? (Y/n): ",
+				session.SessionID, session.LastActivity.Format("2006-01-02 15:04")))
+			if answer == "n" || answer == "N" {
+				a.println("Cancelled.")
+				return nil
+			}
+		}
+		resume = trainer.RecoverSession
+	}
+
+	cltTrainer, err := resume(session.SessionID, registry.GetAll(), sessionStorage)
+	if err != nil {
+		return fmt.Errorf("failed to resume session: %w", err)
+	}
+	return a.train(user, cltTrainer)
+}
+
+// loadSession loads one of the learner's sessions by ID
+func (a *App) loadSession(user learner, sessionStorage storage.SessionStorage, sessionID string) (*models.TrainingSession, error) {
+	session, err := sessionStorage.LoadSession(sessionID)
+	if errors.Is(err, storage.ErrSessionNotFound) || err == nil && session.UserID != user.profile.ID {
+		return nil, notFoundf("no session '%s'; run 'trainer list' for yours", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	return session, nil
+}
+
+// review runs a spaced-repetition review of challenges from past sessions
+func (a *App) review(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("review takes no arguments")
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+	reviewStorage := user.reviews()
+
+	deck, err := reviewStorage.LoadDeck(user.profile.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load review schedule: %w", err)
+	}
+
+	// Schedule anything practiced since the last review
+	sessions, err := trainer.ListUserSessions(user.profile.ID, sessionStorage)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if trainer.ImportSessions(deck, sessions) > 0 {
+		if err := reviewStorage.SaveDeck(deck); err != nil {
+			return fmt.Errorf("failed to save review schedule: %w", err)
+		}
+	}
+
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+	preferred, err := user.trainerConfig(opts)
+	if err != nil {
+		return err
+	}
+	reviewConfig := models.TrainerConfig{MaxAttempts: preferred.MaxAttempts, ShowHints: preferred.ShowHints}
+	reviewer := trainer.NewReviewer(registry.GetAll(), reviewConfig, deck, reviewStorage)
+	terminal := trainer.NewTerminal(a.in, a.Stdout)
+	reviewer.SetIO(terminal, terminal)
+	if _, err := reviewer.Run(); err != nil {
+		return fmt.Errorf("review failed: %w", err)
+	}
+	return nil
+}
+
+// list shows the learner's sessions, or their archived ones, filtered by
+// status and start date and sorted
+func (a *App) list(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("list takes no arguments")
+	}
+	query, err := a.sessionQuery(opts)
+	if err != nil {
+		return err
+	}
+	_, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	query.UserID = user.profile.ID
+
+	var sessionStorage storage.SessionStorage = user.archive()
+	if !opts.archived {
+		opened, closeSessions, err := a.openSessions(user, opts)
+		if err != nil {
+			return err
+		}
+		defer closeSessions()
+		sessionStorage = opened
+	}
+
+	sessions, err := storage.QuerySessions(sessionStorage, query)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if err := sortSessions(sessions, opts.sortBy, opts.reverse); err != nil {
+		return err
+	}
+
+	if opts.json {
+		if sessions == nil {
+			sessions = []*models.TrainingSession{}
+		}
+		return a.printJSON(sessions)
+	}
+
+	if len(sessions) == 0 {
+		a.println("No training sessions found.")
+		return nil
+	}
+
+	a.println("Training Sessions:")
+	a.println("==================")
+
+	for _, session := range sessions {
+		a.printf("Session ID: %s\n", session.SessionID)
+		a.printf("Status: %s\n", session.Status)
+		a.printf("Started: %s\n", session.StartTime.Format("2006-01-02 15:04:05"))
+
+		if session.PausedAt != nil {
+			a.printf("Paused: %s\n", session.PausedAt.Format("2006-01-02 15:04:05"))
+		}
+
+		a.printf("Progress: Exercise %d/%d\n", session.CurrentIndex+1, len(session.Progress))
+		a.println("---")
+	}
+	return nil
+}
+
+// sessionQuery builds the query the --status, --since and --until flags
+// describe
+func (a *App) sessionQuery(opts *options) (storage.SessionQuery, error) {
+	var query storage.SessionQuery
+	var err error
+	if query.Status, err = parseStatus(opts.status); err != nil {
+		return query, err
+	}
+	if query.From, err = a.parseTime("--since", opts.since); err != nil {
+		return query, err
+	}
+	if query.To, err = a.parseTime("--until", opts.until); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// parseStatus checks a --status value; empty matches every status
+func parseStatus(text string) (models.SessionStatus, error) {
+	switch status := models.SessionStatus(text); status {
+	case "", models.SessionActive, models.SessionPaused, models.SessionCompleted, models.SessionAbandoned:
+		return status, nil
+	}
+	return "", usagef("unknown status %q (want active, paused, completed or abandoned)", text)
+}
+
+// parseTime reads a date flag: a date, a date and time in RFC 3339, or an
+// age such as 7d meaning that long ago. Empty yields the zero time.
+func (a *App) parseTime(flagName, text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", text, time.Local); err == nil {
+		return date, nil
+	}
+	if instant, err := time.Parse(time.RFC3339, text); err == nil {
+		return instant, nil
+	}
+	if age, err := ParseAge(text); err == nil {
+		return a.now().Add(-time.Duration(age)), nil
+	}
+	return time.Time{}, usagef("%s: invalid date %q (want 2006-01-02, an RFC 3339 time or an age such as 7d)", flagName, text)
+}
+
+// sortSessions orders sessions by a --sort key, keeping start order among
+// equal ones
+func sortSessions(sessions []*models.TrainingSession, by string, reverse bool) error {
+	var less func(a, b *models.TrainingSession) bool
+	switch by {
+	case "", "started":
+		less = func(a, b *models.TrainingSession) bool { return a.StartTime.Before(b.StartTime) }
+	case "activity":
+		less = func(a, b *models.TrainingSession) bool { return a.LastActivity.Before(b.LastActivity) }
+	case "progress":
+		less = func(a, b *models.TrainingSession) bool { return a.CurrentIndex < b.CurrentIndex }
+	case "status":
+		less = func(a, b *models.TrainingSession) bool { return a.Status < b.Status }
+	default:
+		return usagef("unknown sort %q (want started, activity, progress or status)", by)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if reverse {
+			return less(sessions[j], sessions[i])
+		}
+		return less(sessions[i], sessions[j])
+	})
+	return nil
+}
+
+// delete removes the sessions named, the sessions --status and
+// --older-than select, or with neither a session the learner chooses
+func (a *App) delete(opts *options, args []string) error {
+	filtered := opts.set["status"] || opts.set["older-than"]
+	switch {
+	case len(args) > 0 && filtered:
+		return usagef("give either session IDs or --status and --older-than, not both")
+	case len(args) == 0 && !filtered && opts.dryRun:
+		return usagef("--dry-run needs session IDs, --status or --older-than")
+	}
+	status, err := parseStatus(opts.status)
+	if err != nil {
+		return err
+	}
+
+	_, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	if len(args) > 0 {
+		return a.deleteSessions(user, sessionStorage, args, opts.dryRun)
+	}
+	if filtered {
+		return a.deleteMatching(user, sessionStorage, status, time.Duration(opts.olderThan), opts.dryRun)
+	}
+	return a.deleteChosen(user, sessionStorage)
+}
+
+// deleteSessions deletes sessions by ID, carrying on past any that can't be
+// deleted
+func (a *App) deleteSessions(user learner, sessionStorage storage.SessionStorage, ids []string, dryRun bool) error {
+	var failures []error
+	for _, sessionID := range ids {
+		session, err := a.loadSession(user, sessionStorage, sessionID)
+		if err == nil {
+			err = a.deleteSession(sessionStorage, session, dryRun)
+		}
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// deleteMatching deletes the sessions with a status, started longer ago
+// than olderThan; zero values match every session
+func (a *App) deleteMatching(user learner, sessionStorage storage.SessionStorage, status models.SessionStatus, olderThan time.Duration, dryRun bool) error {
+	query := storage.SessionQuery{UserID: user.profile.ID, Status: status}
+	if olderThan > 0 {
+		query.To = a.now().Add(-olderThan)
+	}
+	sessions, err := storage.QuerySessions(sessionStorage, query)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	deleted := 0
+	var failures []error
+	for _, session := range sessions {
+		if err := a.deleteSession(sessionStorage, session, dryRun); err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		deleted++
+	}
+
+	switch {
+	case deleted == 0 && len(failures) == 0:
+		a.println("No sessions match.")
+	case dryRun:
+		a.printf("Would delete %d session(s).\n", deleted)
+	default:
+		a.printf("Deleted %d session(s).\n", deleted)
+	}
+	return errors.Join(failures...)
+}
+
+// deleteSession deletes one session, or with dryRun only says it would
+func (a *App) deleteSession(sessionStorage storage.SessionStorage, session *models.TrainingSession, dryRun bool) error {
+	description := fmt.Sprintf("%s (%s, started %s)", session.SessionID, session.Status, session.StartTime.Format("2006-01-02 15:04"))
+	if dryRun {
+		a.printf("Would delete %s\n", description)
+		return nil
+	}
+	if err := storage.RemoveSession(sessionStorage, session.SessionID); err != nil {
+		return err
+	}
+	a.printf("Deleted %s\n", description)
+	return nil
+}
+
+// deleteChosen lets the learner choose a session to delete
+func (a *App) deleteChosen(user learner, sessionStorage storage.SessionStorage) error {
+	sessions, err := trainer.ListUserSessions(user.profile.ID, sessionStorage)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	if len(sessions) == 0 {
+		a.println("No training sessions found.")
+		return nil
+	}
+
+	a.println("Select session to delete:")
+	a.println("========================")
+
+	for i, session := range sessions {
+		status := string(session.Status)
+		if session.PausedAt != nil {
+			status += fmt.Sprintf(" (paused %s)", session.PausedAt.Format("2006-01-02 15:04"))
+		}
+		a.printf("%d. %s - %s - Exercise %d\n",
+			i+1, session.SessionID, status, session.CurrentIndex+1)
+	}
+
+	choice, err := strconv.Atoi(a.prompt(fmt.Sprintf("Choose session to delete (1-%d) or 0 to cancel: ", len(sessions))))
+	if err != nil || choice < 0 || choice > len(sessions) {
+		return usagef("invalid choice")
+	}
+
+	if choice == 0 {
+		a.println("Cancelled.")
+		return nil
+	}
+
+	sessionToDelete := sessions[choice-1]
+
+	// Confirm deletion
+	confirm := a.prompt(fmt.Sprintf("Are you sure you want to delete session '%s'? (y/N): ", sessionToDelete.SessionID))
+	if confirm != "y" && confirm != "Y" {
+		a.println("Cancelled.")
+		return nil
+	}
+
+	if err := storage.RemoveSession(sessionStorage, sessionToDelete.SessionID); err != nil {
+		return err
+	}
+
+	a.printf("Session '%s' deleted successfully.\n", sessionToDelete.SessionID)
+	return nil
+}
+
+// migrate upgrades every user's saved sessions to the current file format
+func (a *App) migrate(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("migrate takes no arguments")
+	}
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	profiles, err := a.openProfiles(config)
+	if err != nil {
+		return err
+	}
+	list, err := profiles.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	upgraded := 0
+	var failures []error
+	for _, p := range list {
+		// Archived sessions are upgraded too, so restoring one finds it current
+		for _, dir := range []string{profiles.SessionDir(p.ID), profiles.ArchiveDir(p.ID)} {
+			results, err := storage.NewFileSessionStorage(dir).Migrate()
+			for _, result := range results {
+				a.printf("Upgraded session '%s' from schema version %d (backup: %s)\n", result.SessionID, result.From, result.Backup)
+			}
+			upgraded += len(results)
+			if err != nil {
+				failures = append(failures, fmt.Errorf("user '%s': %w", p.ID, err))
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to migrate sessions: %w", errors.Join(failures...))
+	}
+
+	if upgraded == 0 {
+		a.printf("All sessions already use schema version %d.\n", storage.SchemaVersion)
+	}
+	return nil
+}
+
+// printJSON writes v as indented JSON
+func (a *App) printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+	a.printf("%s\n", data)
+	return nil
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/analytics"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// stats reports on every session the learner has saved, archived ones
+// included
+func (a *App) stats(opts *options, args []string) error {
+	if len(args) > 0 {
+		return usagef("stats takes no arguments")
+	}
+	period, err := analytics.ParsePeriod(opts.period)
+	if err != nil {
+		return &exitError{code: ExitUsage, err: err}
+	}
+	if opts.periods < 1 {
+		return usagef("--periods must be at least 1")
+	}
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	var sessions []*models.TrainingSession
+	for _, store := range []storage.SessionStorage{sessionStorage, user.archive()} {
+		found, err := storage.QuerySessions(store, storage.SessionQuery{UserID: user.profile.ID})
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		sessions = append(sessions, found...)
+	}
+
+	report := analytics.Compute(user.profile.ID, sessions, registry.GetAll(), analytics.Options{
+		Now:     a.now(),
+		Period:  period,
+		Periods: opts.periods,
+		Weakest: 3,
+	})
+	if opts.json {
+		return a.printJSON(report)
+	}
+	a.printReport(user, report)
+	return nil
+}
+
+// printReport shows a report as terminal tables with sparklines
+func (a *App) printReport(user learner, report *analytics.Report) {
+	a.printf("📈 Training history for %s\n", user.profile.DisplayName())
+	a.println("==============================")
+	if report.Sessions == 0 {
+		a.println("No training sessions yet. Start one with: trainer start")
+		return
+	}
+
+	a.printf("Sessions: %d", report.Sessions)
+	for _, status := range []models.SessionStatus{models.SessionCompleted, models.SessionPaused, models.SessionActive, models.SessionAbandoned} {
+		if n := report.ByStatus[status]; n > 0 {
+			a.printf(", %d %s", n, status)
+		}
+	}
+	a.println()
+	a.printf("Exercises completed: %d\n", report.ExercisesCompleted)
+	if report.ExercisesCompleted > 0 {
+		a.printf("Average score: %.1f/100\n", report.AverageScore)
+	}
+	a.printf("Time spent: %s\n", minutes(report.MinutesSpent))
+	a.printf("Hint dependency: %.0f%% of challenges\n", report.HintDependency*100)
+
+	counts := make([]float64, len(report.Timeline))
+	peak := 0.0
+	for i, period := range report.Timeline {
+		counts[i] = float64(period.Completed)
+		peak = math.Max(peak, counts[i])
+	}
+	if len(counts) > 0 {
+		first := report.Timeline[0].Start.Format("Jan 2")
+		a.printf("\n📅 Exercises completed per %s since %s:\n", report.Period, first)
+		a.printf("  %s  (this %s: %d, best: %.0f)\n", sparkline(counts, 0, peak), report.Period, report.Timeline[len(counts)-1].Completed, peak)
+	}
+
+	a.println("\n📊 Exercises:")
+	a.printf("  %-26s %4s %6s %6s  %-10s %6s  %s\n", "Exercise", "Done", "Avg", "Trend", "Scores", "Hints", "Time vs estimate")
+	for _, stats := range report.Exercises {
+		if stats.Started == 0 {
+			a.printf("  %-26s %4s\n", truncate(stats.Title, 26), "-")
+			continue
+		}
+		average, trend, pace := "-", "", "-"
+		if stats.Completed > 0 {
+			average = fmt.Sprintf("%.1f", stats.AverageScore)
+			pace = minutes(stats.AverageMinutes)
+			if stats.EstimatedMinutes > 0 {
+				pace += fmt.Sprintf(" / %dm (%.1fx)", stats.EstimatedMinutes, stats.TimeRatio)
+			}
+		}
+		if stats.Completed > 1 {
+			trend = fmt.Sprintf("%+.1f", stats.ScoreTrend)
+		}
+		a.printf("  %-26s %4d %6s %6s  %-10s %5.0f%%  %s\n",
+			truncate(stats.Title, 26), stats.Completed, average, trend, sparkline(lastN(stats.Scores, 10), 0, 100), stats.HintDependency*100, pace)
+	}
+
+	if len(report.Weakest) > 0 {
+		a.println("\n🎯 Weakest modules:")
+		for i, id := range report.Weakest {
+			for _, stats := range report.Exercises {
+				if stats.ExerciseID != id {
+					continue
+				}
+				details := []string{fmt.Sprintf("average %.1f", stats.AverageScore), fmt.Sprintf("hints on %.0f%% of challenges", stats.HintDependency*100)}
+				if stats.TimeRatio > 0 {
+					details = append(details, fmt.Sprintf("%.1fx the estimated time", stats.TimeRatio))
+				}
+				a.printf("  %d. %s: %s\n", i+1, stats.Title, strings.Join(details, ", "))
+				a.printf("     Practice it with: trainer practice %s\n", stats.ExerciseID)
+			}
+		}
+	}
+}
+
+// sparkLevels are the bars of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values between low and high as a row of bars
+func sparkline(values []float64, low, high float64) string {
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if high > low {
+			level = int(math.Round((value - low) / (high - low) * float64(len(sparkLevels)-1)))
+		}
+		level = max(0, min(level, len(sparkLevels)-1))
+		line.WriteRune(sparkLevels[level])
+	}
+	return line.String()
+}
+
+// lastN returns up to the last n values
+func lastN(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+// minutes formats a number of minutes as a duration such as 1h5m
+func minutes(m float64) string {
+	d := time.Duration(m * float64(time.Minute)).Round(time.Minute)
+	if d < time.Minute {
+		return time.Duration(m * float64(time.Minute)).Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.String(), "0s")
+}
+
+// truncate shortens text to at most n runes
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// learner is the profile a command runs as, with the storage that holds it
+type learner struct {
+	profile  *profile.Profile
+	profiles *storage.FileProfileStorage
+	config   *Config
+}
+
+// openSessions opens the learner's own session storage, in the store the
+// config file chooses, returning a function that closes it
+func (l learner) openSessions() (storage.SessionStorage, func(), error) {
+	dir := l.profiles.SessionDir(l.profile.ID)
+	if l.config.SessionStore == LogStore {
+		store, err := storage.OpenLogSessionStorage(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	}
+	return storage.NewFileSessionStorage(dir), func() {}, nil
+}
+
+// archive returns the storage holding the learner's archived sessions
+func (l learner) archive() *storage.FileSessionStorage {
+	return storage.NewFileSessionStorage(l.profiles.ArchiveDir(l.profile.ID))
+}
+
+// reviews returns the learner's own review storage
+func (l learner) reviews() *storage.FileReviewStorage {
+	return storage.NewFileReviewStorage(l.profiles.ReviewDir(l.profile.ID))
+}
+
+// trainerConfig returns the settings the learner trains with: the config
+// file's, with those chosen in their profile laid over them, adjusted by
+// flags
+func (l learner) trainerConfig(opts *options) (models.TrainerConfig, error) {
+	config, err := l.profile.TrainerConfig(l.config.Trainer)
+	if err != nil {
+		return config, err
+	}
+	return trainingSettings(config, opts)
+}
+
+// saveLevel records the cognitive level a session left the learner at
+func (l learner) saveLevel(level models.CognitiveLevel) error {
+	if l.profile.Level == level {
+		return nil
+	}
+	l.profile.Level = level
+	return l.profiles.SaveProfile(l.profile)
+}
+
+// openSessions opens the learner's session storage, first archiving and
+// deleting the completed sessions the config file's retention policy no
+// longer keeps. --dry-run leaves them alone.
+func (a *App) openSessions(user learner, opts *options) (storage.SessionStorage, func(), error) {
+	sessions, closeSessions, err := user.openSessions()
+	if err != nil || opts.dryRun {
+		return sessions, closeSessions, err
+	}
+
+	result, err := user.config.Retention.policy().Apply(user.profile.ID, sessions, user.archive(), a.now())
+	if len(result.Archived) > 0 {
+		fmt.Fprintf(a.Stderr, "Archived %d completed session(s) older than %s.\n", len(result.Archived), user.config.Retention.ArchiveAfter)
+	}
+	if len(result.Deleted) > 0 {
+		fmt.Fprintf(a.Stderr, "Deleted %d completed session(s) older than %s.\n", len(result.Deleted), user.config.Retention.DeleteAfter)
+	}
+	if err != nil {
+		fmt.Fprintf(a.Stderr, "Warning: failed to apply the retention policy: %v\n", err)
+	}
+	return sessions, closeSessions, nil
+}
+
+// openProfiles returns the profile storage, first moving any sessions and
+// reviews saved before profiles existed into the default profile
+func (a *App) openProfiles(config *Config) (*storage.FileProfileStorage, error) {
+	dir := config.StorageDir
+	profiles := storage.NewFileProfileStorage(filepath.Join(dir, "users"))
+	adopted, err := profiles.AdoptLegacyData(filepath.Join(dir, "sessions"), filepath.Join(dir, "reviews"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move saved sessions to the %s profile: %w", profile.DefaultID, err)
+	}
+	if adopted {
+		a.printf("Moved your saved sessions to the '%s' user profile.\n", profile.DefaultID)
+	}
+	return profiles, nil
+}
+
+// openLearner resolves who is running a command, from --user, $TRAINER_USER
+// or the user chosen with 'user switch', and unlocks their profile. The
+// default profile is created on first use; any other must be added first.
+func (a *App) openLearner(opts *options, config *Config) (learner, error) {
+	profiles, err := a.openProfiles(config)
+	if err != nil {
+		return learner{}, err
+	}
+
+	userID := opts.user
+	if userID == "" {
+		userID = a.Getenv("TRAINER_USER")
+	}
+	if userID == "" {
+		if userID, err = profiles.CurrentUser(); err != nil {
+			return learner{}, err
+		}
+	}
+
+	p, err := loadProfile(profiles, config, userID)
+	var notFound *storage.ProfileNotFoundError
+	if errors.As(err, &notFound) {
+		return learner{}, fmt.Errorf("%w; add one with: trainer user add %s", err, userID)
+	}
+	if err != nil {
+		return learner{}, err
+	}
+
+	if err := a.unlock(p); err != nil {
+		return learner{}, err
+	}
+	return learner{profile: p, profiles: profiles, config: config}, nil
+}
+
+// loadProfile loads a learner's profile, creating the default profile on
+// first use
+func loadProfile(profiles *storage.FileProfileStorage, config *Config, userID string) (*profile.Profile, error) {
+	p, err := profiles.LoadProfile(userID)
+	var notFound *storage.ProfileNotFoundError
+	if errors.As(err, &notFound) && userID == profile.DefaultID {
+		p, err = profile.New(userID, "")
+		if err == nil {
+			p.Level = config.Trainer.CognitiveLoad
+			err = profiles.SaveProfile(p)
+		}
+	}
+	return p, err
+}
+
+// unlock asks for a protected profile's passphrase
+func (a *App) unlock(p *profile.Profile) error {
+	if !p.Protected() {
+		return nil
+	}
+	passphrase := a.readPassphrase(fmt.Sprintf("Passphrase for %s: ", p.DisplayName()))
+	if err := p.Unlock(passphrase); err != nil {
+		return fmt.Errorf("can't open profile '%s': %w", p.ID, err)
+	}
+	return nil
+}
+
+// user manages profiles: user add|list|switch|set
+func (a *App) user(opts *options, args []string) error {
+	if len(args) == 0 {
+		return usagef("user needs a command: add, list, switch or set")
+	}
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	profiles, err := a.openProfiles(config)
+	if err != nil {
+		return err
+	}
+
+	command, args := args[0], args[1:]
+	if command == "list" {
+		if len(args) > 0 {
+			return usagef("user list takes no arguments")
+		}
+		return a.userList(profiles)
+	}
+	if len(args) != 1 {
+		return usagef("usage: trainer user %s <user>", command)
+	}
+
+	switch command {
+	case "add":
+		return a.userAdd(profiles, config, opts, args[0])
+	case "switch":
+		return a.userSwitch(profiles, args[0])
+	case "set":
+		return a.userSet(profiles, config, opts, args[0])
+	}
+	return usagef("unknown user command %q (want add, list, switch or set)", command)
+}
+
+// userAdd creates a profile, starting from the config file's settings
+func (a *App) userAdd(profiles *storage.FileProfileStorage, config *Config, opts *options, userID string) error {
+	if _, err := profiles.LoadProfile(userID); err == nil {
+		return fmt.Errorf("user '%s' already exists", userID)
+	}
+	p, err := profile.New(userID, "")
+	if err != nil {
+		return &exitError{code: ExitUsage, err: err}
+	}
+	p.Level = config.Trainer.CognitiveLoad
+	if err := a.applyUserSettings(p, config, opts); err != nil {
+		return err
+	}
+
+	if err := profiles.SaveProfile(p); err != nil {
+		return err
+	}
+	a.printf("Added user '%s'. Train as them with: trainer --user %s, or trainer user switch %s\n", p.ID, p.ID, p.ID)
+	return nil
+}
+
+// userList shows every profile, marking the current one
+func (a *App) userList(profiles *storage.FileProfileStorage) error {
+	list, err := profiles.ListProfiles()
+	if err != nil {
+		return err
+	}
+	current, err := profiles.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	if len(list) == 0 {
+		a.println("No users yet. Add one with: trainer user add <user>")
+		return nil
+	}
+	for _, p := range list {
+		marker := " "
+		if p.ID == current {
+			marker = "*"
+		}
+		details := []string{p.Level.String()}
+		if p.Protected() {
+			details = append(details, "passphrase")
+		}
+		a.printf("%s %-16s %-24s (%s)\n", marker, p.ID, p.Name, strings.Join(details, ", "))
+	}
+	return nil
+}
+
+// userSwitch makes a profile the one later commands run as
+func (a *App) userSwitch(profiles *storage.FileProfileStorage, userID string) error {
+	p, err := profiles.LoadProfile(userID)
+	if err != nil {
+		return err
+	}
+	if err := a.unlock(p); err != nil {
+		return err
+	}
+
+	if err := profiles.SetCurrentUser(p.ID); err != nil {
+		return err
+	}
+	a.printf("Now training as %s.\n", p.DisplayName())
+	return nil
+}
+
+// userSet changes an existing profile's settings
+func (a *App) userSet(profiles *storage.FileProfileStorage, config *Config, opts *options, userID string) error {
+	p, err := profiles.LoadProfile(userID)
+	if err != nil {
+		return err
+	}
+	if err := a.unlock(p); err != nil {
+		return err
+	}
+	if err := a.applyUserSettings(p, config, opts); err != nil {
+		return err
+	}
+
+	if err := profiles.SaveProfile(p); err != nil {
+		return err
+	}
+	a.printf("Updated user '%s'.\n", p.ID)
+	return nil
+}
+
+// profileSettings are the training flags a profile keeps, with the config
+// file fields they set. The level is kept as the profile's own.
+var profileSettings = []struct{ flag, field string }{
+	{"max-attempts", "max_attempts"},
+	{"no-hints", "show_hints"},
+	{"time-limit", "time_limit"},
+	{"time-factor", "exercise_time_factor"},
+	{"mastery-threshold", "mastery_threshold"},
+}
+
+// applyUserSettings copies the settings given on the command line into a
+// profile
+func (a *App) applyUserSettings(p *profile.Profile, config *Config, opts *options) error {
+	if opts.set["name"] {
+		p.Name = opts.name
+	}
+	if opts.set["level"] {
+		level, err := models.ParseCognitiveLevel(opts.level)
+		if err != nil {
+			return &exitError{code: ExitUsage, err: err}
+		}
+		p.Level = level
+	}
+
+	// Only the settings given are kept, so the rest follow the config file
+	preferred, err := p.TrainerConfig(config.Trainer)
+	if err != nil {
+		return err
+	}
+	if preferred, err = trainingSettings(preferred, opts); err != nil {
+		return err
+	}
+	var chosen []string
+	for _, setting := range profileSettings {
+		if opts.set[setting.flag] {
+			chosen = append(chosen, setting.field)
+		}
+	}
+	if err := p.Prefer(preferred, chosen...); err != nil {
+		return err
+	}
+
+	if opts.passphrase {
+		passphrase := a.readPassphrase("New passphrase (empty for none): ")
+		if passphrase != "" && a.readPassphrase("Repeat passphrase: ") != passphrase {
+			return errors.New("passphrases don't match")
+		}
+		if err := p.SetPassphrase(passphrase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPassphrase prompts for a passphrase, hiding it as it's typed when
+// stdin is a terminal
+func (a *App) readPassphrase(prompt string) string {
+	if file, ok := a.Stdin.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 && stty(file, "-echo") == nil {
+			defer func() {
+				stty(file, "echo")
+				a.println()
+			}()
+		}
+	}
+	return a.prompt(prompt)
+}
+
+// stty changes a terminal's settings, where the stty command exists
+func stty(terminal *os.File, setting string) error {
+	cmd := exec.Command("stty", setting)
+	cmd.Stdin = terminal
+	return cmd.Run()
+}
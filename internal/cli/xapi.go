@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/xapi"
+)
+
+// xapiBuilder returns the statement builder for the learner's sessions
+func xapiBuilder(user learner) xapi.Builder {
+	return xapi.Builder{
+		ActivityBase: user.config.XAPI.ActivityBase,
+		LearnerName:  user.profile.Name,
+		PassingScore: user.config.XAPI.PassingScore,
+	}
+}
+
+// xapiClient returns a client for the config file's LRS, buffering in the
+// storage directory, or nil if there is none
+func (a *App) xapiClient(config *Config) *xapi.Client {
+	if config.XAPI.Endpoint == "" {
+		return nil
+	}
+	client := xapi.NewClient(config.XAPI.Endpoint, &xapi.Spool{Path: filepath.Join(config.StorageDir, "xapi-buffer.ndjson")})
+	client.Username = config.XAPI.Username
+	client.Password = a.Getenv("TRAINER_XAPI_PASSWORD")
+	if a.HTTPClient != nil {
+		client.HTTPClient = a.HTTPClient
+	}
+	return client
+}
+
+// xapiSink returns where the config file sends statements: its spool, its
+// LRS or both
+func (a *App) xapiSink(config *Config) xapi.Sink {
+	var sinks xapi.Sinks
+	if config.XAPI.Spool != "" {
+		sinks = append(sinks, &xapi.Spool{Path: config.XAPI.Spool})
+	}
+	if client := a.xapiClient(config); client != nil {
+		sinks = append(sinks, client)
+	}
+	return sinks
+}
+
+// reportPending warns about statements still waiting for the LRS
+func (a *App) reportPending(client *xapi.Client) {
+	if client == nil {
+		return
+	}
+	if pending, err := client.Pending(); err == nil && pending > 0 {
+		fmt.Fprintf(a.Stderr, "%d xAPI statement(s) are buffered until the LRS can be reached; send them with: trainer xapi flush\n", pending)
+	}
+}
+
+// xapi exports sessions as xAPI statements or sends buffered ones:
+// xapi export|flush
+func (a *App) xapi(opts *options, args []string) error {
+	if len(args) == 0 {
+		return usagef("xapi needs a command: export or flush")
+	}
+	command, args := args[0], args[1:]
+	switch command {
+	case "export":
+		return a.xapiExport(opts, args)
+	case "flush":
+		if len(args) > 0 {
+			return usagef("xapi flush takes no arguments")
+		}
+		return a.xapiFlush(opts)
+	}
+	return usagef("unknown xapi command %q (want export or flush)", command)
+}
+
+// xapiExport builds statements from saved sessions, live and archived: the
+// ones named, or all of the learner's. They're printed one per line, or
+// with --send go where the config file sends live statements.
+func (a *App) xapiExport(opts *options, ids []string) error {
+	config, user, err := a.setup(opts)
+	if err != nil {
+		return err
+	}
+	if opts.send && !config.XAPI.enabled() {
+		return usagef("--send needs an xapi spool or endpoint in the config file")
+	}
+	registry, err := a.loadRegistry(config)
+	if err != nil {
+		return err
+	}
+	sessionStorage, closeSessions, err := a.openSessions(user, opts)
+	if err != nil {
+		return err
+	}
+	defer closeSessions()
+
+	sessions, err := a.exportedSessions(user, sessionStorage, ids)
+	if err != nil {
+		return err
+	}
+	builder := xapiBuilder(user)
+	var statements []xapi.Statement
+	for _, session := range sessions {
+		for _, event := range xapi.SessionEvents(session, registry.GetAll()) {
+			statements = append(statements, builder.Statements(event)...)
+		}
+	}
+
+	if !opts.send {
+		for _, statement := range statements {
+			data, err := json.Marshal(statement)
+			if err != nil {
+				return fmt.Errorf("failed to marshal statement: %w", err)
+			}
+			a.printf("%s\n", data)
+		}
+		return nil
+	}
+
+	client := a.xapiClient(config)
+	if err := a.xapiSink(config).Send(statements); err != nil {
+		return fmt.Errorf("failed to send statements: %w", err)
+	}
+	a.printf("Exported %d statement(s) from %d session(s).\n", len(statements), len(sessions))
+	a.reportPending(client)
+	return nil
+}
+
+// exportedSessions loads the sessions named, from storage or the archive,
+// or lists all the learner's sessions if none are
+func (a *App) exportedSessions(user learner, sessionStorage storage.SessionStorage, ids []string) ([]*models.TrainingSession, error) {
+	stores := []storage.SessionStorage{sessionStorage, user.archive()}
+	var sessions []*models.TrainingSession
+	if len(ids) == 0 {
+		for _, store := range stores {
+			found, err := storage.QuerySessions(store, storage.SessionQuery{UserID: user.profile.ID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list sessions: %w", err)
+			}
+			sessions = append(sessions, found...)
+		}
+		return sessions, nil
+	}
+
+	for _, id := range ids {
+		session, err := a.loadSession(user, sessionStorage, id)
+		var exit *exitError
+		if errors.As(err, &exit) && exit.code == ExitNotFound {
+			session, err = a.loadSession(user, user.archive(), id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// xapiFlush sends the statements buffered while the LRS couldn't be reached
+func (a *App) xapiFlush(opts *options) error {
+	config, err := a.loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	client := a.xapiClient(config)
+	if client == nil {
+		return usagef("no xapi endpoint in the config file")
+	}
+	pending, err := client.Pending()
+	if err != nil {
+		return err
+	}
+	if err := client.Flush(); err != nil {
+		return fmt.Errorf("failed to send buffered statements: %w", err)
+	}
+	a.printf("Sent %d buffered statement(s).\n", pending)
+	return nil
+}
@@ -0,0 +1,74 @@
+package exercises
+
+import (
+	"embed"
+	"fmt"
+	"sync"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// builtinContent holds the Go fundamentals curriculum shipped with the binary
+//
+//go:embed content
+var builtinContent embed.FS
+
+var (
+	builtinPack     *Pack
+	builtinPackErr  error
+	builtinPackOnce sync.Once
+)
+
+// Builtin returns the built-in curriculum pack
+func Builtin() (*Pack, error) {
+	builtinPackOnce.Do(func() {
+		builtinPack, builtinPackErr = LoadPack(builtinContent, "content")
+	})
+	return builtinPack, builtinPackErr
+}
+
+// mustBuiltin returns the built-in pack, which is validated by the test suite
+func mustBuiltin() *Pack {
+	pack, err := Builtin()
+	if err != nil {
+		panic(fmt.Sprintf("built-in exercise content is invalid: %v", err))
+	}
+	return pack
+}
+
+// builtinExercise returns a copy of one built-in exercise by ID
+func builtinExercise(id string) models.Exercise {
+	for _, exercise := range mustBuiltin().Exercises {
+		if exercise.ID == id {
+			return exercise.Clone()
+		}
+	}
+	panic(fmt.Sprintf("built-in exercise %q not found", id))
+}
+
+// GetVariablesExercise returns the variables learning module
+// Applies CLT principles: worked examples, progressive disclosure, variability
+func GetVariablesExercise() models.Exercise {
+	return builtinExercise("variables")
+}
+
+// GetBasicTypesExercise returns the basic types learning module
+func GetBasicTypesExercise() models.Exercise {
+	return builtinExercise("basic-types")
+}
+
+// GetCompositeTypesExercise returns the composite types learning module
+func GetCompositeTypesExercise() models.Exercise {
+	return builtinExercise("composite-types")
+}
+
+// GetFunctionsExercise returns the functions learning module
+// Applies progressive disclosure and worked examples
+func GetFunctionsExercise() models.Exercise {
+	return builtinExercise("functions")
+}
+
+// GetStructsExercise returns the structs learning module
+func GetStructsExercise() models.Exercise {
+	return builtinExercise("structs")
+}
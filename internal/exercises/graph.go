@@ -0,0 +1,266 @@
+package exercises
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// MissingPrerequisiteError reports a prerequisite that names no known exercise
+type MissingPrerequisiteError struct {
+	Exercise     string
+	Prerequisite string
+}
+
+// Error implements error
+func (e *MissingPrerequisiteError) Error() string {
+	return fmt.Sprintf("exercise %q requires unknown exercise %q", e.Exercise, e.Prerequisite)
+}
+
+// CycleError reports exercises that require each other, directly or not
+type CycleError struct {
+	Cycle []string // e.g. [a b a]: a requires b, which requires a
+}
+
+// Error implements error
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("prerequisite cycle: %s", strings.Join(e.Cycle, " -> "))
+}
+
+// Graph is the prerequisite graph of a curriculum
+type Graph struct {
+	ids        []string // Declaration order, used to break ties
+	position   map[string]int
+	requires   map[string][]string
+	dependents map[string][]string
+	order      []string // Topological learning order
+}
+
+// NewGraph builds the prerequisite graph for a set of exercises, rejecting
+// unknown prerequisites and cycles
+func NewGraph(exercises []models.Exercise) (*Graph, error) {
+	g := &Graph{
+		position:   make(map[string]int, len(exercises)),
+		requires:   make(map[string][]string, len(exercises)),
+		dependents: make(map[string][]string, len(exercises)),
+	}
+
+	for i, exercise := range exercises {
+		if _, dup := g.position[exercise.ID]; dup {
+			return nil, fmt.Errorf("exercise %q is defined more than once", exercise.ID)
+		}
+		g.ids = append(g.ids, exercise.ID)
+		g.position[exercise.ID] = i
+	}
+
+	for _, exercise := range exercises {
+		seen := map[string]bool{}
+		for _, prereq := range exercise.Prerequisites {
+			if _, known := g.position[prereq]; !known {
+				return nil, &MissingPrerequisiteError{Exercise: exercise.ID, Prerequisite: prereq}
+			}
+			if seen[prereq] {
+				continue
+			}
+			seen[prereq] = true
+			g.requires[exercise.ID] = append(g.requires[exercise.ID], prereq)
+			g.dependents[prereq] = append(g.dependents[prereq], exercise.ID)
+		}
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, &CycleError{Cycle: cycle}
+	}
+	g.order = g.sort()
+	return g, nil
+}
+
+// findCycle returns one prerequisite cycle, or nil if there is none
+func (g *Graph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(g.ids))
+	var stack []string
+
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, prereq := range g.requires[id] {
+			switch state[prereq] {
+			case visiting:
+				for i, onStack := range stack {
+					if onStack == prereq {
+						return append(append([]string{}, stack[i:]...), prereq)
+					}
+				}
+			case unvisited:
+				if cycle := visit(prereq); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+		return nil
+	}
+
+	for _, id := range g.ids {
+		if state[id] == unvisited {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// sort orders exercises so each comes after its prerequisites. Among
+// exercises that are ready at the same time, declaration order wins, so the
+// result is stable across runs.
+func (g *Graph) sort() []string {
+	waiting := make(map[string]int, len(g.ids))
+	for _, id := range g.ids {
+		waiting[id] = len(g.requires[id])
+	}
+
+	order := make([]string, 0, len(g.ids))
+	placed := make(map[string]bool, len(g.ids))
+	for len(order) < len(g.ids) {
+		for _, id := range g.ids {
+			if placed[id] || waiting[id] > 0 {
+				continue
+			}
+			placed[id] = true
+			order = append(order, id)
+			for _, dependent := range g.dependents[id] {
+				waiting[dependent]--
+			}
+			break
+		}
+	}
+	return order
+}
+
+// Order returns every exercise ID in learning order
+func (g *Graph) Order() []string {
+	return append([]string{}, g.order...)
+}
+
+// Contains reports whether the graph has an exercise with the given ID
+func (g *Graph) Contains(id string) bool {
+	_, ok := g.position[id]
+	return ok
+}
+
+// Prerequisites returns the exercises that id directly requires
+func (g *Graph) Prerequisites(id string) []string {
+	return append([]string{}, g.requires[id]...)
+}
+
+// Available returns the exercises not yet completed whose prerequisites all
+// are, in learning order
+func (g *Graph) Available(completed []string) []string {
+	done := toSet(completed)
+	var available []string
+	for _, id := range g.order {
+		if !done[id] && g.ready(id, done) {
+			available = append(available, id)
+		}
+	}
+	return available
+}
+
+// Next returns the first available exercise in learning order
+func (g *Graph) Next(completed []string) (string, bool) {
+	available := g.Available(completed)
+	if len(available) == 0 {
+		return "", false
+	}
+	return available[0], true
+}
+
+// Unlocks returns the exercises that become available once id is completed
+// on top of the completed ones, in learning order
+func (g *Graph) Unlocks(id string, completed []string) []string {
+	done := toSet(completed)
+	if done[id] {
+		return nil
+	}
+	done[id] = true
+
+	var unlocked []string
+	for _, candidate := range g.order {
+		if done[candidate] || !contains(g.requires[candidate], id) {
+			continue
+		}
+		if g.ready(candidate, done) {
+			unlocked = append(unlocked, candidate)
+		}
+	}
+	return unlocked
+}
+
+// PathTo returns the shortest sequence of exercises that reaches target:
+// every prerequisite of target, direct or indirect, that is not completed,
+// followed by target itself, in learning order
+func (g *Graph) PathTo(target string, completed []string) ([]string, error) {
+	if !g.Contains(target) {
+		return nil, fmt.Errorf("unknown exercise %q", target)
+	}
+
+	done := toSet(completed)
+	needed := map[string]bool{}
+	var collect func(id string)
+	collect = func(id string) {
+		if needed[id] || done[id] {
+			return
+		}
+		needed[id] = true
+		for _, prereq := range g.requires[id] {
+			collect(prereq)
+		}
+	}
+	collect(target)
+
+	var path []string
+	for _, id := range g.order {
+		if needed[id] {
+			path = append(path, id)
+		}
+	}
+	return path, nil
+}
+
+// ready reports whether every prerequisite of id is in done
+func (g *Graph) ready(id string, done map[string]bool) bool {
+	for _, prereq := range g.requires[id] {
+		if !done[prereq] {
+			return false
+		}
+	}
+	return true
+}
+
+// toSet turns a list of IDs into a set
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// contains reports whether ids includes id
+func contains(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,396 @@
+package exercises
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+// manifestFile names the file that describes a content pack
+const manifestFile = "pack.json"
+
+// Pack is a set of exercises loaded from data files
+type Pack struct {
+	Name      string
+	Title     string
+	Exercises []models.Exercise // In the order listed by the manifest
+}
+
+// ContentError reports malformed content with the file and field at fault
+type ContentError struct {
+	File  string
+	Field string // e.g. challenges[1].rules[0].kind; empty for whole-file errors
+	Err   error
+}
+
+// Error implements error
+func (e *ContentError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %v", e.File, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", e.File, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ContentError) Unwrap() error {
+	return e.Err
+}
+
+// packManifest is the JSON form of pack.json
+type packManifest struct {
+	Name      string   `json:"name"`
+	Title     string   `json:"title"`
+	Exercises []string `json:"exercises"` // Exercise files, in learning order
+}
+
+// exerciseFile is the JSON form of one exercise
+type exerciseFile struct {
+	ID             string          `json:"id"`
+	Title          string          `json:"title"`
+	Description    text            `json:"description"`
+	CognitiveLevel string          `json:"cognitive_level"`
+	ExerciseType   string          `json:"exercise_type"`
+	Prerequisites  []string        `json:"prerequisites"`
+	LearningGoals  []string        `json:"learning_goals"`
+	EstimatedTime  int             `json:"estimated_time"`
+	Notes          string          `json:"notes"` // Markdown file with example explanations
+	Examples       []exampleFile   `json:"examples"`
+	Support        []exampleFile   `json:"support_examples"` // Shown only when adaptive pacing calls for them
+	Challenges     []challengeFile `json:"challenges"`
+}
+
+// exampleFile is the JSON form of a worked example
+type exampleFile struct {
+	Title       string `json:"title"`
+	Code        text   `json:"code"`
+	Explanation text   `json:"explanation"` // May instead come from the notes file
+	Output      text   `json:"output"`
+}
+
+// challengeFile is the JSON form of a practice challenge
+type challengeFile struct {
+	Description    string                `json:"description"`
+	Concepts       []string              `json:"concepts"`
+	Template       text                  `json:"template"`
+	Solution       text                  `json:"solution"`
+	Hints          []string              `json:"hints"`
+	Rules          []validation.RuleSpec `json:"rules"`
+	ExpectedOutput text                  `json:"expected_output"`
+	Tests          text                  `json:"tests"`
+	TestsFile      string                `json:"tests_file"` // Hidden test file, relative to the pack
+}
+
+// text is a string that may be written in JSON as a string or as an array
+// of lines, which keeps multi-line Go code readable in data files
+type text string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *text) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = text(single)
+		return nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return fmt.Errorf("want a string or an array of lines")
+	}
+	*t = text(strings.Join(lines, "\n"))
+	return nil
+}
+
+// LoadDir loads a content pack from a directory on disk
+func LoadDir(dir string) (*Pack, error) {
+	return LoadPack(os.DirFS(dir), ".")
+}
+
+// LoadPack loads the pack whose pack.json lives in dir within fsys
+func LoadPack(fsys fs.FS, dir string) (*Pack, error) {
+	manifestPath := path.Join(dir, manifestFile)
+	var manifest packManifest
+	if err := decodeJSON(fsys, manifestPath, &manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Name == "" {
+		return nil, &ContentError{File: manifestPath, Field: "name", Err: errors.New("required")}
+	}
+	if len(manifest.Exercises) == 0 {
+		return nil, &ContentError{File: manifestPath, Field: "exercises", Err: errors.New("lists no exercise files")}
+	}
+
+	pack := &Pack{Name: manifest.Name, Title: manifest.Title}
+	seen := map[string]string{}
+	for i, name := range manifest.Exercises {
+		exercise, err := loadExercise(fsys, dir, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, &ContentError{File: manifestPath, Field: fmt.Sprintf("exercises[%d]", i), Err: fmt.Errorf("file %q not found", name)}
+			}
+			return nil, err
+		}
+		if other, dup := seen[exercise.ID]; dup {
+			return nil, &ContentError{File: path.Join(dir, name), Field: "id", Err: fmt.Errorf("%q is already used by %s", exercise.ID, other)}
+		}
+		seen[exercise.ID] = name
+		pack.Exercises = append(pack.Exercises, exercise)
+	}
+	return pack, nil
+}
+
+// loadExercise reads one exercise file and the files it refers to
+func loadExercise(fsys fs.FS, dir, name string) (models.Exercise, error) {
+	file := path.Join(dir, name)
+	var data exerciseFile
+	if err := decodeJSON(fsys, file, &data); err != nil {
+		return models.Exercise{}, err
+	}
+
+	fail := func(field string, err error) (models.Exercise, error) {
+		return models.Exercise{}, &ContentError{File: file, Field: field, Err: err}
+	}
+
+	if data.ID == "" {
+		return fail("id", errors.New("required"))
+	}
+	if data.Title == "" {
+		return fail("title", errors.New("required"))
+	}
+
+	level, err := models.ParseCognitiveLevel(data.CognitiveLevel)
+	if err != nil {
+		return fail("cognitive_level", err)
+	}
+	exerciseType, err := models.ParseExerciseType(data.ExerciseType)
+	if err != nil {
+		return fail("exercise_type", err)
+	}
+
+	notes := map[string]string{}
+	if data.Notes != "" {
+		notesPath := path.Join(dir, data.Notes)
+		content, err := fs.ReadFile(fsys, notesPath)
+		if err != nil {
+			return fail("notes", err)
+		}
+		if notes, err = parseNotes(content); err != nil {
+			return models.Exercise{}, &ContentError{File: notesPath, Err: err}
+		}
+	}
+
+	exercise := models.Exercise{
+		ID:             data.ID,
+		Title:          data.Title,
+		Description:    string(data.Description),
+		CognitiveLevel: level,
+		ExerciseType:   exerciseType,
+		Prerequisites:  append([]string{}, data.Prerequisites...),
+		LearningGoals:  data.LearningGoals,
+		EstimatedTime:  data.EstimatedTime,
+	}
+
+	used := map[string]bool{}
+	buildExamples := func(field string, examples []exampleFile) ([]models.Example, error) {
+		var built []models.Example
+		for i, example := range examples {
+			field := fmt.Sprintf("%s[%d]", field, i)
+			if example.Title == "" {
+				return nil, &ContentError{File: file, Field: field + ".title", Err: errors.New("required")}
+			}
+			if example.Code == "" {
+				return nil, &ContentError{File: file, Field: field + ".code", Err: errors.New("required")}
+			}
+
+			explanation := string(example.Explanation)
+			if note, ok := notes[example.Title]; ok {
+				if explanation != "" {
+					return nil, &ContentError{File: file, Field: field + ".explanation", Err: fmt.Errorf("also given in %s", data.Notes)}
+				}
+				explanation = note
+				used[example.Title] = true
+			}
+			if explanation == "" {
+				return nil, &ContentError{File: file, Field: field + ".explanation", Err: fmt.Errorf("required, inline or as a \"## %s\" section in the notes file", example.Title)}
+			}
+
+			built = append(built, models.Example{
+				Title:       example.Title,
+				Code:        string(example.Code),
+				Explanation: explanation,
+				Output:      string(example.Output),
+			})
+		}
+		return built, nil
+	}
+
+	if exercise.Examples, err = buildExamples("examples", data.Examples); err != nil {
+		return models.Exercise{}, err
+	}
+	if exercise.SupportExamples, err = buildExamples("support_examples", data.Support); err != nil {
+		return models.Exercise{}, err
+	}
+
+	for title := range notes {
+		if !used[title] {
+			return models.Exercise{}, &ContentError{File: path.Join(dir, data.Notes), Err: fmt.Errorf("section %q does not match any example", title)}
+		}
+	}
+
+	for i, challenge := range data.Challenges {
+		field := fmt.Sprintf("challenges[%d]", i)
+		if challenge.Description == "" {
+			return fail(field+".description", errors.New("required"))
+		}
+		if challenge.Solution == "" {
+			return fail(field+".solution", errors.New("required"))
+		}
+
+		tests := string(challenge.Tests)
+		if challenge.TestsFile != "" {
+			if tests != "" {
+				return fail(field+".tests_file", errors.New("cannot be combined with tests"))
+			}
+			content, err := fs.ReadFile(fsys, path.Join(dir, challenge.TestsFile))
+			if err != nil {
+				return fail(field+".tests_file", err)
+			}
+			tests = string(content)
+		}
+
+		rules := make([]validation.Rule, 0, len(challenge.Rules))
+		for j, spec := range challenge.Rules {
+			rule, err := spec.Build()
+			if err != nil {
+				var specErr *validation.SpecError
+				if errors.As(err, &specErr) {
+					return fail(fmt.Sprintf("%s.rules[%d].%s", field, j, specErr.Field), specErr.Err)
+				}
+				return fail(fmt.Sprintf("%s.rules[%d]", field, j), err)
+			}
+			rules = append(rules, rule)
+		}
+
+		for j, concept := range challenge.Concepts {
+			if strings.TrimSpace(concept) == "" {
+				return fail(fmt.Sprintf("%s.concepts[%d]", field, j), errors.New("concept is empty"))
+			}
+		}
+
+		if len(rules) == 0 && challenge.ExpectedOutput == "" && tests == "" {
+			return fail(field+".rules", errors.New("a challenge needs rules, expected_output or tests to check answers"))
+		}
+
+		exercise.Challenges = append(exercise.Challenges, models.Challenge{
+			Description:    challenge.Description,
+			Concepts:       challenge.Concepts,
+			Template:       string(challenge.Template),
+			Solution:       string(challenge.Solution),
+			Hints:          challenge.Hints,
+			Rules:          rules,
+			ExpectedOutput: string(challenge.ExpectedOutput),
+			Tests:          tests,
+		})
+	}
+
+	return withValidators(exercise), nil
+}
+
+// decodeJSON strictly decodes a JSON file, reporting syntax errors by line
+// and unknown or mistyped fields by name
+func decodeJSON(fsys fs.FS, file string, v any) error {
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return &ContentError{File: file, Err: err}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// Offset counts the offending byte, so step back to point at it
+			line, column := lineColumn(data, max(syntaxErr.Offset-1, 0))
+			return &ContentError{File: fmt.Sprintf("%s:%d:%d", file, line, column), Err: syntaxErr}
+		case errors.As(err, &typeErr):
+			return &ContentError{File: file, Field: typeErr.Field, Err: fmt.Errorf("cannot use JSON %s as %s", typeErr.Value, typeErr.Type)}
+		case errors.Is(err, io.EOF):
+			return &ContentError{File: file, Err: errors.New("file is empty")}
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return &ContentError{File: file, Field: strings.Trim(field, `"`), Err: errors.New("unknown field")}
+		}
+		return &ContentError{File: file, Err: err}
+	}
+	return nil
+}
+
+// lineColumn converts a byte offset into a 1-based line and column
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// parseNotes splits a Markdown notes file into "## Title" sections. Text
+// before the first section, such as a top-level heading, is ignored.
+func parseNotes(content []byte) (map[string]string, error) {
+	sections := map[string]string{}
+	var title string
+	var body []string
+
+	flush := func() error {
+		if title == "" {
+			return nil
+		}
+		if _, dup := sections[title]; dup {
+			return fmt.Errorf("section %q appears more than once", title)
+		}
+		sections[title] = strings.TrimSpace(strings.Join(body, "\n"))
+		if sections[title] == "" {
+			return fmt.Errorf("section %q is empty", title)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			title, body = strings.TrimSpace(heading), nil
+			continue
+		}
+		if title != "" {
+			body = append(body, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
@@ -1,75 +1,173 @@
 package exercises
 
-import "github.com/cmyers78/claude/internal/models"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/validation"
+)
 
 // Registry holds all available exercises
 type Registry struct {
 	exercises map[string]models.Exercise
+	ids       []string          // Declaration order: packs in the order added, then manifest order
+	packs     map[string]string // Exercise ID to the pack that defined it
+	graph     *Graph
 }
 
-// NewRegistry creates a new exercise registry
+// NewRegistry creates a registry holding the built-in curriculum
 func NewRegistry() *Registry {
-	registry := &Registry{
-		exercises: make(map[string]models.Exercise),
+	registry := newEmptyRegistry()
+	if err := registry.AddPack(mustBuiltin()); err != nil {
+		panic(fmt.Sprintf("built-in exercise content is invalid: %v", err))
 	}
-	
-	// Register all exercises
-	registry.exercises["variables"] = GetVariablesExercise()
-	registry.exercises["basic-types"] = GetBasicTypesExercise()
-	registry.exercises["composite-types"] = GetCompositeTypesExercise()
-	registry.exercises["functions"] = GetFunctionsExercise()
-	registry.exercises["structs"] = GetStructsExercise()
-	
 	return registry
 }
 
-// GetAll returns all exercises in learning order
-func (r *Registry) GetAll() []models.Exercise {
-	// Return exercises in pedagogical order
-	order := []string{"variables", "basic-types", "composite-types", "functions", "structs"}
-	
-	var exercises []models.Exercise
-	for _, id := range order {
-		if exercise, exists := r.exercises[id]; exists {
-			exercises = append(exercises, exercise)
+// LoadRegistry creates a registry holding the built-in curriculum plus the
+// content packs in each of the given directories. Packs may require
+// exercises from one another regardless of the order they are given in.
+func LoadRegistry(dirs ...string) (*Registry, error) {
+	registry := NewRegistry()
+	var packs []*Pack
+	for _, dir := range dirs {
+		pack, err := LoadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exercises from %s: %w", dir, err)
 		}
+		packs = append(packs, pack)
 	}
-	
-	return exercises
+	if err := registry.addPacks(packs...); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// newEmptyRegistry creates a registry with no exercises
+func newEmptyRegistry() *Registry {
+	graph, _ := NewGraph(nil)
+	return &Registry{
+		exercises: make(map[string]models.Exercise),
+		packs:     make(map[string]string),
+		graph:     graph,
+	}
+}
+
+// AddPack registers every exercise in a pack. Exercise IDs must be unique
+// across packs, and prerequisites must name registered exercises without
+// forming a cycle; otherwise the registry is left unchanged.
+func (r *Registry) AddPack(pack *Pack) error {
+	return r.addPacks(pack)
+}
+
+// addPacks registers several packs at once and rebuilds the prerequisite graph
+func (r *Registry) addPacks(packs ...*Pack) error {
+	exercises := maps.Clone(r.exercises)
+	owners := maps.Clone(r.packs)
+	ids := slices.Clone(r.ids)
+
+	for _, pack := range packs {
+		for _, exercise := range pack.Exercises {
+			if owner, exists := owners[exercise.ID]; exists {
+				return fmt.Errorf("pack %q: exercise %q is already defined by pack %q", pack.Name, exercise.ID, owner)
+			}
+			exercises[exercise.ID] = exercise
+			owners[exercise.ID] = pack.Name
+			ids = append(ids, exercise.ID)
+		}
+	}
+
+	list := make([]models.Exercise, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, exercises[id])
+	}
+	graph, err := NewGraph(list)
+	if err != nil {
+		var missing *MissingPrerequisiteError
+		if errors.As(err, &missing) {
+			return fmt.Errorf("pack %q: %w", owners[missing.Exercise], err)
+		}
+		return err
+	}
+
+	r.exercises, r.packs, r.ids, r.graph = exercises, owners, ids, graph
+	return nil
+}
+
+// Graph returns the prerequisite graph of the registered exercises
+func (r *Registry) Graph() *Graph {
+	return r.graph
+}
+
+// GetAll returns all exercises in learning order, each after its prerequisites
+func (r *Registry) GetAll() []models.Exercise {
+	return r.lookup(r.graph.Order())
 }
 
 // GetByID returns a specific exercise by ID
 func (r *Registry) GetByID(id string) (models.Exercise, bool) {
 	exercise, exists := r.exercises[id]
-	return exercise, exists
+	return exercise.Clone(), exists
 }
 
-// GetByPrerequisites returns exercises that match the given prerequisites
+// GetByPrerequisites returns exercises whose prerequisites are all completed,
+// in learning order
 func (r *Registry) GetByPrerequisites(completed []string) []models.Exercise {
-	var available []models.Exercise
-	
-	for _, exercise := range r.exercises {
-		if r.hasPrerequisites(exercise.Prerequisites, completed) {
-			available = append(available, exercise)
+	done := toSet(completed)
+	var available []string
+	for _, id := range r.graph.Order() {
+		if r.graph.ready(id, done) {
+			available = append(available, id)
 		}
 	}
-	
-	return available
+	return r.lookup(available)
 }
 
-// hasPrerequisites checks if all prerequisites are met
-func (r *Registry) hasPrerequisites(required []string, completed []string) bool {
-	for _, req := range required {
-		found := false
-		for _, comp := range completed {
-			if req == comp {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
+// Next returns the first exercise in learning order that is not completed
+// and whose prerequisites are
+func (r *Registry) Next(completed []string) (models.Exercise, bool) {
+	id, ok := r.graph.Next(completed)
+	if !ok {
+		return models.Exercise{}, false
+	}
+	return r.exercises[id].Clone(), true
+}
+
+// Unlocks returns the exercises that completing id makes available
+func (r *Registry) Unlocks(id string, completed []string) []models.Exercise {
+	return r.lookup(r.graph.Unlocks(id, completed))
+}
+
+// PathTo returns the exercises still to complete, in order, to reach target
+func (r *Registry) PathTo(target string, completed []string) ([]models.Exercise, error) {
+	path, err := r.graph.PathTo(target, completed)
+	if err != nil {
+		return nil, err
+	}
+	return r.lookup(path), nil
+}
+
+// lookup maps exercise IDs to copies of the exercises, which callers may
+// change without affecting the registry or each other
+func (r *Registry) lookup(ids []string) []models.Exercise {
+	exercises := make([]models.Exercise, 0, len(ids))
+	for _, id := range ids {
+		exercises = append(exercises, r.exercises[id].Clone())
+	}
+	return exercises
+}
+
+// withValidators returns a copy of an exercise with each challenge's
+// Validator built from its rules and expected output
+func withValidators(exercise models.Exercise) models.Exercise {
+	exercise = exercise.Clone()
+	for i, challenge := range exercise.Challenges {
+		if checks := challenge.Checks(); challenge.Validator == nil && len(checks) > 0 {
+			exercise.Challenges[i].Validator = validation.Validator(challenge.Template, checks...)
 		}
 	}
-	return true
-}
\ No newline at end of file
+	return exercise
+}
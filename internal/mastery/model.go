@@ -0,0 +1,135 @@
+package mastery
+
+import (
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Params are the Bayesian Knowledge Tracing parameters for a concept
+type Params struct {
+	Init  float64 // P(L0): the concept is known before any practice
+	Learn float64 // P(T): an unknown concept is learned at each practice opportunity
+	Slip  float64 // P(S): a known concept is still answered wrongly
+	Guess float64 // P(G): an unknown concept is still answered correctly
+}
+
+// DefaultParams suit short programming challenges
+var DefaultParams = Params{Init: 0.3, Learn: 0.3, Slip: 0.1, Guess: 0.2}
+
+// DefaultThreshold is calibrated against DefaultParams: a clean solve from a
+// new learner reaches it (0.76), while a solve that needed a hint, or a
+// skip, leaves the concept to practice
+const DefaultThreshold = 0.75
+
+// Outcome is the evidence one challenge gives about its concepts
+type Outcome struct {
+	WrongAttempts int // Answers that didn't pass
+	HintsUsed     int
+	Solved        bool // False when skipped, revealed or out of attempts
+}
+
+// Model tracks a learner's mastery of each concept with Bayesian Knowledge
+// Tracing, updated from every answer
+type Model struct {
+	Params    Params
+	Threshold float64
+
+	concepts map[string]*models.ConceptMastery
+	order    []string // Concepts in the order first seen
+}
+
+// New creates a model with no observations
+func New(params Params, threshold float64) *Model {
+	return &Model{Params: params, Threshold: threshold, concepts: make(map[string]*models.ConceptMastery)}
+}
+
+// Restore creates a model from saved estimates
+func Restore(params Params, threshold float64, saved []models.ConceptMastery) *Model {
+	m := New(params, threshold)
+	for _, estimate := range saved {
+		m.concepts[estimate.Concept] = &estimate
+		m.order = append(m.order, estimate.Concept)
+	}
+	return m
+}
+
+// Observe updates a concept from one answer: first the posterior given the
+// answer, then the chance the concept was learned from the practice
+func (m *Model) Observe(concept string, correct bool) {
+	estimate := m.estimate(concept)
+	known := estimate.Probability
+	p := m.Params
+
+	var posterior float64
+	if correct {
+		posterior = known * (1 - p.Slip) / (known*(1-p.Slip) + (1-known)*p.Guess)
+		estimate.Correct++
+	} else {
+		posterior = known * p.Slip / (known*p.Slip + (1-known)*(1-p.Guess))
+	}
+	estimate.Probability = posterior + (1-posterior)*p.Learn
+	estimate.Observations++
+}
+
+// Record updates every concept a challenge exercises. Each wrong answer is
+// an incorrect observation. A solved challenge adds a correct one, unless
+// hints were needed; a challenge skipped without answering adds an
+// incorrect one.
+func (m *Model) Record(concepts []string, outcome Outcome) {
+	for _, concept := range concepts {
+		for range outcome.WrongAttempts {
+			m.Observe(concept, false)
+		}
+		switch {
+		case outcome.Solved:
+			m.Observe(concept, outcome.HintsUsed == 0)
+		case outcome.WrongAttempts == 0:
+			m.Observe(concept, false)
+		}
+	}
+}
+
+// Probability returns the estimated probability a concept is known
+func (m *Model) Probability(concept string) float64 {
+	if estimate, ok := m.concepts[concept]; ok {
+		return estimate.Probability
+	}
+	return m.Params.Init
+}
+
+// Mastered reports whether a concept has reached the threshold
+func (m *Model) Mastered(concept string) bool {
+	return m.Probability(concept) >= m.Threshold
+}
+
+// Unmastered returns the concepts, without duplicates, that are below the threshold
+func (m *Model) Unmastered(concepts []string) []string {
+	var weak []string
+	seen := make(map[string]bool)
+	for _, concept := range concepts {
+		if !seen[concept] && !m.Mastered(concept) {
+			weak = append(weak, concept)
+		}
+		seen[concept] = true
+	}
+	return weak
+}
+
+// Estimates returns every observed concept's estimate in the order first seen
+func (m *Model) Estimates() []models.ConceptMastery {
+	estimates := make([]models.ConceptMastery, len(m.order))
+	for i, concept := range m.order {
+		estimates[i] = *m.concepts[concept]
+	}
+	return estimates
+}
+
+// estimate returns a concept's estimate, starting it at the prior
+func (m *Model) estimate(concept string) *models.ConceptMastery {
+	if estimate, ok := m.concepts[concept]; ok {
+		return estimate
+	}
+	estimate := &models.ConceptMastery{Concept: concept, Probability: m.Params.Init}
+	m.concepts[concept] = estimate
+	m.order = append(m.order, concept)
+	return estimate
+}
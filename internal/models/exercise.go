@@ -1,5 +1,13 @@
 package models
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/validation"
+)
+
 // CognitiveLevel represents the complexity level based on Cognitive Load Theory
 type CognitiveLevel int
 
@@ -9,15 +17,57 @@ const (
 	Advanced
 )
 
+// cognitiveLevelNames are the spellings used in data files and flags
+var cognitiveLevelNames = []string{"beginner", "intermediate", "advanced"}
+
+// String returns the lowercase name of the level
+func (l CognitiveLevel) String() string {
+	if l < 0 || int(l) >= len(cognitiveLevelNames) {
+		return fmt.Sprintf("CognitiveLevel(%d)", int(l))
+	}
+	return cognitiveLevelNames[l]
+}
+
+// ParseCognitiveLevel parses "beginner", "intermediate" or "advanced"
+func ParseCognitiveLevel(name string) (CognitiveLevel, error) {
+	for i, known := range cognitiveLevelNames {
+		if strings.EqualFold(name, known) {
+			return CognitiveLevel(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cognitive level %q (want %s)", name, strings.Join(cognitiveLevelNames, ", "))
+}
+
 // ExerciseType categorizes the type of learning exercise
 type ExerciseType int
 
 const (
-	Concept ExerciseType = iota  // Understanding concepts
-	Application                  // Applying knowledge
-	Synthesis                   // Combining concepts
+	Concept     ExerciseType = iota // Understanding concepts
+	Application                     // Applying knowledge
+	Synthesis                       // Combining concepts
 )
 
+// exerciseTypeNames are the spellings used in data files
+var exerciseTypeNames = []string{"concept", "application", "synthesis"}
+
+// String returns the lowercase name of the exercise type
+func (e ExerciseType) String() string {
+	if e < 0 || int(e) >= len(exerciseTypeNames) {
+		return fmt.Sprintf("ExerciseType(%d)", int(e))
+	}
+	return exerciseTypeNames[e]
+}
+
+// ParseExerciseType parses "concept", "application" or "synthesis"
+func ParseExerciseType(name string) (ExerciseType, error) {
+	for i, known := range exerciseTypeNames {
+		if strings.EqualFold(name, known) {
+			return ExerciseType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown exercise type %q (want %s)", name, strings.Join(exerciseTypeNames, ", "))
+}
+
 // Example represents a single learning example
 type Example struct {
 	Title       string
@@ -28,11 +78,36 @@ type Example struct {
 
 // Challenge represents a practice challenge
 type Challenge struct {
-	Description string
-	Template    string
-	Solution    string
-	Hints       []string
-	Validator   func(string) bool
+	Description    string
+	Concepts       []string // Knowledge components the challenge exercises, e.g. "pointer-receivers"
+	Template       string
+	Solution       string
+	Hints          []string
+	Rules          []validation.Rule // Structural checks applied to the parsed answer
+	ExpectedOutput string            // Exact stdout of a correct program, if deterministic
+	Tests          string            // Hidden _test.go file the answer must pass
+	Validator      func(string) bool
+}
+
+// IsTestDriven reports whether the challenge is checked by hidden tests
+func (c Challenge) IsTestDriven() bool {
+	return c.Tests != ""
+}
+
+// Checks returns every rule an answer must satisfy: the structural rules,
+// plus compiling, matching ExpectedOutput and passing Tests when set
+func (c Challenge) Checks() []validation.Rule {
+	checks := append([]validation.Rule{}, c.Rules...)
+	if c.ExpectedOutput != "" || c.Tests != "" {
+		checks = append(checks, validation.Compiles())
+	}
+	if c.ExpectedOutput != "" {
+		checks = append(checks, validation.OutputMatches(c.ExpectedOutput))
+	}
+	if c.Tests != "" {
+		checks = append(checks, validation.PassesTests(c.Tests))
+	}
+	return checks
 }
 
 // Exercise represents a complete learning module
@@ -45,6 +120,23 @@ type Exercise struct {
 	Prerequisites   []string
 	LearningGoals   []string
 	Examples        []Example
+	SupportExamples []Example // Extra worked examples for learners who are struggling
 	Challenges      []Challenge
 	EstimatedTime   int // minutes
-}
\ No newline at end of file
+}
+
+// Clone returns a copy of the exercise that shares no slices with it, so
+// either can be changed without affecting the other
+func (e Exercise) Clone() Exercise {
+	e.Prerequisites = slices.Clone(e.Prerequisites)
+	e.LearningGoals = slices.Clone(e.LearningGoals)
+	e.Examples = slices.Clone(e.Examples)
+	e.SupportExamples = slices.Clone(e.SupportExamples)
+	e.Challenges = slices.Clone(e.Challenges)
+	for i, challenge := range e.Challenges {
+		e.Challenges[i].Concepts = slices.Clone(challenge.Concepts)
+		e.Challenges[i].Hints = slices.Clone(challenge.Hints)
+		e.Challenges[i].Rules = slices.Clone(challenge.Rules)
+	}
+	return e
+}
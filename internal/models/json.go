@@ -0,0 +1,150 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Durations are saved as strings such as "1h30m0s" rather than integer
+// nanoseconds, so session files stay readable and independent of units
+
+// duration is a time.Duration in its saved form
+type duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string like \"1h30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// durations converts a slice to its saved form
+func durations(ds []time.Duration) []duration {
+	if ds == nil {
+		return nil
+	}
+	saved := make([]duration, len(ds))
+	for i, d := range ds {
+		saved[i] = duration(d)
+	}
+	return saved
+}
+
+// fromDurations converts a slice from its saved form
+func fromDurations(saved []duration) []time.Duration {
+	if saved == nil {
+		return nil
+	}
+	ds := make([]time.Duration, len(saved))
+	for i, d := range saved {
+		ds[i] = time.Duration(d)
+	}
+	return ds
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Levels are saved as numbers;
+// names such as "intermediate" are accepted too, for hand-written config.
+func (l *CognitiveLevel) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		var level int
+		if err := json.Unmarshal(data, &level); err != nil {
+			return fmt.Errorf("cognitive level must be a name or a number: %w", err)
+		}
+		*l = CognitiveLevel(level)
+		return nil
+	}
+	level, err := ParseCognitiveLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (p LearningProgress) MarshalJSON() ([]byte, error) {
+	type plain LearningProgress
+	return json.Marshal(struct {
+		plain
+		TimeSpent duration `json:"time_spent"`
+	}{plain(p), duration(p.TimeSpent)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *LearningProgress) UnmarshalJSON(data []byte) error {
+	type plain LearningProgress
+	saved := struct {
+		*plain
+		TimeSpent duration `json:"time_spent"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	p.TimeSpent = time.Duration(saved.TimeSpent)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (c TrainerConfig) MarshalJSON() ([]byte, error) {
+	type plain TrainerConfig
+	return json.Marshal(struct {
+		plain
+		TimeLimit    duration   `json:"time_limit"`
+		TimeWarnings []duration `json:"time_warnings,omitempty"`
+	}{plain(c), duration(c.TimeLimit), durations(c.TimeWarnings)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *TrainerConfig) UnmarshalJSON(data []byte) error {
+	type plain TrainerConfig
+	// Fields missing from data keep their current values, so a partial
+	// config can be decoded over defaults
+	saved := struct {
+		*plain
+		TimeLimit    duration   `json:"time_limit"`
+		TimeWarnings []duration `json:"time_warnings,omitempty"`
+	}{(*plain)(c), duration(c.TimeLimit), durations(c.TimeWarnings)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	c.TimeLimit = time.Duration(saved.TimeLimit)
+	c.TimeWarnings = fromDurations(saved.TimeWarnings)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (s TrainingSession) MarshalJSON() ([]byte, error) {
+	type plain TrainingSession
+	return json.Marshal(struct {
+		plain
+		ActiveTime duration `json:"active_time,omitempty"`
+	}{plain(s), duration(s.ActiveTime)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *TrainingSession) UnmarshalJSON(data []byte) error {
+	type plain TrainingSession
+	saved := struct {
+		*plain
+		ActiveTime duration `json:"active_time,omitempty"`
+	}{plain: (*plain)(s)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	s.ActiveTime = time.Duration(saved.ActiveTime)
+	return nil
+}
@@ -4,36 +4,100 @@ import "time"
 
 // LearningProgress tracks a learner's progress through exercises
 type LearningProgress struct {
-	ExerciseID    string
-	StartTime     time.Time
-	CompletedAt   *time.Time
-	Attempts      int
-	Score         float64
-	TimeSpent     time.Duration
-	HintsUsed     int
+	ExerciseID     string              `json:"exercise_id"`
+	StartTime      time.Time           `json:"start_time"`
+	CompletedAt    *time.Time          `json:"completed_at"`
+	Attempts       int                 `json:"attempts"`
+	Score          float64             `json:"score"`
+	TimeSpent      time.Duration       `json:"time_spent"`
+	HintsUsed      int                 `json:"hints_used"`
+	CompileErrors  int                 `json:"compile_errors"` // Attempts that failed to compile
+	WrongAnswers   int                 `json:"wrong_answers"`  // Attempts that compiled but did not meet the challenge
+	AttemptHistory []AttemptRecord     `json:"attempt_history,omitempty"`
+	Challenges     []ChallengeProgress `json:"challenges,omitempty"` // One entry per challenge posed, in order
+}
+
+// ChallengeProgress tracks a learner's work on one challenge of an exercise.
+// The answers submitted are kept in the exercise's AttemptHistory.
+type ChallengeProgress struct {
+	Challenge   int        `json:"challenge"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Attempts    int        `json:"attempts"`
+	HintsUsed   int        `json:"hints_used"`
+	Solved      bool       `json:"solved,omitempty"`
+	Skipped     bool       `json:"skipped,omitempty"` // The learner skipped to the solution
+}
+
+// AttemptRecord captures the result of one submitted answer so instructors
+// can see which hidden tests learners commonly fail
+type AttemptRecord struct {
+	Challenge    int       `json:"challenge"`
+	Attempt      int       `json:"attempt"`
+	SubmittedAt  time.Time `json:"submitted_at"`
+	Answer       string    `json:"answer,omitempty"`
+	Passed       bool      `json:"passed"`
+	CompileError bool      `json:"compile_error,omitempty"`
+	FailedTests  []string  `json:"failed_tests,omitempty"`
+}
+
+// AdaptiveAction names a pacing change made by adaptive pacing
+type AdaptiveAction string
+
+const (
+	ActionExtraExample   AdaptiveAction = "extra_example"   // Showed a support example
+	ActionSkipChallenges AdaptiveAction = "skip_challenges" // Skipped challenges a fluent learner didn't need
+	ActionLevelUp        AdaptiveAction = "level_up"        // Raised the learner's cognitive level
+	ActionLevelDown      AdaptiveAction = "level_down"      // Lowered the learner's cognitive level
+)
+
+// AdaptiveDecision records why the trainer changed what a learner saw
+type AdaptiveDecision struct {
+	At         time.Time      `json:"at"`
+	ExerciseID string         `json:"exercise_id"`
+	Challenge  int            `json:"challenge"` // Index of the challenge the decision followed; -1 for exercise-level decisions
+	Action     AdaptiveAction `json:"action"`
+	Reason     string         `json:"reason"`
+	Level      CognitiveLevel `json:"level"`             // Learner's level once the decision took effect
+	Example    string         `json:"example,omitempty"` // Title of the support example shown
+	Skipped    int            `json:"skipped,omitempty"` // Number of challenges skipped
 }
 
+// ConceptMastery is a learner's estimated mastery of one knowledge component
+type ConceptMastery struct {
+	Concept      string  `json:"concept"`
+	Probability  float64 `json:"probability"`  // Estimated probability the concept is known
+	Observations int     `json:"observations"` // Answers the estimate is based on
+	Correct      int     `json:"correct"`      // Of which were correct without help
+}
 
 // TrainerConfig holds configuration for the training session
 type TrainerConfig struct {
-	MaxAttempts     int
-	TimeLimit       time.Duration
-	ShowHints       bool
-	AdaptivePacing  bool
-	CognitiveLoad   CognitiveLevel
+	MaxAttempts        int             `json:"max_attempts"`
+	TimeLimit          time.Duration   `json:"time_limit"`                     // Time allowed per sitting before auto-pause; 0 for no limit
+	ExerciseTimeFactor float64         `json:"exercise_time_factor,omitempty"` // Per-exercise limit as a multiple of EstimatedTime; 0, in sessions saved before it existed, for no limit
+	TimeWarnings       []time.Duration `json:"time_warnings,omitempty"`        // Warn when this much time remains on either limit
+	ShowHints          bool            `json:"show_hints"`
+	AdaptivePacing     bool            `json:"adaptive_pacing"`
+	CognitiveLoad      CognitiveLevel  `json:"cognitive_load"`
+	MasteryThreshold   float64         `json:"mastery_threshold"` // Mastery probability every practiced concept must reach before moving on; 0 to only track mastery
 }
 
 // TrainingSession represents a saved training session that can be resumed
 type TrainingSession struct {
-	UserID         string              `json:"user_id"`
-	SessionID      string              `json:"session_id"`
-	Config         TrainerConfig       `json:"config"`
-	Progress       []LearningProgress  `json:"progress"`
-	CurrentIndex   int                 `json:"current_index"`
-	StartTime      time.Time           `json:"start_time"`
-	LastActivity   time.Time           `json:"last_activity"`
-	PausedAt       *time.Time          `json:"paused_at,omitempty"`
-	Status         SessionStatus       `json:"status"`
+	UserID       string             `json:"user_id"`
+	SessionID    string             `json:"session_id"`
+	Config       TrainerConfig      `json:"config"`
+	Progress     []LearningProgress `json:"progress"`
+	CurrentIndex int                `json:"current_index"`
+	StartTime    time.Time          `json:"start_time"`
+	ActiveTime   time.Duration      `json:"active_time,omitempty"` // Time trained before the last pause
+	LastActivity time.Time          `json:"last_activity"`
+	PausedAt     *time.Time         `json:"paused_at,omitempty"`
+	Status       SessionStatus      `json:"status"`
+	Decisions    []AdaptiveDecision `json:"decisions,omitempty"` // Adaptive pacing audit trail
+	Mastery      []ConceptMastery   `json:"mastery,omitempty"`   // Per-concept mastery estimates
+	Exercises    []string           `json:"exercises,omitempty"` // IDs of the exercises trained, in order; empty for the whole curriculum
 }
 
 // SessionStatus represents the current state of a training session
@@ -44,4 +108,4 @@ const (
 	SessionPaused    SessionStatus = "paused"
 	SessionCompleted SessionStatus = "completed"
 	SessionAbandoned SessionStatus = "abandoned"
-)
\ No newline at end of file
+)
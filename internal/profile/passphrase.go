@@ -0,0 +1,71 @@
+package profile
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// PBKDF2 parameters for new passphrases
+const (
+	passphraseIterations = 600_000
+	saltLength           = 16
+	keyLength            = sha256.Size
+)
+
+// Passphrase is a salted PBKDF2-HMAC-SHA256 hash of a profile's passphrase.
+// It keeps learners out of each other's profiles on a shared machine; it
+// does not encrypt their data.
+type Passphrase struct {
+	Salt       []byte `json:"salt"`
+	Hash       []byte `json:"hash"`
+	Iterations int    `json:"iterations"`
+}
+
+// hashPassphrase hashes a passphrase with a new random salt
+func hashPassphrase(passphrase string) (*Passphrase, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return &Passphrase{
+		Salt:       salt,
+		Hash:       pbkdf2([]byte(passphrase), salt, passphraseIterations, keyLength),
+		Iterations: passphraseIterations,
+	}, nil
+}
+
+// matches reports whether passphrase hashes to the stored hash
+func (p *Passphrase) matches(passphrase string) bool {
+	if p.Iterations <= 0 || len(p.Hash) == 0 {
+		return false
+	}
+	hash := pbkdf2([]byte(passphrase), p.Salt, p.Iterations, len(p.Hash))
+	return subtle.ConstantTimeCompare(hash, p.Hash) == 1
+}
+
+// pbkdf2 derives a key with PBKDF2 (RFC 8018) using HMAC-SHA256
+func pbkdf2(password, salt []byte, iterations, length int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < length; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := append([]byte(nil), u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:length]
+}
@@ -0,0 +1,132 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// DefaultID is the profile used when no learner has been chosen
+const DefaultID = "default"
+
+// ErrWrongPassphrase is returned when a protected profile is opened with
+// the wrong passphrase
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// validID limits profile IDs to names that are safe as directory names
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,31}$`)
+
+// Profile is one learner sharing the machine: their preferred settings,
+// the cognitive level they have reached and an optional passphrase
+type Profile struct {
+	ID         string                `json:"id"`
+	Name       string                `json:"name,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	Config     json.RawMessage       `json:"config,omitempty"` // Settings the learner chose, as trainer fields of the config file; the rest follow the config file
+	Level      models.CognitiveLevel `json:"level"`            // Level new sessions start at, kept up to date by adaptive pacing; overrides CognitiveLoad
+	Passphrase *Passphrase           `json:"passphrase,omitempty"`
+}
+
+// New creates a profile, checking that its ID is usable
+func New(id, name string) (*Profile, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	return &Profile{ID: id, Name: name, CreatedAt: time.Now()}, nil
+}
+
+// ValidateID reports whether id can name a profile: up to 32 lowercase
+// letters, digits, '.', '_' or '-', starting with a letter or digit
+func ValidateID(id string) error {
+	if !validID.MatchString(id) {
+		return fmt.Errorf("invalid user %q: use up to 32 lowercase letters, digits, '.', '_' or '-'", id)
+	}
+	return nil
+}
+
+// DisplayName returns the learner's name, or their ID if they gave none
+func (p *Profile) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// TrainerConfig returns defaults with the learner's preferred settings laid
+// over them, starting at the learner's cognitive level
+func (p *Profile) TrainerConfig(defaults models.TrainerConfig) (models.TrainerConfig, error) {
+	config := defaults
+	if len(p.Config) > 0 {
+		// Fields the learner didn't choose keep their defaults
+		if err := json.Unmarshal(p.Config, &config); err != nil {
+			return defaults, fmt.Errorf("invalid settings in profile %s: %w", p.ID, err)
+		}
+	}
+	config.CognitiveLoad = p.Level
+	return config, nil
+}
+
+// Prefer records the named fields of config, as the config file spells
+// them, as the learner's choice, keeping the fields chosen before
+func (p *Profile) Prefer(config models.TrainerConfig, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	chosen := make(map[string]json.RawMessage)
+	if len(p.Config) > 0 {
+		if err := json.Unmarshal(p.Config, &chosen); err != nil {
+			return fmt.Errorf("invalid settings in profile %s: %w", p.ID, err)
+		}
+	}
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			return fmt.Errorf("unknown trainer setting %q", field)
+		}
+		chosen[field] = value
+	}
+	p.Config, err = json.Marshal(chosen)
+	return err
+}
+
+// Protected reports whether the profile needs a passphrase to open
+func (p *Profile) Protected() bool {
+	return p.Passphrase != nil
+}
+
+// SetPassphrase protects the profile with passphrase, or removes the
+// protection if passphrase is empty
+func (p *Profile) SetPassphrase(passphrase string) error {
+	if passphrase == "" {
+		p.Passphrase = nil
+		return nil
+	}
+	hashed, err := hashPassphrase(passphrase)
+	if err != nil {
+		return err
+	}
+	p.Passphrase = hashed
+	return nil
+}
+
+// Unlock checks a passphrase against a protected profile. Profiles without
+// a passphrase accept any.
+func (p *Profile) Unlock(passphrase string) error {
+	if p.Passphrase == nil || p.Passphrase.matches(passphrase) {
+		return nil
+	}
+	return ErrWrongPassphrase
+}
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Format is the kind of document a report is rendered as
+type Format string
+
+const (
+	HTML      Format = "html"           // A self-contained web page
+	PrintHTML Format = "pdf-ready-html" // A web page laid out for printing or saving as PDF
+	Markdown  Format = "md"
+)
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case HTML, PrintHTML, Markdown:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown report format %q (want html, md or pdf-ready-html)", name)
+}
+
+// builtinTemplates holds the templates reports are rendered with by default
+//
+//go:embed templates
+var builtinTemplates embed.FS
+
+// builtinFiles lists the templates each format parses, in order; later
+// files redefine blocks of earlier ones
+var builtinFiles = map[Format][]string{
+	HTML:      {"templates/report.html.tmpl"},
+	PrintHTML: {"templates/report.html.tmpl", "templates/print.html.tmpl"},
+	Markdown:  {"templates/report.md.tmpl"},
+}
+
+// entry is the template a report is rendered from
+const entry = "report"
+
+// executor is the part of html/template and text/template rendering needs
+type executor interface {
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// Template renders documents in one format. HTML formats use html/template,
+// so everything shown is escaped; Markdown uses text/template with the md
+// function for escaping.
+type Template struct {
+	Format Format
+	exec   executor
+	name   string // Template executed
+}
+
+// New returns the built-in template for a format
+func New(format Format) (*Template, error) {
+	return Load(format, "")
+}
+
+// Load returns the template for a format, customized by the template file
+// at path if it isn't empty. A file that only defines templates overrides
+// those blocks of the built-in one, such as "style" or "certificate"; a
+// file with content outside any definition replaces it entirely.
+func Load(format Format, path string) (*Template, error) {
+	files, ok := builtinFiles[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+	var custom string
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report template: %w", err)
+		}
+		custom = string(data)
+	}
+
+	if format == Markdown {
+		t := template.New(entry).Funcs(template.FuncMap(funcs))
+		for _, file := range files {
+			if _, err := t.ParseFS(builtinTemplates, file); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+		}
+		name := entry
+		if path != "" {
+			customized, err := t.New(path).Parse(custom)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse report template: %w", err)
+			}
+			if !parse.IsEmptyTree(customized.Tree.Root) {
+				name = path
+			}
+		}
+		return &Template{Format: format, exec: t, name: name}, nil
+	}
+
+	t := htmltemplate.New(entry).Funcs(htmltemplate.FuncMap(funcs))
+	for _, file := range files {
+		if _, err := t.ParseFS(builtinTemplates, file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+	}
+	name := entry
+	if path != "" {
+		customized, err := t.New(path).Parse(custom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse report template: %w", err)
+		}
+		if !parse.IsEmptyTree(customized.Tree.Root) {
+			name = path
+		}
+	}
+	return &Template{Format: format, exec: t, name: name}, nil
+}
+
+// Render writes a document
+func (t *Template) Render(w io.Writer, doc *Document) error {
+	if err := t.exec.ExecuteTemplate(w, t.name, doc); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// funcs are the functions report templates may call
+var funcs = map[string]any{
+	"date":     func(t time.Time) string { return t.Format("2 January 2006") },
+	"datetime": func(t time.Time) string { return t.Format("2006-01-02 15:04 MST") },
+	"duration": formatDuration,
+	"score":    func(score float64) string { return fmt.Sprintf("%.1f", score) },
+	"percent": func(part, whole int) string {
+		if whole == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", float64(part)/float64(whole)*100)
+	},
+	"md": escapeMarkdown,
+}
+
+// formatDuration formats a duration to the minute, such as 1h5m, or to the
+// second if it's shorter than a minute
+func formatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
+
+// markdownSpecial are the characters escaped in Markdown text
+var markdownSpecial = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`, "\n", " ",
+)
+
+// escapeMarkdown escapes text so Markdown shows it literally, on one line
+func escapeMarkdown(text string) string {
+	return markdownSpecial.Replace(text)
+}
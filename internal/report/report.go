@@ -0,0 +1,223 @@
+// Package report renders a training session as a self-contained progress
+// report with a completion certificate, for managers who need proof that
+// a learner finished the track.
+package report
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Document is everything a report template can show about one session
+type Document struct {
+	Learner     string                `json:"learner"` // Display name
+	UserID      string                `json:"user_id"`
+	SessionID   string                `json:"session_id"`
+	Status      models.SessionStatus  `json:"status"`
+	Started     time.Time             `json:"started"`
+	LastActive  time.Time             `json:"last_active"`
+	Generated   time.Time             `json:"generated"`
+	Level       models.CognitiveLevel `json:"level"`
+	Exercises   []ExerciseResult      `json:"exercises"` // In the order trained
+	Goals       []string              `json:"goals"`     // Learning goals of every completed exercise
+	Completed   int                   `json:"completed"` // Exercises completed
+	Total       int                   `json:"total"`     // Exercises in the session
+	Score       float64               `json:"score"`     // Average score of completed exercises
+	TimeSpent   time.Duration         `json:"time_spent"`
+	HintsUsed   int                   `json:"hints_used"`
+	Certificate Certificate           `json:"certificate"`
+}
+
+// ExerciseResult is a learner's result on one exercise of the session
+type ExerciseResult struct {
+	ExerciseID string        `json:"exercise_id"`
+	Title      string        `json:"title"`
+	Completed  bool          `json:"completed"`
+	Score      float64       `json:"score"` // Scored when the exercise was completed
+	TimeSpent  time.Duration `json:"time_spent"`
+	Estimated  time.Duration `json:"estimated,omitempty"`
+	Attempts   int           `json:"attempts"`
+	HintsUsed  int           `json:"hints_used"`
+	Goals      []string      `json:"goals"` // Achieved if Completed
+}
+
+// Certificate attests that a session was completed. Hash signs the session
+// data the report was made from with the install's key, so only that
+// install can check it with Verify, and editing the session file breaks it.
+type Certificate struct {
+	Awarded bool   `json:"awarded"` // The session was completed
+	Hash    string `json:"hash"`    // HMAC-SHA256 of the session, in hex
+}
+
+// KeySize is the length in bytes of a certificate signing key
+const KeySize = 32
+
+// LoadKey reads the install's certificate signing key from path, creating
+// a random one the first time
+func LoadKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := createKey(path); err != nil {
+			return nil, err
+		}
+		key, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("certificate key %s is damaged: want %d bytes, got %d", path, KeySize, len(key))
+	}
+	return key, nil
+}
+
+// createKey writes a new random key to path, unless another process wrote
+// one first. The key is linked into place complete, so a racing reader
+// never sees it half written.
+func createKey(path string) error {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create certificate key: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".key-*")
+	if err != nil {
+		return fmt.Errorf("failed to create certificate key: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(key)
+	if syncErr := tmp.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Link(tmp.Name(), path)
+	}
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create certificate key: %w", err)
+	}
+	return nil
+}
+
+// Build gathers a report on a session. exercises supplies titles, goals
+// and time estimates; exercises no longer offered are reported by ID. key
+// signs the certificate.
+func Build(session *models.TrainingSession, exercises []models.Exercise, learner string, now time.Time, key []byte) (*Document, error) {
+	hash, err := SessionHash(session, key)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{
+		Learner:     learner,
+		UserID:      session.UserID,
+		SessionID:   session.SessionID,
+		Status:      session.Status,
+		Started:     session.StartTime,
+		LastActive:  session.LastActivity,
+		Generated:   now,
+		Level:       session.Config.CognitiveLoad,
+		Exercises:   []ExerciseResult{},
+		Goals:       []string{},
+		TimeSpent:   session.ActiveTime,
+		Certificate: Certificate{Awarded: session.Status == models.SessionCompleted, Hash: hash},
+	}
+	if doc.Learner == "" {
+		doc.Learner = session.UserID
+	}
+
+	known := make(map[string]models.Exercise, len(exercises))
+	for _, exercise := range exercises {
+		known[exercise.ID] = exercise
+	}
+
+	var timeSpent time.Duration
+	var scoreSum float64
+	for i, progress := range session.Progress {
+		id := progress.ExerciseID
+		if id == "" && i < len(session.Exercises) {
+			id = session.Exercises[i] // Not reached yet
+		}
+		if id == "" {
+			continue
+		}
+		exercise, ok := known[id]
+		result := ExerciseResult{
+			ExerciseID: id,
+			Title:      id,
+			Completed:  progress.CompletedAt != nil,
+			TimeSpent:  progress.TimeSpent,
+			Attempts:   progress.Attempts,
+			HintsUsed:  progress.HintsUsed,
+			Goals:      []string{},
+		}
+		if ok {
+			result.Title = exercise.Title
+			result.Estimated = time.Duration(exercise.EstimatedTime) * time.Minute
+			result.Goals = append(result.Goals, exercise.LearningGoals...)
+		}
+		if result.Completed {
+			result.Score = progress.Score
+			scoreSum += progress.Score
+			doc.Completed++
+			doc.Goals = append(doc.Goals, result.Goals...)
+		}
+		timeSpent += progress.TimeSpent
+		doc.HintsUsed += progress.HintsUsed
+		doc.Exercises = append(doc.Exercises, result)
+	}
+
+	doc.Total = len(doc.Exercises)
+	if doc.Completed > 0 {
+		doc.Score = scoreSum / float64(doc.Completed)
+	}
+	// Sessions saved before active time was recorded only have exercise times
+	if doc.TimeSpent == 0 {
+		doc.TimeSpent = timeSpent
+	}
+	return doc, nil
+}
+
+// SessionHash signs a session's results with key and returns the HMAC-SHA256
+// in hex. LastActivity is left out: storage stamps it on every save, and
+// archiving a session saves it again.
+func SessionHash(session *models.TrainingSession, key []byte) (string, error) {
+	signed := *session
+	signed.LastActivity = time.Time{}
+	data, err := json.Marshal(&signed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal session: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify reports whether hash, as printed on a certificate, was made from
+// this session's data with key
+func Verify(session *models.TrainingSession, hash string, key []byte) (bool, error) {
+	want, err := SessionHash(session, key)
+	if err != nil {
+		return false, err
+	}
+	got, err := hex.DecodeString(strings.ToLower(strings.TrimSpace(hash)))
+	if err != nil {
+		return false, nil
+	}
+	sum, _ := hex.DecodeString(want)
+	return hmac.Equal(sum, got), nil
+}
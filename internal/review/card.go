@@ -0,0 +1,88 @@
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// SM-2 parameters
+const (
+	initialEase  = 2.5
+	minimumEase  = 1.3
+	passingGrade = 3 // Qualities below this reset the card
+)
+
+// Card is the memory state of one challenge for one learner, scheduled with
+// the SM-2 algorithm
+type Card struct {
+	ExerciseID   string    `json:"exercise_id"`
+	Challenge    int       `json:"challenge"` // Index into the exercise's challenges
+	Ease         float64   `json:"ease"`
+	Interval     int       `json:"interval_days"`
+	Repetitions  int       `json:"repetitions"` // Successful reviews in a row
+	Lapses       int       `json:"lapses"`
+	Due          time.Time `json:"due"`
+	LastReviewed time.Time `json:"last_reviewed"`
+}
+
+// Key identifies the challenge a card is for
+func (c *Card) Key() string {
+	return cardKey(c.ExerciseID, c.Challenge)
+}
+
+// cardKey builds the key for a challenge
+func cardKey(exerciseID string, challenge int) string {
+	return fmt.Sprintf("%s#%d", exerciseID, challenge)
+}
+
+// IsDue reports whether the card should be reviewed at now
+func (c *Card) IsDue(now time.Time) bool {
+	return !c.Due.After(now)
+}
+
+// Review updates the card after an answer graded 0 (no recall) to 5 (perfect)
+func (c *Card) Review(quality int, at time.Time) {
+	quality = max(0, min(5, quality))
+	if c.Ease == 0 {
+		c.Ease = initialEase
+	}
+
+	if quality < passingGrade {
+		c.Repetitions = 0
+		c.Interval = 1
+		if !c.LastReviewed.IsZero() {
+			c.Lapses++
+		}
+	} else {
+		c.Repetitions++
+		switch c.Repetitions {
+		case 1:
+			c.Interval = 1
+		case 2:
+			c.Interval = 6
+		default:
+			c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+		}
+	}
+
+	miss := float64(5 - quality)
+	c.Ease = max(minimumEase, c.Ease+0.1-miss*(0.08+miss*0.02))
+	c.LastReviewed = at
+	c.Due = at.AddDate(0, 0, c.Interval)
+}
+
+// Quality grades an answer for SM-2 from how it went
+func Quality(attempts, hintsUsed int, solved bool) int {
+	switch {
+	case !solved && attempts == 0:
+		return 0 // Skipped without trying
+	case !solved:
+		return 1
+	case attempts == 1 && hintsUsed == 0:
+		return 5
+	case attempts <= 2 && hintsUsed == 0:
+		return 4
+	}
+	return 3 // Needed hints or several attempts
+}
@@ -0,0 +1,129 @@
+package review
+
+import (
+	"sort"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Deck holds every card a learner has, plus how far each training session's
+// attempt history has been imported
+type Deck struct {
+	UserID   string               `json:"user_id"`
+	Cards    []*Card              `json:"cards"`
+	Imported map[string]time.Time `json:"imported,omitempty"` // Session ID to the last attempt imported
+}
+
+// NewDeck creates an empty deck for a learner
+func NewDeck(userID string) *Deck {
+	return &Deck{UserID: userID, Imported: make(map[string]time.Time)}
+}
+
+// Card returns the card for a challenge, if the learner has one
+func (d *Deck) Card(exerciseID string, challenge int) (*Card, bool) {
+	key := cardKey(exerciseID, challenge)
+	for _, card := range d.Cards {
+		if card.Key() == key {
+			return card, true
+		}
+	}
+	return nil, false
+}
+
+// Record schedules a challenge after an answer of the given quality,
+// creating its card on first sight
+func (d *Deck) Record(exerciseID string, challenge, quality int, at time.Time) *Card {
+	card, ok := d.Card(exerciseID, challenge)
+	if !ok {
+		card = &Card{ExerciseID: exerciseID, Challenge: challenge}
+		d.Cards = append(d.Cards, card)
+	}
+	card.Review(quality, at)
+	return card
+}
+
+// Due returns the cards due at now, most overdue first
+func (d *Deck) Due(now time.Time) []*Card {
+	var due []*Card
+	for _, card := range d.Cards {
+		if card.IsDue(now) {
+			due = append(due, card)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		if !due[i].Due.Equal(due[j].Due) {
+			return due[i].Due.Before(due[j].Due)
+		}
+		return due[i].Key() < due[j].Key()
+	})
+	return due
+}
+
+// NextDue returns when the next card falls due, if there are any cards
+func (d *Deck) NextDue() (time.Time, bool) {
+	var next time.Time
+	for _, card := range d.Cards {
+		if next.IsZero() || card.Due.Before(next) {
+			next = card.Due
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// Import schedules the challenges a training session attempted since it was
+// last imported. Each challenge counts as one review, graded by the attempt
+// on which it was first passed.
+func (d *Deck) Import(session *models.TrainingSession) int {
+	if d.Imported == nil {
+		d.Imported = make(map[string]time.Time)
+	}
+	since := d.Imported[session.SessionID]
+
+	type result struct {
+		exerciseID string
+		challenge  int
+		attempts   int
+		passedOn   int
+		at         time.Time
+	}
+	var order []string
+	results := map[string]*result{}
+	latest := since
+
+	for _, progress := range session.Progress {
+		for _, record := range progress.AttemptHistory {
+			if !record.SubmittedAt.After(since) {
+				continue
+			}
+			key := cardKey(progress.ExerciseID, record.Challenge)
+			r, ok := results[key]
+			if !ok {
+				r = &result{exerciseID: progress.ExerciseID, challenge: record.Challenge}
+				results[key] = r
+				order = append(order, key)
+			}
+			r.attempts++
+			if record.Passed && r.passedOn == 0 {
+				r.passedOn = r.attempts
+			}
+			if record.SubmittedAt.After(r.at) {
+				r.at = record.SubmittedAt
+			}
+			if record.SubmittedAt.After(latest) {
+				latest = record.SubmittedAt
+			}
+		}
+	}
+
+	for _, key := range order {
+		r := results[key]
+		quality := Quality(r.attempts, 0, false)
+		if r.passedOn > 0 {
+			quality = Quality(r.passedOn, 0, true)
+		}
+		d.Record(r.exerciseID, r.challenge, quality, r.at)
+	}
+	d.Imported[session.SessionID] = latest
+	return len(order)
+}
@@ -0,0 +1,53 @@
+package sandbox
+
+import (
+	"container/list"
+	"crypto/sha256"
+)
+
+// DefaultCacheSize is how many results of each kind a runner keeps when
+// CacheSize isn't set
+const DefaultCacheSize = 1024
+
+// cache keeps the most recently used results by source digest, dropping
+// the least recently used once it holds size of them. Callers hold the
+// runner's mu.
+type cache[V any] struct {
+	entries map[[sha256.Size]byte]*list.Element
+	order   list.List // Most recently used first
+}
+
+// cacheEntry is a result and the digest it's kept under
+type cacheEntry[V any] struct {
+	key   [sha256.Size]byte
+	value V
+}
+
+// get returns the result kept for key, marking it recently used
+func (c *cache[V]) get(key [sha256.Size]byte) (V, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry[V]).value, true
+}
+
+// put keeps a result for key, dropping the oldest beyond size
+func (c *cache[V]) put(key [sha256.Size]byte, value V, size int) {
+	if c.entries == nil {
+		c.entries = make(map[[sha256.Size]byte]*list.Element)
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry[V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value})
+	for c.order.Len() > size {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*cacheEntry[V]).key)
+		c.order.Remove(oldest)
+	}
+}
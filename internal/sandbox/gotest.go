@@ -0,0 +1,310 @@
+package sandbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TestFailure describes one failing test from a hidden test file
+type TestFailure struct {
+	Name     string
+	Messages []string // t.Error and t.Fatal messages, without file:line prefixes
+}
+
+// TestReport is the outcome of running hidden tests against a learner's program
+type TestReport struct {
+	Built       bool     // Whether the program and tests compiled
+	BuildOutput string   // Compiler output when they did not
+	Expected    []string // The hidden tests, which must all run and pass
+	Passed      []string
+	Failures    []TestFailure
+	TimedOut    bool
+	OutOfMemory bool
+	Isolated    bool
+}
+
+// OK reports whether everything compiled and every hidden test ran and
+// passed. A program that exits before its tests run, say from init, has
+// passed none of them.
+func (r *TestReport) OK() bool {
+	if !r.Built || r.TimedOut || r.OutOfMemory || len(r.Failures) > 0 || len(r.Expected) == 0 {
+		return false
+	}
+	passed := make(map[string]bool, len(r.Passed))
+	for _, name := range r.Passed {
+		passed[name] = true
+	}
+	for _, name := range r.Expected {
+		if !passed[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// FailedNames returns the names of the failing tests
+func (r *TestReport) FailedNames() []string {
+	names := make([]string, 0, len(r.Failures))
+	for _, failure := range r.Failures {
+		names = append(names, failure.Name)
+	}
+	return names
+}
+
+// testEvent is a single line of `go test -json` output
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+// Test compiles a program together with a hidden _test.go file and runs the
+// tests under the runner's limits. The test binary's output is converted
+// with `go tool test2json`, so events match what `go test -json` prints.
+//
+// The program shares that output, so it can print events of its own. A
+// passing test only counts if the binary got through every test: then a
+// generated TestMain exits with a status chosen for this build, which the
+// program can't know. A test that fails anywhere in the output has failed.
+func (r *Runner) Test(ctx context.Context, source, testSource string) (*TestReport, error) {
+	key := sha256.Sum256([]byte(source + "\x00" + testSource))
+	r.mu.Lock()
+	if cached, ok := r.testCache.get(key); ok {
+		r.mu.Unlock()
+		return cached, nil
+	}
+	r.mu.Unlock()
+
+	goBin, err := r.goBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{"main.go": source, "main_test.go": testSource}
+	status := 0
+	if !declaresTestMain(testSource) {
+		if status, err = harnessStatus(); err != nil {
+			return nil, err
+		}
+		files["harness_test.go"] = fmt.Sprintf(harnessSource, status)
+	}
+	dir, err := writeModule(files)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	report := &TestReport{Expected: testNames(testSource)}
+	buildCtx, cancel := context.WithTimeout(ctx, r.Limits.BuildTimeout)
+	defer cancel()
+
+	build := exec.CommandContext(buildCtx, goBin, "test", "-c", "-o", "prog.test", ".")
+	build.Dir = dir
+	build.Env = toolchainEnv()
+	if output, err := build.CombinedOutput(); err != nil {
+		if buildCtx.Err() != nil {
+			return nil, fmt.Errorf("test build timed out after %s", r.Limits.BuildTimeout)
+		}
+		report.BuildOutput = cleanBuildOutput(string(output), dir)
+		r.storeTest(key, report)
+		return report, nil
+	}
+	report.Built = true
+
+	run := &Result{}
+	if err := r.execute(ctx, filepath.Join(dir, "prog.test"), dir, run, "-test.v=test2json", "-test.count=1"); err != nil {
+		return nil, err
+	}
+	report.TimedOut = run.TimedOut
+	report.OutOfMemory = run.OutOfMemory
+	report.Isolated = run.Isolated
+
+	convert := exec.CommandContext(ctx, goBin, "tool", "test2json", "-t")
+	convert.Env = toolchainEnv()
+	convert.Stdin = strings.NewReader(run.Stdout)
+	events, err := convert.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert test output: %w", err)
+	}
+
+	if err := parseTestEvents(events, report); err != nil {
+		return nil, err
+	}
+	finished := run.ExitCode == 0
+	if status != 0 {
+		finished = run.ExitCode == status
+	}
+	if !finished && len(report.Failures) == 0 {
+		// Whatever passed was printed by the program, not the tests
+		report.Passed = nil
+		if run.ExitCode != 0 {
+			// The binary died before reporting any test, e.g. a panic in init
+			report.Failures = append(report.Failures, TestFailure{
+				Name:     "(program)",
+				Messages: []string{strings.TrimSpace(run.Stdout + run.Stderr)},
+			})
+		}
+	}
+	report.Failures = append(report.Failures, notRun(report)...)
+
+	r.storeTest(key, report)
+	return report, nil
+}
+
+// harnessSource is a TestMain that exits with the status it is formatted
+// with once every test has passed
+const harnessSource = `package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if code := m.Run(); code != 0 {
+		os.Exit(code)
+	}
+	os.Exit(%d)
+}
+`
+
+// harnessStatus picks the exit status of a passing test binary, clear of
+// the statuses Go and shells use themselves
+func harnessStatus() (int, error) {
+	var b [1]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("failed to pick test harness status: %w", err)
+	}
+	return 64 + int(b[0])%62, nil
+}
+
+// declaresTestMain reports whether a test file has its own TestMain, which
+// leaves no room for the harness
+func declaresTestMain(testSource string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), "main_test.go", testSource, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "TestMain" {
+			return true
+		}
+	}
+	return false
+}
+
+// testNames lists the top-level test functions of a test file
+func testNames(testSource string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "main_test.go", testSource, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if ok && fn.Recv == nil && strings.HasPrefix(fn.Name.Name, "Test") && fn.Type.Params.NumFields() == 1 {
+			names = append(names, fn.Name.Name)
+		}
+	}
+	return names
+}
+
+// notRun reports the hidden tests that neither passed nor failed, because
+// the program stopped the test binary before they finished
+func notRun(report *TestReport) []TestFailure {
+	seen := map[string]bool{}
+	for _, name := range report.Passed {
+		seen[name] = true
+	}
+	for _, failure := range report.Failures {
+		seen[failure.Name] = true
+	}
+	var failures []TestFailure
+	for _, name := range report.Expected {
+		if !seen[name] {
+			failures = append(failures, TestFailure{Name: name, Messages: []string{"didn't run to completion: the program exited before the test finished"}})
+		}
+	}
+	return failures
+}
+
+// fileLinePrefix matches the "main_test.go:12: " prefix of test log lines
+var fileLinePrefix = regexp.MustCompile(`^\s*\w+\.go:\d+: `)
+
+// parseTestEvents collects passing and failing tests from test2json events
+func parseTestEvents(data []byte, report *TestReport) error {
+	messages := map[string][]string{}
+	passed := map[string]bool{}
+	failed := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return fmt.Errorf("failed to parse test event: %w", err)
+		}
+		if event.Test == "" {
+			continue
+		}
+
+		switch event.Action {
+		case "output":
+			line := strings.TrimRight(event.Output, "\n")
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "=== ") || strings.HasPrefix(trimmed, "--- ") {
+				continue
+			}
+			messages[event.Test] = append(messages[event.Test], fileLinePrefix.ReplaceAllString(line, ""))
+		case "pass":
+			passed[event.Test] = true
+		case "fail":
+			failed[event.Test] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read test events: %w", err)
+	}
+
+	// A test reported both ways failed; the pass was printed by the program
+	for name := range passed {
+		if !failed[name] {
+			report.Passed = append(report.Passed, name)
+		}
+	}
+	sort.Strings(report.Passed)
+
+	names := make([]string, 0, len(failed))
+	for name := range failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		report.Failures = append(report.Failures, TestFailure{Name: name, Messages: messages[name]})
+	}
+	return nil
+}
+
+// storeTest caches a test report for later runs of the same sources,
+// except runs killed for time or memory
+func (r *Runner) storeTest(key [sha256.Size]byte, report *TestReport) {
+	if report.TimedOut || report.OutOfMemory {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.testCache.put(key, report, r.cacheSize())
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// isolate runs the program in fresh user and network namespaces, leaving it
+// with only a loopback interface that is down
+func isolate(cmd *exec.Cmd) bool {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER | syscall.CLONE_NEWNET,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+	}
+	return true
+}
+
+// watchMemory polls the program's resident set size and calls kill once it
+// passes limit. The returned function reports whether the limit was hit.
+func watchMemory(ctx context.Context, pid int, limit int64, kill func()) func() bool {
+	var exceeded atomic.Bool
+	if limit <= 0 {
+		return exceeded.Load
+	}
+
+	pageSize := int64(os.Getpagesize())
+	statm := fmt.Sprintf("/proc/%d/statm", pid)
+	go func() {
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				data, err := os.ReadFile(statm)
+				if err != nil {
+					return
+				}
+				var size, resident int64
+				if _, err := fmt.Sscan(string(data), &size, &resident); err != nil {
+					return
+				}
+				if resident*pageSize > limit {
+					exceeded.Store(true)
+					kill()
+					return
+				}
+			}
+		}
+	}()
+	return exceeded.Load
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+)
+
+// isolate is unsupported outside Linux; programs keep host network access
+func isolate(cmd *exec.Cmd) bool {
+	return false
+}
+
+// watchMemory is unsupported outside Linux; GOMEMLIMIT still applies
+func watchMemory(ctx context.Context, pid int, limit int64, kill func()) func() bool {
+	return func() bool { return false }
+}
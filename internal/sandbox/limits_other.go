@@ -0,0 +1,19 @@
+//go:build !unix
+
+package sandbox
+
+import (
+	"context"
+	"os"
+	"os/exec"
+)
+
+// limitedCommand runs the program directly; only the wall clock limit applies
+func limitedCommand(ctx context.Context, limits Limits, program string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, program, args...)
+}
+
+// cpuExceeded is always false where CPU limits are unsupported
+func cpuExceeded(state *os.ProcessState) bool {
+	return false
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// limitedCommand starts the program through sh so ulimit can cap CPU time
+// before exec replaces the shell with the learner's program
+func limitedCommand(ctx context.Context, limits Limits, program string, args ...string) *exec.Cmd {
+	script := `exec "$0" "$@"`
+	if seconds := int(limits.CPUTime.Seconds()); seconds > 0 {
+		script = fmt.Sprintf(`ulimit -t %d && exec "$0" "$@"`, seconds)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", append([]string{"-c", script, program}, args...)...)
+}
+
+// cpuExceeded reports whether the kernel stopped the program for using too much CPU
+func cpuExceeded(state *os.ProcessState) bool {
+	if state == nil {
+		return false
+	}
+	status, ok := state.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGXCPU
+}
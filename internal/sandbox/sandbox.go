@@ -0,0 +1,328 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrToolchainUnavailable is returned when no local go command can be found
+var ErrToolchainUnavailable = errors.New("go toolchain not available")
+
+// ErrIsolationUnavailable is returned by a runner that requires isolation
+// when the host can't cut programs off from the network
+var ErrIsolationUnavailable = errors.New("sandbox isolation unavailable: this host doesn't allow user and network namespaces, so answers can't be run without network access")
+
+// Limits bounds the resources a learner's program may use
+type Limits struct {
+	BuildTimeout time.Duration // Wall clock allowed for go build
+	RunTimeout   time.Duration // Wall clock allowed for the program itself
+	CPUTime      time.Duration // CPU seconds before the program is killed
+	Memory       int64         // Resident memory in bytes before the program is killed
+	OutputBytes  int           // Maximum stdout/stderr kept from the program
+}
+
+// DefaultLimits are generous enough for every built-in challenge
+var DefaultLimits = Limits{
+	BuildTimeout: 30 * time.Second,
+	RunTimeout:   5 * time.Second,
+	CPUTime:      2 * time.Second,
+	Memory:       256 << 20,
+	OutputBytes:  64 << 10,
+}
+
+// Result describes a single build and run of a learner's program
+type Result struct {
+	Built       bool   // Whether go build succeeded
+	BuildOutput string // Compiler output when the build failed
+	Stdout      string
+	Stderr      string
+	ExitCode    int
+	TimedOut    bool // Killed for exceeding the wall clock or CPU limit
+	OutOfMemory bool // Killed for exceeding the memory limit
+	Isolated    bool // Ran without network access
+}
+
+// Runner builds and runs programs in throwaway modules with the local toolchain
+type Runner struct {
+	Limits Limits
+	GoBin  string // Path to the go command; looked up on PATH when empty
+
+	// RequireIsolation refuses to run programs when they can't be cut off
+	// from the network. Otherwise they run anyway, and the first such run
+	// writes a warning to Warnings, or os.Stderr when it's nil.
+	RequireIsolation bool
+	Warnings         io.Writer
+
+	// CacheSize bounds the runs and the test runs kept for answers checked
+	// again, so a long-running server doesn't keep every answer it has
+	// seen; DefaultCacheSize when 0
+	CacheSize int
+
+	mu        sync.Mutex
+	cache     cache[*Result]
+	testCache cache[*TestReport]
+	warned    bool
+}
+
+// NewRunner creates a runner with the given limits
+func NewRunner(limits Limits) *Runner {
+	return &Runner{Limits: limits}
+}
+
+var (
+	defaultRunner     *Runner
+	defaultRunnerOnce sync.Once
+)
+
+// Default returns the shared runner used by challenge validators
+func Default() *Runner {
+	defaultRunnerOnce.Do(func() {
+		defaultRunner = NewRunner(DefaultLimits)
+	})
+	return defaultRunner
+}
+
+// Run builds a single-file main package and runs it, returning what it printed.
+// Results are cached by source so repeated checks of one answer run once.
+func (r *Runner) Run(ctx context.Context, source string) (*Result, error) {
+	key := sha256.Sum256([]byte(source))
+	r.mu.Lock()
+	if cached, ok := r.cache.get(key); ok {
+		r.mu.Unlock()
+		return cached, nil
+	}
+	r.mu.Unlock()
+
+	goBin, err := r.goBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := writeModule(map[string]string{"main.go": source})
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	result := &Result{}
+	buildCtx, cancel := context.WithTimeout(ctx, r.Limits.BuildTimeout)
+	defer cancel()
+
+	build := exec.CommandContext(buildCtx, goBin, "build", "-o", "prog", ".")
+	build.Dir = dir
+	build.Env = toolchainEnv()
+	if output, err := build.CombinedOutput(); err != nil {
+		if buildCtx.Err() != nil {
+			return nil, fmt.Errorf("build timed out after %s", r.Limits.BuildTimeout)
+		}
+		result.BuildOutput = cleanBuildOutput(string(output), dir)
+		r.store(key, result)
+		return result, nil
+	}
+	result.Built = true
+
+	if err := r.execute(ctx, filepath.Join(dir, "prog"), dir, result); err != nil {
+		return nil, err
+	}
+
+	r.store(key, result)
+	return result, nil
+}
+
+// execute runs a built program under the runner's limits
+func (r *Runner) execute(ctx context.Context, program, dir string, result *Result, args ...string) error {
+	runCtx, cancel := context.WithTimeout(ctx, r.Limits.RunTimeout)
+	defer cancel()
+
+	cmd := limitedCommand(runCtx, r.Limits, program, args...)
+	cmd.Dir = dir
+	cmd.Env = programEnv(r.Limits)
+
+	stdout := &cappedBuffer{limit: r.Limits.OutputBytes}
+	stderr := &cappedBuffer{limit: r.Limits.OutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	result.Isolated = isolate(cmd)
+	if !result.Isolated && r.RequireIsolation {
+		return ErrIsolationUnavailable
+	}
+	err := cmd.Start()
+	if err != nil && result.Isolated {
+		// Namespaces can be disabled by the host
+		if r.RequireIsolation {
+			return fmt.Errorf("%w (%v)", ErrIsolationUnavailable, err)
+		}
+		cmd = limitedCommand(runCtx, r.Limits, program, args...)
+		cmd.Dir, cmd.Env, cmd.Stdout, cmd.Stderr = dir, programEnv(r.Limits), stdout, stderr
+		result.Isolated = false
+		err = cmd.Start()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start program: %w", err)
+	}
+	if !result.Isolated {
+		r.warnUnisolated()
+	}
+
+	oom := watchMemory(runCtx, cmd.Process.Pid, r.Limits.Memory, func() { cmd.Process.Kill() })
+	err = cmd.Wait()
+
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.OutOfMemory = oom()
+	result.TimedOut = runCtx.Err() == context.DeadlineExceeded || cpuExceeded(cmd.ProcessState)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case runCtx.Err() != nil:
+		result.ExitCode = -1
+	default:
+		return fmt.Errorf("failed to run program: %w", err)
+	}
+	return nil
+}
+
+// warnUnisolated warns, once per runner, that programs have network access
+func (r *Runner) warnUnisolated() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.warned {
+		return
+	}
+	r.warned = true
+	w := r.Warnings
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintln(w, "⚠️  Warning: answers are running WITH network access, because this host doesn't allow")
+	fmt.Fprintln(w, "   user and network namespaces. Set sandbox.require_isolation in the config file")
+	fmt.Fprintln(w, "   to refuse to run them instead.")
+}
+
+// store caches a result for later runs of the same source. Runs killed for
+// time or memory aren't cached: a busy machine may have caused them.
+func (r *Runner) store(key [sha256.Size]byte, result *Result) {
+	if result.TimedOut || result.OutOfMemory {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache.put(key, result, r.cacheSize())
+}
+
+// cacheSize returns how many results of each kind to keep
+func (r *Runner) cacheSize() int {
+	if r.CacheSize > 0 {
+		return r.CacheSize
+	}
+	return DefaultCacheSize
+}
+
+// goBinary locates the go command
+func (r *Runner) goBinary() (string, error) {
+	if r.GoBin != "" {
+		return r.GoBin, nil
+	}
+	path, err := exec.LookPath("go")
+	if err != nil {
+		return "", ErrToolchainUnavailable
+	}
+	return path, nil
+}
+
+// writeModule creates a temporary module containing the given files
+func writeModule(files map[string]string) (string, error) {
+	dir, err := os.MkdirTemp("", "go-trainer-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+
+	files["go.mod"] = "module sandbox\n\ngo 1.22\n"
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return dir, nil
+}
+
+// toolchainEnv keeps the go command offline and away from any workspace
+func toolchainEnv() []string {
+	env := []string{
+		"GOPROXY=off",
+		"GOFLAGS=-mod=mod",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+		"CGO_ENABLED=0",
+	}
+	for _, key := range []string{"PATH", "HOME", "GOCACHE", "GOPATH", "GOROOT", "TMPDIR", "LOCALAPPDATA", "APPDATA", "USERPROFILE", "SYSTEMROOT"} {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+// programEnv is the minimal environment handed to a learner's program
+func programEnv(limits Limits) []string {
+	env := []string{"PATH=/usr/bin:/bin"}
+	if limits.Memory > 0 {
+		env = append(env, fmt.Sprintf("GOMEMLIMIT=%d", limits.Memory))
+	}
+	return env
+}
+
+// cleanBuildOutput strips temporary paths so errors read like main.go:3:2
+func cleanBuildOutput(output, dir string) string {
+	output = strings.ReplaceAll(output, dir+string(filepath.Separator), "")
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, strings.TrimPrefix(line, "./"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cappedBuffer keeps at most limit bytes and silently drops the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write implements io.Writer
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.Len()+len(p) > b.limit {
+		b.truncated = true
+		if room := b.limit - b.Len(); room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// String returns the captured output, noting when it was cut short
+func (b *cappedBuffer) String() string {
+	if b.truncated {
+		return b.Buffer.String() + "\n... output truncated"
+	}
+	return b.Buffer.String()
+}
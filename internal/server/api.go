@@ -0,0 +1,261 @@
+package server
+
+import (
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Awaiting says what a running session is waiting for
+type Awaiting string
+
+const (
+	AwaitingNothing Awaiting = ""       // Working, or stopped
+	AwaitingReady   Awaiting = "ready"  // The learner to finish studying the examples
+	AwaitingAnswer  Awaiting = "answer" // An answer or command for the current challenge
+)
+
+// ExerciseInfo describes an exercise in the curriculum
+type ExerciseInfo struct {
+	ID               string   `json:"id"`
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Level            string   `json:"level"`
+	Type             string   `json:"type"`
+	Prerequisites    []string `json:"prerequisites"`
+	LearningGoals    []string `json:"learning_goals"`
+	EstimatedMinutes int      `json:"estimated_minutes"`
+	Challenges       int      `json:"challenges"`
+}
+
+// ExerciseDetail is an exercise with its worked examples and challenges,
+// but not their solutions
+type ExerciseDetail struct {
+	ExerciseInfo
+	Examples   []ExampleView   `json:"examples"`
+	Challenges []ChallengeView `json:"challenge_list"`
+}
+
+// ExampleView is a worked example
+type ExampleView struct {
+	Title       string `json:"title"`
+	Code        string `json:"code"`
+	Explanation string `json:"explanation"`
+	Output      string `json:"output,omitempty"`
+}
+
+// ExerciseView is the exercise a session is working on
+type ExerciseView struct {
+	ID            string        `json:"id"`
+	Title         string        `json:"title"`
+	Description   string        `json:"description"`
+	LearningGoals []string      `json:"learning_goals"`
+	Examples      []ExampleView `json:"examples"` // Including support examples shown so far
+}
+
+// ChallengeView is the challenge a session is working on
+type ChallengeView struct {
+	Number      int      `json:"number"` // From 1
+	Total       int      `json:"total"`
+	Description string   `json:"description"`
+	Template    string   `json:"template"`
+	Concepts    []string `json:"concepts,omitempty"`
+	Hints       []string `json:"hints,omitempty"` // Hints shown so far
+	HintsLeft   int      `json:"hints_left"`
+}
+
+// State is a running session as the learner sees it
+type State struct {
+	SessionID string               `json:"session_id"`
+	UserID    string               `json:"user_id"`
+	Status    models.SessionStatus `json:"status"`
+	Running   bool                 `json:"running"`
+	Awaiting  Awaiting             `json:"awaiting,omitempty"`
+	Completed int                  `json:"completed"` // Exercises completed
+	Total     int                  `json:"total"`
+	Exercise  *ExerciseView        `json:"exercise,omitempty"`
+	Challenge *ChallengeView       `json:"challenge,omitempty"`
+	Messages  []Message            `json:"messages"` // Feedback since the last input
+}
+
+// Message is one piece of feedback from the trainer. Text is what the
+// terminal trainer would print; the other fields carry the same details in
+// structured form.
+type Message struct {
+	Type        string       `json:"type"`
+	Text        string       `json:"text"`
+	Reason      string       `json:"reason,omitempty"` // Why a solution was shown, or an adaptive decision made
+	Code        string       `json:"code,omitempty"`   // A solution or example
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+	Tests       []TestResult `json:"tests,omitempty"` // Failing hidden tests
+	Diff        string       `json:"diff,omitempty"`  // Expected against actual output
+	Attempts    int          `json:"attempts,omitempty"`
+	Score       float64      `json:"score,omitempty"`
+	Exercises   []string     `json:"exercises,omitempty"` // IDs of exercises unlocked or completed
+	Remaining   float64      `json:"remaining,omitempty"` // Seconds left before a time limit
+}
+
+// Message types
+const (
+	MessageWelcome           = "welcome"
+	MessageResumed           = "resumed"
+	MessageHelp              = "help"
+	MessageHint              = "hint"
+	MessageHintsOff          = "hints_off"
+	MessageSolution          = "solution"
+	MessageCorrect           = "correct"
+	MessageCompileErrors     = "compile_errors"
+	MessageTestFailures      = "test_failures"
+	MessageOutputMismatch    = "output_mismatch"
+	MessageRunError          = "run_error"
+	MessageRetry             = "retry"
+	MessageExtraExample      = "extra_example"
+	MessageAdapted           = "adapted"
+	MessagePractice          = "practice"
+	MessageTimeWarning       = "time_warning"
+	MessageTimeUp            = "time_up"
+	MessageInterrupted       = "interrupted"
+	MessageSessionSaved      = "session_saved"
+	MessageError             = "error"
+	MessageExerciseCompleted = "exercise_completed"
+	MessageUnlocked          = "unlocked"
+	MessageResults           = "results"
+)
+
+// Diagnostic is a compiler error in the learner's answer
+type Diagnostic struct {
+	Line    int    `json:"line"` // In the answer; 0 when the error is in the template
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// TestResult is a hidden test an answer failed
+type TestResult struct {
+	Name     string   `json:"name"`
+	Messages []string `json:"messages"`
+}
+
+// SessionInfo summarizes a saved session
+type SessionInfo struct {
+	SessionID    string               `json:"session_id"`
+	UserID       string               `json:"user_id"`
+	Status       models.SessionStatus `json:"status"`
+	Running      bool                 `json:"running"` // Training on this server now
+	StartTime    time.Time            `json:"start_time"`
+	LastActivity time.Time            `json:"last_activity"`
+	Completed    int                  `json:"completed"`
+	Total        int                  `json:"total"`
+	Exercises    []string             `json:"exercises,omitempty"`
+}
+
+// CreateRequest starts a session: on the exercises named, on the
+// curriculum from one exercise on, or on the whole curriculum
+type CreateRequest struct {
+	Exercises []string `json:"exercises,omitempty"`
+	From      string   `json:"from,omitempty"`
+}
+
+// AnswerRequest submits an answer to the current challenge
+type AnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+// CommandRequest sends a command: "ready" once the examples are studied,
+// or "hint", "skip", "help" or "quit" during a challenge
+type CommandRequest struct {
+	Command string `json:"command"`
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// exerciseInfo describes an exercise for listing
+func exerciseInfo(exercise models.Exercise) ExerciseInfo {
+	return ExerciseInfo{
+		ID:               exercise.ID,
+		Title:            exercise.Title,
+		Description:      exercise.Description,
+		Level:            exercise.CognitiveLevel.String(),
+		Type:             exercise.ExerciseType.String(),
+		Prerequisites:    nonNil(exercise.Prerequisites),
+		LearningGoals:    nonNil(exercise.LearningGoals),
+		EstimatedMinutes: exercise.EstimatedTime,
+		Challenges:       len(exercise.Challenges),
+	}
+}
+
+// exerciseDetail describes an exercise with its examples and challenges
+func exerciseDetail(exercise models.Exercise) ExerciseDetail {
+	detail := ExerciseDetail{ExerciseInfo: exerciseInfo(exercise), Examples: exampleViews(exercise.Examples), Challenges: []ChallengeView{}}
+	for i, challenge := range exercise.Challenges {
+		detail.Challenges = append(detail.Challenges, challengeView(challenge, i+1, len(exercise.Challenges)))
+	}
+	return detail
+}
+
+// exerciseView shows the exercise a session is starting
+func exerciseView(exercise models.Exercise) *ExerciseView {
+	return &ExerciseView{
+		ID:            exercise.ID,
+		Title:         exercise.Title,
+		Description:   exercise.Description,
+		LearningGoals: nonNil(exercise.LearningGoals),
+		Examples:      []ExampleView{},
+	}
+}
+
+// exampleViews converts worked examples
+func exampleViews(examples []models.Example) []ExampleView {
+	views := make([]ExampleView, len(examples))
+	for i, example := range examples {
+		views[i] = exampleView(example)
+	}
+	return views
+}
+
+// exampleView converts a worked example
+func exampleView(example models.Example) ExampleView {
+	return ExampleView{Title: example.Title, Code: example.Code, Explanation: example.Explanation, Output: example.Output}
+}
+
+// challengeView shows a challenge without its solution or hints
+func challengeView(challenge models.Challenge, number, total int) ChallengeView {
+	return ChallengeView{
+		Number:      number,
+		Total:       total,
+		Description: challenge.Description,
+		Template:    challenge.Template,
+		Concepts:    challenge.Concepts,
+		HintsLeft:   len(challenge.Hints),
+	}
+}
+
+// sessionInfo summarizes a saved session
+func sessionInfo(session *models.TrainingSession, running bool) SessionInfo {
+	info := SessionInfo{
+		SessionID:    session.SessionID,
+		UserID:       session.UserID,
+		Status:       session.Status,
+		Running:      running,
+		StartTime:    session.StartTime,
+		LastActivity: session.LastActivity,
+		Total:        len(session.Progress),
+		Exercises:    session.Exercises,
+	}
+	for _, progress := range session.Progress {
+		if progress.CompletedAt != nil {
+			info.Completed++
+		}
+	}
+	return info
+}
+
+// nonNil returns an empty slice for nil, so JSON shows [] rather than null
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
@@ -0,0 +1,513 @@
+// Package server runs the trainer as a shared service: a JSON API over
+// HTTP that starts, resumes and pauses sessions for many learners at once
+// and relays their answers to the same engine the terminal trainer uses.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// PassphraseHeader carries the passphrase of a protected profile
+const PassphraseHeader = "X-Trainer-Passphrase"
+
+// Learner is someone training through the server
+type Learner struct {
+	ID       string
+	Config   models.TrainerConfig // Settings new sessions train with
+	Sessions storage.SessionStorage
+
+	// Attach, if set, is called with each trainer before it starts, to add
+	// listeners. The function it returns, if any, is called once training
+	// stops.
+	Attach func(t *trainer.CLTTrainer) (finished func())
+}
+
+// Learners finds the learner a request is for. It fails with a
+// storage.ProfileNotFoundError for unknown learners and
+// profile.ErrWrongPassphrase when a protected profile's passphrase is
+// wrong. It's called concurrently, and should return the same session
+// storage for every request of a learner.
+type Learners interface {
+	Learner(userID, passphrase string) (*Learner, error)
+}
+
+// Server serves the trainer over HTTP. Every session in training runs on
+// its own goroutine; requests for different sessions proceed in parallel,
+// while input to one session is handled in order.
+type Server struct {
+	registry *exercises.Registry
+	learners Learners
+
+	mu   sync.Mutex
+	runs map[string]*run // Sessions training now, by ID
+}
+
+// New creates a server for the exercises in registry
+func New(registry *exercises.Registry, learners Learners) *Server {
+	return &Server{registry: registry, learners: learners, runs: make(map[string]*run)}
+}
+
+// Handler returns the server's API:
+//
+//	GET  /api/exercises                               List the curriculum
+//	GET  /api/exercises/{id}                          An exercise's goals, examples and challenges
+//	GET  /api/users/{user}/sessions                   List a learner's sessions
+//	POST /api/users/{user}/sessions                   Start a session
+//	GET  /api/users/{user}/sessions/{id}              Summarize a session
+//	GET  /api/users/{user}/sessions/{id}/current      The running session's exercise or challenge
+//	POST /api/users/{user}/sessions/{id}/answer       Submit an answer
+//	POST /api/users/{user}/sessions/{id}/commands     Send ready, hint, skip, help or quit
+//	POST /api/users/{user}/sessions/{id}/pause        Pause and save a running session
+//	POST /api/users/{user}/sessions/{id}/resume       Resume a paused or interrupted session
+//
+// POST requests must be sent as application/json and, if they carry an
+// Origin, come from the server's own, so other sites can't drive it.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/exercises", s.listExercises)
+	mux.HandleFunc("GET /api/exercises/{id}", s.getExercise)
+	mux.HandleFunc("GET /api/users/{user}/sessions", s.listSessions)
+	mux.HandleFunc("POST /api/users/{user}/sessions", s.createSession)
+	mux.HandleFunc("GET /api/users/{user}/sessions/{id}", s.getSession)
+	mux.HandleFunc("GET /api/users/{user}/sessions/{id}/current", s.current)
+	mux.HandleFunc("POST /api/users/{user}/sessions/{id}/answer", s.answer)
+	mux.HandleFunc("POST /api/users/{user}/sessions/{id}/commands", s.command)
+	mux.HandleFunc("POST /api/users/{user}/sessions/{id}/pause", s.pause)
+	mux.HandleFunc("POST /api/users/{user}/sessions/{id}/resume", s.resume)
+	return sameSite(mux)
+}
+
+// sameSite refuses requests that change state unless a page of another
+// site couldn't have sent them: a cross-site form can't send JSON, and
+// fetch can't without a preflight this server never answers
+func sameSite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+				writeError(w, statusError(http.StatusUnsupportedMediaType, "requests must be sent as application/json"))
+				return
+			}
+			if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+				writeError(w, statusError(http.StatusForbidden, "requests from %s aren't allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether an Origin header names the host a request was
+// sent to
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
+
+// Shutdown pauses and saves every session in training, waiting for them to
+// stop until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	runs := make([]*run, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r)
+	}
+	s.mu.Unlock()
+
+	for _, r := range runs {
+		r.trainer.Interrupt()
+	}
+	for _, r := range runs {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// httpError is an error with the status code to respond with
+type httpError struct {
+	status int
+	err    error
+}
+
+// Error implements error
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// statusError creates an error responded to with status
+func statusError(status int, format string, args ...any) error {
+	return &httpError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// statusCode picks the status to respond to an error with
+func statusCode(err error) int {
+	var httpErr *httpError
+	var notAwaiting *notAwaitingError
+	var noProfile *storage.ProfileNotFoundError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.status
+	case errors.As(err, &noProfile), errors.Is(err, storage.ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, profile.ErrWrongPassphrase):
+		return http.StatusForbidden
+	case errors.As(err, &notAwaiting), errors.Is(err, errStopped), errors.Is(err, storage.ErrSessionInUse):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// writeJSON responds with v as JSON
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responds with an error as JSON
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusCode(err), errorResponse{Error: err.Error()})
+}
+
+// decode reads a JSON request body into v, rejecting unknown fields. An
+// empty body leaves v as it is.
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return statusError(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+// learner finds the learner named in a request's path
+func (s *Server) learner(r *http.Request) (*Learner, error) {
+	return s.learners.Learner(r.PathValue("user"), r.Header.Get(PassphraseHeader))
+}
+
+// running returns a learner's session if it's training on this server
+func (s *Server) running(learner *Learner, sessionID string) (*run, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.runs[sessionID]
+	if !ok || r.userID != learner.ID {
+		return nil, false
+	}
+	return r, true
+}
+
+// listExercises lists the curriculum in learning order
+func (s *Server) listExercises(w http.ResponseWriter, r *http.Request) {
+	list := []ExerciseInfo{}
+	for _, exercise := range s.registry.GetAll() {
+		list = append(list, exerciseInfo(exercise))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// getExercise describes one exercise
+func (s *Server) getExercise(w http.ResponseWriter, r *http.Request) {
+	exercise, ok := s.registry.GetByID(r.PathValue("id"))
+	if !ok {
+		writeError(w, statusError(http.StatusNotFound, "no exercise %q", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, exerciseDetail(exercise))
+}
+
+// listSessions lists a learner's saved sessions, newest first
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	learner, err := s.learner(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sessions, err := learner.Sessions.ListSessions(learner.ID)
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to list sessions: %w", err))
+		return
+	}
+	sort.SliceStable(sessions, func(i, j int) bool { return sessions[i].StartTime.After(sessions[j].StartTime) })
+
+	list := []SessionInfo{}
+	for _, session := range sessions {
+		_, running := s.running(learner, session.SessionID)
+		list = append(list, sessionInfo(session, running))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// createSession starts a session and responds with its first exercise
+func (s *Server) createSession(w http.ResponseWriter, r *http.Request) {
+	learner, err := s.learner(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var request CreateRequest
+	if err := decode(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	list, err := s.sessionExercises(request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	t := trainer.NewCLTTrainer(list, learner.Config, learner.ID, learner.Sessions)
+	run, err := s.start(learner, t, len(list), 0, true)
+	if err != nil {
+		t.Release()
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, run.firstTurn())
+}
+
+// sessionExercises picks the exercises a new session trains on
+func (s *Server) sessionExercises(request CreateRequest) ([]models.Exercise, error) {
+	all := s.registry.GetAll()
+	switch {
+	case len(request.Exercises) > 0 && request.From != "":
+		return nil, statusError(http.StatusBadRequest, "give exercises or from, not both")
+	case len(request.Exercises) > 0:
+		var list []models.Exercise
+		for _, id := range request.Exercises {
+			exercise, ok := s.registry.GetByID(id)
+			if !ok {
+				return nil, statusError(http.StatusNotFound, "no exercise %q", id)
+			}
+			list = append(list, exercise)
+		}
+		return list, nil
+	case request.From != "":
+		for i, exercise := range all {
+			if exercise.ID == request.From {
+				return all[i:], nil
+			}
+		}
+		return nil, statusError(http.StatusNotFound, "no exercise %q", request.From)
+	}
+	return all, nil
+}
+
+// start registers a session and starts training it. New sessions are
+// given an ID no other session of the learner's has.
+func (s *Server) start(learner *Learner, t *trainer.CLTTrainer, total, completed int, isNew bool) (*run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := t.SessionID()
+	if isNew {
+		base := id
+		for n := 2; s.taken(learner, id); n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		t.SetSessionID(id)
+	} else if _, ok := s.runs[id]; ok {
+		return nil, statusError(http.StatusConflict, "session '%s' is already training", id)
+	}
+
+	var finished func()
+	if learner.Attach != nil {
+		finished = learner.Attach(t)
+	}
+	var r *run
+	r = startRun(t, learner.ID, total, completed, func() {
+		if finished != nil {
+			finished()
+		}
+		s.mu.Lock()
+		if s.runs[id] == r {
+			delete(s.runs, id)
+		}
+		s.mu.Unlock()
+	})
+	s.runs[id] = r
+	return r, nil
+}
+
+// taken reports whether a session ID is in use, with mu held
+func (s *Server) taken(learner *Learner, id string) bool {
+	if _, ok := s.runs[id]; ok {
+		return true
+	}
+	_, err := learner.Sessions.LoadSession(id)
+	return !errors.Is(err, storage.ErrSessionNotFound)
+}
+
+// getSession summarizes one of a learner's sessions
+func (s *Server) getSession(w http.ResponseWriter, r *http.Request) {
+	learner, err := s.learner(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	session, err := s.loadSession(learner, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	_, running := s.running(learner, session.SessionID)
+	writeJSON(w, http.StatusOK, sessionInfo(session, running))
+}
+
+// loadSession loads one of a learner's saved sessions
+func (s *Server) loadSession(learner *Learner, sessionID string) (*models.TrainingSession, error) {
+	session, err := learner.Sessions.LoadSession(sessionID)
+	if errors.Is(err, storage.ErrSessionNotFound) || err == nil && session.UserID != learner.ID {
+		return nil, statusError(http.StatusNotFound, "no session '%s'", sessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session: %w", err)
+	}
+	return session, nil
+}
+
+// runFor finds the running session a request is for
+func (s *Server) runFor(r *http.Request) (*run, error) {
+	learner, err := s.learner(r)
+	if err != nil {
+		return nil, err
+	}
+	run, ok := s.running(learner, r.PathValue("id"))
+	if !ok {
+		return nil, statusError(http.StatusConflict, "session '%s' isn't training; resume it first", r.PathValue("id"))
+	}
+	return run, nil
+}
+
+// current shows the exercise or challenge a running session is on
+func (s *Server) current(w http.ResponseWriter, r *http.Request) {
+	run, err := s.runFor(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run.State())
+}
+
+// answer submits an answer to the current challenge
+func (s *Server) answer(w http.ResponseWriter, r *http.Request) {
+	var request AnswerRequest
+	if err := decode(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	answer := strings.TrimSpace(request.Answer)
+	if answer == "" {
+		writeError(w, statusError(http.StatusBadRequest, "the answer is empty"))
+		return
+	}
+	s.send(w, r, answer, AwaitingAnswer)
+}
+
+// command sends a command to a running session
+func (s *Server) command(w http.ResponseWriter, r *http.Request) {
+	var request CommandRequest
+	if err := decode(w, r, &request); err != nil {
+		writeError(w, err)
+		return
+	}
+	switch command := strings.ToLower(request.Command); command {
+	case "ready":
+		s.send(w, r, "", AwaitingReady)
+	case "hint", "skip", "help", "quit":
+		s.send(w, r, command, AwaitingAnswer)
+	default:
+		writeError(w, statusError(http.StatusBadRequest, "unknown command %q (want ready, hint, skip, help or quit)", request.Command))
+	}
+}
+
+// send gives a running session input and responds with its feedback
+func (s *Server) send(w http.ResponseWriter, r *http.Request, input string, want Awaiting) {
+	run, err := s.runFor(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	state, err := run.Send(input, want)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// pause pauses and saves a running session
+func (s *Server) pause(w http.ResponseWriter, r *http.Request) {
+	run, err := s.runFor(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run.Pause())
+}
+
+// resume continues a paused session, or one left active by a crash, and
+// responds with where it picks up
+func (s *Server) resume(w http.ResponseWriter, r *http.Request) {
+	learner, err := s.learner(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if run, ok := s.running(learner, r.PathValue("id")); ok {
+		writeJSON(w, http.StatusOK, run.State())
+		return
+	}
+	session, err := s.loadSession(learner, r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	resume := trainer.ResumeSession
+	switch session.Status {
+	case models.SessionPaused:
+	case models.SessionActive:
+		resume = trainer.RecoverSession
+	default:
+		writeError(w, statusError(http.StatusConflict, "session '%s' is %s and can't be resumed", session.SessionID, session.Status))
+		return
+	}
+	t, err := resume(session.SessionID, s.registry.GetAll(), learner.Sessions)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	info := sessionInfo(session, true)
+	run, err := s.start(learner, t, info.Total, info.Completed, false)
+	if err != nil {
+		// The resumed trainer holds the session's lock until released
+		t.Release()
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run.firstTurn())
+}
@@ -0,0 +1,478 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/review"
+	"github.com/cmyers78/claude/internal/trainer"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+// errStopped is returned for input to a session that has stopped training
+var errStopped = errors.New("the session has stopped; resume it to continue")
+
+// notAwaitingError is returned for input the session isn't waiting for
+type notAwaitingError struct {
+	awaiting Awaiting
+}
+
+// Error implements error
+func (e *notAwaitingError) Error() string {
+	switch e.awaiting {
+	case AwaitingReady:
+		return "the session is showing examples; send the ready command to start the challenges"
+	case AwaitingAnswer:
+		return "the session is waiting for an answer or command for the current challenge"
+	}
+	return "the session is busy; try again shortly"
+}
+
+// run is a session training on the server. The trainer runs on its own
+// goroutine, exactly as it does in a terminal; run is its front-end,
+// turning what it presents into messages and feeding it input from
+// requests one at a time. Each input's response holds everything the
+// trainer presented until it next prompted for input or stopped.
+type run struct {
+	trainer   *trainer.CLTTrainer
+	userID    string
+	sessionID string
+	total     int
+
+	inputs chan string
+	done   chan struct{} // Closed once training has stopped
+	send   sync.Mutex    // Held while an input is answered
+
+	mu        sync.Mutex
+	changed   *sync.Cond // Broadcast when the trainer prompts or stops
+	prompts   int        // Times the trainer has prompted for input
+	awaiting  Awaiting
+	stopped   bool
+	status    models.SessionStatus
+	completed int
+	exercise  *ExerciseView
+	challenge *ChallengeView
+	messages  []Message
+
+	// The terminal front-end, rendering into text, supplies each
+	// message's wording
+	terminal *trainer.Terminal
+	text     bytes.Buffer
+}
+
+// startRun starts training on its own goroutine. completed is the number
+// of exercises already completed in a resumed session. finished is called
+// once training stops, before the run reports it has.
+func startRun(t *trainer.CLTTrainer, userID string, total, completed int, finished func()) *run {
+	r := &run{
+		trainer:   t,
+		userID:    userID,
+		sessionID: t.SessionID(),
+		total:     total,
+		completed: completed,
+		status:    models.SessionActive,
+		inputs:    make(chan string),
+		done:      make(chan struct{}),
+	}
+	r.changed = sync.NewCond(&r.mu)
+	r.terminal = trainer.NewTerminal(strings.NewReader(""), &r.text)
+	t.SetIO(r, r)
+	t.AddListener(r)
+	// The server interrupts every session itself when it's asked to stop
+	t.IgnoreSignals()
+
+	go func() {
+		defer close(r.done)
+		defer func() {
+			r.mu.Lock()
+			r.stopped = true
+			r.awaiting = AwaitingNothing
+			r.changed.Broadcast()
+			r.mu.Unlock()
+		}()
+		defer finished()
+		defer r.recoverPanic()
+		t.Start()
+	}()
+	return r
+}
+
+// recoverPanic stops a session whose training panicked without taking the
+// server down. The trainer has already tried to save it as paused.
+func (r *run) recoverPanic() {
+	if p := recover(); p != nil {
+		r.update(func() { r.status = models.SessionPaused })
+		r.Error(fmt.Errorf("training stopped unexpectedly: %v; the session was saved as paused", p))
+	}
+}
+
+// firstTurn waits for the trainer's first prompt and returns what it
+// presented up to it
+func (r *run) firstTurn() *State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waitForPrompt(0)
+	return r.stateLocked()
+}
+
+// Send gives the trainer one line of input, which must be what it's
+// waiting for, and returns what it presented in response
+func (r *run) Send(input string, want Awaiting) (*State, error) {
+	r.send.Lock()
+	defer r.send.Unlock()
+
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return nil, errStopped
+	}
+	if r.awaiting != want {
+		r.mu.Unlock()
+		return nil, &notAwaitingError{awaiting: r.awaiting}
+	}
+	prompts := r.prompts
+	r.awaiting = AwaitingNothing
+	r.messages = nil
+	r.mu.Unlock()
+
+	select {
+	case r.inputs <- input:
+	case <-r.done:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.waitForPrompt(prompts)
+	return r.stateLocked(), nil
+}
+
+// Pause interrupts training, which saves the session as paused, and
+// returns the final state once it has stopped
+func (r *run) Pause() *State {
+	r.mu.Lock()
+	if !r.stopped {
+		r.messages = nil
+	}
+	r.mu.Unlock()
+
+	r.trainer.Interrupt()
+	<-r.done
+	return r.State()
+}
+
+// State returns the session as the learner sees it now
+func (r *run) State() *State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stateLocked()
+}
+
+// waitForPrompt waits, with mu held, until the trainer has prompted more
+// than prompts times or stopped
+func (r *run) waitForPrompt(prompts int) {
+	for r.prompts <= prompts && !r.stopped {
+		r.changed.Wait()
+	}
+}
+
+// stateLocked copies the session's state, with mu held
+func (r *run) stateLocked() *State {
+	state := &State{
+		SessionID: r.sessionID,
+		UserID:    r.userID,
+		Status:    r.status,
+		Running:   !r.stopped,
+		Awaiting:  r.awaiting,
+		Completed: r.completed,
+		Total:     r.total,
+		Messages:  append([]Message{}, r.messages...),
+	}
+	if r.exercise != nil {
+		exercise := *r.exercise
+		exercise.Examples = append([]ExampleView{}, exercise.Examples...)
+		state.Exercise = &exercise
+	}
+	if r.challenge != nil {
+		challenge := *r.challenge
+		challenge.Hints = append([]string(nil), challenge.Hints...)
+		state.Challenge = &challenge
+	}
+	return state
+}
+
+// prompt records that the trainer is about to wait for input
+func (r *run) prompt(awaiting Awaiting) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prompts++
+	r.awaiting = awaiting
+	r.changed.Broadcast()
+}
+
+// read waits for the next input, reporting eof once training has stopped
+func (r *run) read() (string, bool) {
+	select {
+	case input := <-r.inputs:
+		return input, false
+	case <-r.done:
+		return "", true
+	}
+}
+
+// say adds a message worded the way the terminal renders it
+func (r *run) say(message Message, render func(term *trainer.Terminal)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.text.Reset()
+	render(r.terminal)
+	message.Text = strings.TrimSpace(r.text.String())
+	r.messages = append(r.messages, message)
+}
+
+// update changes the session's state
+func (r *run) update(change func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	change()
+}
+
+// ReadLine implements trainer.Input
+func (r *run) ReadLine() (string, bool) { return r.read() }
+
+// ReadAnswer implements trainer.Input
+func (r *run) ReadAnswer() (string, bool) { return r.read() }
+
+// ReadMultiline implements trainer.Input; answers already arrive whole
+func (r *run) ReadMultiline() (string, bool) { return r.read() }
+
+// Edit implements trainer.Input. There's no editor on the server; the
+// answer itself can be a whole program.
+func (r *run) Edit(template string) (string, error) {
+	return "", errors.New("there's no editor here; submit the whole program as your answer")
+}
+
+// Event implements trainer.Listener, tracking the session's status
+func (r *run) Event(event trainer.Event) {
+	r.update(func() {
+		switch event.Type {
+		case trainer.ExerciseCompleted:
+			r.completed++
+		case trainer.SessionPaused:
+			r.status = models.SessionPaused
+		case trainer.SessionCompleted:
+			r.status = models.SessionCompleted
+		case trainer.SessionAbandoned:
+			r.status = models.SessionAbandoned
+		}
+	})
+}
+
+// Welcome implements trainer.Presenter
+func (r *run) Welcome() {
+	r.say(Message{Type: MessageWelcome}, func(term *trainer.Terminal) { term.Welcome() })
+}
+
+// Resumed implements trainer.Presenter
+func (r *run) Resumed(pausedAt time.Time, position, total int, exercise models.Exercise, challenge int) {
+	r.say(Message{Type: MessageResumed}, func(term *trainer.Terminal) { term.Resumed(pausedAt, position, total, exercise, challenge) })
+}
+
+// LearningGoals implements trainer.Presenter, starting a new exercise
+func (r *run) LearningGoals(exercise models.Exercise) {
+	r.update(func() {
+		r.exercise = exerciseView(exercise)
+		r.challenge = nil
+	})
+}
+
+// Examples implements trainer.Presenter
+func (r *run) Examples(exercise models.Exercise) {
+	r.update(func() { r.exercise.Examples = append(r.exercise.Examples, exampleViews(exercise.Examples)...) })
+}
+
+// ReadyPrompt implements trainer.Presenter
+func (r *run) ReadyPrompt() { r.prompt(AwaitingReady) }
+
+// ChallengesHeader implements trainer.Presenter
+func (r *run) ChallengesHeader() {}
+
+// Challenge implements trainer.Presenter
+func (r *run) Challenge(challenge models.Challenge, number, total int) {
+	view := challengeView(challenge, number, total)
+	r.update(func() { r.challenge = &view })
+}
+
+// AnswerPrompt implements trainer.Presenter
+func (r *run) AnswerPrompt() { r.prompt(AwaitingAnswer) }
+
+// MultilineInstructions implements trainer.Presenter
+func (r *run) MultilineInstructions() { r.prompt(AwaitingAnswer) }
+
+// Submitting implements trainer.Presenter
+func (r *run) Submitting(code string) {}
+
+// EditUnchanged implements trainer.Presenter
+func (r *run) EditUnchanged() {}
+
+// Help implements trainer.Presenter
+func (r *run) Help() {
+	r.say(Message{Type: MessageHelp}, func(term *trainer.Terminal) { term.Help() })
+}
+
+// Hint implements trainer.Presenter
+func (r *run) Hint(hint string) {
+	r.update(func() {
+		if r.challenge != nil {
+			r.challenge.Hints = append(r.challenge.Hints, hint)
+			r.challenge.HintsLeft = max(0, r.challenge.HintsLeft-1)
+		}
+	})
+	r.say(Message{Type: MessageHint}, func(term *trainer.Terminal) { term.Hint(hint) })
+}
+
+// HintsOff implements trainer.Presenter
+func (r *run) HintsOff() {
+	r.say(Message{Type: MessageHintsOff}, func(term *trainer.Terminal) { term.HintsOff() })
+}
+
+// solutionReasons name the reasons a solution is shown
+var solutionReasons = map[trainer.SolutionReason]string{
+	trainer.HintsExhausted: "hints_exhausted",
+	trainer.Skipped:        "skipped",
+	trainer.OutOfAttempts:  "out_of_attempts",
+	trainer.OutOfTime:      "out_of_time",
+}
+
+// Solution implements trainer.Presenter
+func (r *run) Solution(reason trainer.SolutionReason, solution string) {
+	r.say(Message{Type: MessageSolution, Reason: solutionReasons[reason], Code: solution}, func(term *trainer.Terminal) { term.Solution(reason, solution) })
+}
+
+// Correct implements trainer.Presenter
+func (r *run) Correct(attempts, hintsUsed int) {
+	r.say(Message{Type: MessageCorrect, Attempts: attempts}, func(term *trainer.Terminal) { term.Correct(attempts, hintsUsed) })
+}
+
+// CompileErrors implements trainer.Presenter
+func (r *run) CompileErrors(diagnostics []validation.Diagnostic) {
+	message := Message{Type: MessageCompileErrors}
+	for _, diagnostic := range diagnostics {
+		message.Diagnostics = append(message.Diagnostics, Diagnostic{Line: diagnostic.Line, Column: diagnostic.Column, Message: diagnostic.Message})
+	}
+	r.say(message, func(term *trainer.Terminal) { term.CompileErrors(diagnostics) })
+}
+
+// TestFailures implements trainer.Presenter
+func (r *run) TestFailures(failures *validation.TestFailures) {
+	message := Message{Type: MessageTestFailures}
+	for _, test := range failures.Report.Failures {
+		message.Tests = append(message.Tests, TestResult{Name: test.Name, Messages: nonNil(test.Messages)})
+	}
+	r.say(message, func(term *trainer.Terminal) { term.TestFailures(failures) })
+}
+
+// OutputMismatch implements trainer.Presenter
+func (r *run) OutputMismatch(diff string) {
+	r.say(Message{Type: MessageOutputMismatch, Diff: diff}, func(term *trainer.Terminal) { term.OutputMismatch(diff) })
+}
+
+// RunError implements trainer.Presenter
+func (r *run) RunError(err error) {
+	r.say(Message{Type: MessageRunError}, func(term *trainer.Terminal) { term.RunError(err) })
+}
+
+// Retry implements trainer.Presenter
+func (r *run) Retry(attempts int, hints bool) {
+	r.say(Message{Type: MessageRetry, Attempts: attempts}, func(term *trainer.Terminal) { term.Retry(attempts, hints) })
+}
+
+// ExtraExample implements trainer.Presenter
+func (r *run) ExtraExample(example models.Example) {
+	r.update(func() { r.exercise.Examples = append(r.exercise.Examples, exampleView(example)) })
+	r.say(Message{Type: MessageExtraExample, Code: example.Code}, func(term *trainer.Terminal) { term.ExtraExample(example) })
+}
+
+// Adapted implements trainer.Presenter
+func (r *run) Adapted(decision models.AdaptiveDecision) {
+	r.say(Message{Type: MessageAdapted, Reason: string(decision.Action)}, func(term *trainer.Terminal) { term.Adapted(decision) })
+}
+
+// Practice implements trainer.Presenter
+func (r *run) Practice(concepts []string) {
+	r.say(Message{Type: MessagePractice}, func(term *trainer.Terminal) { term.Practice(concepts) })
+}
+
+// TimeRemaining implements trainer.Presenter; the limits are shown in
+// warnings instead of at every prompt
+func (r *run) TimeRemaining(session, exercise time.Duration) {}
+
+// TimeWarning implements trainer.Presenter
+func (r *run) TimeWarning(scope trainer.TimeScope, remaining time.Duration) {
+	r.say(Message{Type: MessageTimeWarning, Reason: scope.String(), Remaining: remaining.Seconds()}, func(term *trainer.Terminal) { term.TimeWarning(scope, remaining) })
+}
+
+// TimeUp implements trainer.Presenter
+func (r *run) TimeUp(scope trainer.TimeScope) {
+	r.say(Message{Type: MessageTimeUp, Reason: scope.String()}, func(term *trainer.Terminal) { term.TimeUp(scope) })
+}
+
+// Interrupted implements trainer.Presenter
+func (r *run) Interrupted() {
+	r.say(Message{Type: MessageInterrupted}, func(term *trainer.Terminal) { term.Interrupted() })
+}
+
+// SessionSaved implements trainer.Presenter
+func (r *run) SessionSaved() {
+	r.say(Message{Type: MessageSessionSaved}, func(term *trainer.Terminal) { term.SessionSaved() })
+}
+
+// Error implements trainer.Presenter
+func (r *run) Error(err error) {
+	r.say(Message{Type: MessageError}, func(term *trainer.Terminal) { term.Error(err) })
+}
+
+// ExerciseCompleted implements trainer.Presenter
+func (r *run) ExerciseCompleted(exercise models.Exercise, progress models.LearningProgress) {
+	r.update(func() { r.challenge = nil })
+	message := Message{Type: MessageExerciseCompleted, Score: progress.Score, Attempts: progress.Attempts, Exercises: []string{exercise.ID}}
+	r.say(message, func(term *trainer.Terminal) { term.ExerciseCompleted(exercise, progress) })
+}
+
+// Unlocked implements trainer.Presenter
+func (r *run) Unlocked(exercises []models.Exercise) {
+	message := Message{Type: MessageUnlocked}
+	for _, exercise := range exercises {
+		message.Exercises = append(message.Exercises, exercise.ID)
+	}
+	r.say(message, func(term *trainer.Terminal) { term.Unlocked(exercises) })
+}
+
+// Results implements trainer.Presenter
+func (r *run) Results(summary trainer.Summary) {
+	message := Message{Type: MessageResults, Score: summary.AverageScore(), Attempts: summary.Attempts}
+	for _, result := range summary.Completed {
+		message.Exercises = append(message.Exercises, result.Exercise.ID)
+	}
+	r.update(func() {
+		r.exercise = nil
+		r.challenge = nil
+	})
+	r.say(message, func(term *trainer.Terminal) { term.Results(summary) })
+}
+
+// ReviewStart implements trainer.Presenter; reviews don't run on the server
+func (r *run) ReviewStart(due int, next time.Time) {}
+
+// ReviewCard implements trainer.Presenter
+func (r *run) ReviewCard(exercise models.Exercise, number, total int) {}
+
+// Reviewed implements trainer.Presenter
+func (r *run) Reviewed(card review.Card) {}
+
+// ReviewDone implements trainer.Presenter
+func (r *run) ReviewDone(reviewed, remaining int) {}
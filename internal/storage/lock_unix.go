@@ -0,0 +1,42 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// acquireLock takes a non-blocking exclusive flock on the lock file at path.
+// The kernel releases it if the process dies, so crashes leave no stale locks.
+func acquireLock(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrSessionInUse
+		}
+		return nil, err
+	}
+
+	// The lock file is kept: removing it would let another process lock a
+	// fresh file while this one still holds the old one
+	return func() error {
+		defer file.Close()
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
+
+// syncDir flushes a directory so a rename within it survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
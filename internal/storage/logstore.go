@@ -0,0 +1,630 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// Log file layout: a header of logMagic and a generation number, then
+// records of a big-endian payload length, the payload's CRC-32 and the
+// JSON-encoded logRecord payload
+const (
+	logMagic        = "TRNRLOG1"
+	logHeaderSize   = int64(len(logMagic) + 8)
+	recordHeaderLen = 8
+	maxRecordSize   = 64 << 20
+
+	logFileName   = "sessions.log"
+	indexFileName = "sessions.idx"
+
+	// indexInterval is how many writes may go by before the index is
+	// rewritten; writes after the last index are replayed on open
+	indexInterval = 256
+)
+
+// errTornRecord marks a record cut short or damaged by a crash mid-append
+var errTornRecord = errors.New("incomplete or damaged log record")
+
+// logRecord is one write to the log: a session in the current schema, or
+// a tombstone for a deleted one. The indexed fields are copied out of the
+// session so the index can be rebuilt without decoding sessions.
+type logRecord struct {
+	SessionID string               `json:"session_id"`
+	Deleted   bool                 `json:"deleted,omitempty"`
+	UserID    string               `json:"user_id,omitempty"`
+	Status    models.SessionStatus `json:"status,omitempty"`
+	StartTime time.Time            `json:"start_time,omitempty"`
+	Session   json.RawMessage      `json:"session,omitempty"`
+}
+
+// indexEntry locates the latest record of a session in the log
+type indexEntry struct {
+	SessionID string               `json:"session_id"`
+	UserID    string               `json:"user_id"`
+	Status    models.SessionStatus `json:"status"`
+	StartTime time.Time            `json:"start_time"`
+	Offset    int64                `json:"offset"`
+	Size      int64                `json:"size"` // Record length including its header
+}
+
+// indexFile is the on-disk index, valid for the first LogSize bytes of the
+// log generation it names
+type indexFile struct {
+	Generation uint64        `json:"generation"`
+	LogSize    int64         `json:"log_size"`
+	Sessions   []*indexEntry `json:"sessions"`
+}
+
+// LogSessionStorage implements SessionStorage as an append-only log of
+// session writes with an index by session, user and status, so lookups
+// read only the sessions they return. Compact drops superseded records.
+//
+// Several processes may share a store: each picks up the others' appends
+// before it reads. Compact should run while no other process is writing,
+// since an append racing the rewrite can be lost.
+type LogSessionStorage struct {
+	mu         sync.Mutex
+	dir        string
+	file       *os.File
+	generation uint64
+	size       int64 // Bytes of the log reflected in the index
+	unindexed  int   // Writes since the index file was last written
+
+	sessions map[string]*indexEntry
+	byUser   map[string]map[string]*indexEntry
+	byStatus map[models.SessionStatus]map[string]*indexEntry
+}
+
+// OpenLogSessionStorage opens the log store in dir, creating it if needed.
+// A missing or stale index is rebuilt from the log, and a record left
+// incomplete by a crash is discarded.
+func OpenLogSessionStorage(dir string) (*LogSessionStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create base path: %w", err)
+	}
+	ls := &LogSessionStorage{dir: dir}
+	if err := ls.open(true); err != nil {
+		return nil, err
+	}
+	return ls, nil
+}
+
+// Close writes the index and closes the log
+func (ls *LogSessionStorage) Close() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if ls.file == nil {
+		return nil
+	}
+	err := ls.writeIndex()
+	if closeErr := ls.file.Close(); err == nil {
+		err = closeErr
+	}
+	ls.file = nil
+	return err
+}
+
+// SaveSession appends a session to the log
+func (ls *LogSessionStorage) SaveSession(session *models.TrainingSession) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return err
+	}
+
+	session.LastActivity = time.Now()
+
+	data, err := json.Marshal(sessionFile{SchemaVersion: SchemaVersion, Session: session})
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	return ls.append(&logRecord{
+		SessionID: session.SessionID,
+		UserID:    session.UserID,
+		Status:    session.Status,
+		StartTime: session.StartTime,
+		Session:   data,
+	})
+}
+
+// LoadSession reads the latest copy of a session from the log
+func (ls *LogSessionStorage) LoadSession(sessionID string) (*models.TrainingSession, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return nil, err
+	}
+	entry, ok := ls.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	return ls.read(entry)
+}
+
+// ListSessions returns all sessions for a given user, oldest first
+func (ls *LogSessionStorage) ListSessions(userID string) ([]*models.TrainingSession, error) {
+	return ls.QuerySessions(SessionQuery{UserID: userID})
+}
+
+// QuerySessions returns the sessions matching query, oldest first. Only the
+// matching sessions are read from the log.
+func (ls *LogSessionStorage) QuerySessions(query SessionQuery) ([]*models.TrainingSession, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return nil, err
+	}
+
+	// Start from the narrowest index the query allows
+	candidates := ls.sessions
+	if query.UserID != "" {
+		candidates = ls.byUser[query.UserID]
+	}
+	if byStatus := ls.byStatus[query.Status]; query.Status != "" && len(byStatus) < len(candidates) {
+		candidates = byStatus
+	}
+
+	var sessions []*models.TrainingSession
+	for _, entry := range candidates {
+		if !query.matches(entry.UserID, entry.Status, entry.StartTime) {
+			continue
+		}
+		session, err := ls.read(entry)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	sortByStart(sessions)
+	return sessions, nil
+}
+
+// DeleteSession appends a tombstone for a session. Its lock file is left in
+// place, as another process may still hold a lock on it.
+func (ls *LogSessionStorage) DeleteSession(sessionID string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return err
+	}
+	if _, ok := ls.sessions[sessionID]; ok {
+		if err := ls.append(&logRecord{SessionID: sessionID, Deleted: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LockSession reserves a session for this process until unlock is called
+// or the process exits
+func (ls *LogSessionStorage) LockSession(sessionID string) (func() error, error) {
+	if err := os.MkdirAll(filepath.Join(ls.dir, "locks"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	unlock, err := acquireLock(ls.lockPath(sessionID))
+	if errors.Is(err, ErrSessionInUse) {
+		return nil, fmt.Errorf("session %s is open in another trainer process: %w", sessionID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session: %w", err)
+	}
+	return unlock, nil
+}
+
+// LogStats describes how much of the log is live
+type LogStats struct {
+	Sessions  int
+	LogBytes  int64
+	LiveBytes int64 // Bytes Compact would keep
+}
+
+// Stats reports the size of the log and how much of it is live
+func (ls *LogSessionStorage) Stats() (LogStats, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return LogStats{}, err
+	}
+	stats := LogStats{Sessions: len(ls.sessions), LogBytes: ls.size, LiveBytes: logHeaderSize}
+	for _, entry := range ls.sessions {
+		stats.LiveBytes += entry.Size
+	}
+	return stats, nil
+}
+
+// Compact rewrites the log with only the latest record of each session,
+// dropping superseded writes and tombstones
+func (ls *LogSessionStorage) Compact() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if err := ls.refresh(); err != nil {
+		return err
+	}
+
+	path := ls.logPath()
+	tmp, err := os.CreateTemp(ls.dir, "."+logFileName+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create compacted log: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly once renamed
+
+	generation := ls.generation + 1
+	writer := bufio.NewWriter(tmp)
+	writer.Write(logHeader(generation))
+	offset := logHeaderSize
+	moved := make(map[string]int64, len(ls.sessions))
+	for id, entry := range ls.sessions {
+		record := make([]byte, entry.Size)
+		if _, err := ls.file.ReadAt(record, entry.Offset); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to read session %s: %w", id, err)
+		}
+		writer.Write(record)
+		moved[id] = offset
+		offset += entry.Size
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write compacted log: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write compacted log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write compacted log: %w", err)
+	}
+
+	// Some platforms can't rename over an open file
+	ls.file.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		if openErr := ls.open(false); openErr != nil {
+			return errors.Join(fmt.Errorf("failed to replace log: %w", err), openErr)
+		}
+		return fmt.Errorf("failed to replace log: %w", err)
+	}
+	if ls.file, err = os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0644); err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	if err := syncDir(ls.dir); err != nil {
+		return fmt.Errorf("failed to replace log: %w", err)
+	}
+	for id, offset := range moved {
+		ls.sessions[id].Offset = offset
+	}
+	ls.generation = generation
+	ls.size = offset
+	return ls.writeIndex()
+}
+
+// open opens the log file and loads its index, replaying any writes the
+// index doesn't cover. With repair set, a torn record at the end of the
+// log is truncated; otherwise it may be another process's append in
+// flight, so it is left for a later refresh.
+func (ls *LogSessionStorage) open(repair bool) error {
+	file, err := os.OpenFile(ls.logPath(), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+	generation, err := readLogHeader(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if ls.file != nil {
+		ls.file.Close()
+	}
+	ls.file = file
+	ls.generation = generation
+	ls.reset()
+
+	index, err := ls.readIndex()
+	if err != nil {
+		log.Printf("Warning: Rebuilding session index: %v", err)
+	} else if index != nil {
+		for _, entry := range index.Sessions {
+			ls.put(entry)
+		}
+		ls.size = index.LogSize
+	}
+
+	replayed, err := ls.replay()
+	if errors.Is(err, errTornRecord) && repair {
+		log.Printf("Warning: Discarding incomplete session log record at offset %d", ls.size)
+		if err := ls.file.Truncate(ls.size); err != nil {
+			return fmt.Errorf("failed to repair log: %w", err)
+		}
+	} else if err != nil && !errors.Is(err, errTornRecord) {
+		return err
+	}
+	if replayed > 0 && repair {
+		return ls.writeIndex()
+	}
+	return nil
+}
+
+// refresh picks up writes made by other processes: records appended to the
+// log since it was last read, or a log replaced by compaction
+func (ls *LogSessionStorage) refresh() error {
+	if ls.file == nil {
+		return errors.New("log session storage is closed")
+	}
+	current, err := os.Stat(ls.logPath())
+	if err != nil {
+		return fmt.Errorf("failed to stat log: %w", err)
+	}
+	opened, err := ls.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log: %w", err)
+	}
+	if !os.SameFile(current, opened) {
+		return ls.open(false)
+	}
+	if current.Size() > ls.size {
+		if _, err := ls.replay(); err != nil && !errors.Is(err, errTornRecord) {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay indexes the records after ls.size, stopping at the end of the log
+// or at a torn record, and returns how many it read
+func (ls *LogSessionStorage) replay() (int, error) {
+	if _, err := ls.file.Seek(ls.size, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to read log: %w", err)
+	}
+	reader := bufio.NewReader(ls.file)
+	count := 0
+	for {
+		record, size, err := readRecord(reader)
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		ls.apply(record, ls.size, size)
+		ls.size += size
+		ls.unindexed++
+		count++
+	}
+}
+
+// append writes a record to the end of the log and indexes it
+func (ls *LogSessionStorage) append(record *logRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log record: %w", err)
+	}
+	data := make([]byte, recordHeaderLen, recordHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(data[4:8], crc32.ChecksumIEEE(payload))
+	data = append(data, payload...)
+
+	if _, err := ls.file.Write(data); err != nil {
+		return fmt.Errorf("failed to append to log: %w", err)
+	}
+	if err := ls.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync log: %w", err)
+	}
+
+	// With O_APPEND the write lands at the end of the file, after any
+	// records other processes appended since the last refresh
+	end, err := ls.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to read log position: %w", err)
+	}
+	offset := end - int64(len(data))
+	if offset > ls.size {
+		if _, err := ls.replay(); err != nil {
+			return err
+		}
+	} else {
+		ls.apply(record, offset, int64(len(data)))
+		ls.size = end
+		ls.unindexed++
+	}
+
+	if ls.unindexed >= indexInterval {
+		return ls.writeIndex()
+	}
+	return nil
+}
+
+// apply updates the in-memory index with a record read at offset
+func (ls *LogSessionStorage) apply(record *logRecord, offset, size int64) {
+	if old, ok := ls.sessions[record.SessionID]; ok {
+		delete(ls.sessions, old.SessionID)
+		delete(ls.byUser[old.UserID], old.SessionID)
+		delete(ls.byStatus[old.Status], old.SessionID)
+	}
+	if record.Deleted {
+		return
+	}
+	ls.put(&indexEntry{
+		SessionID: record.SessionID,
+		UserID:    record.UserID,
+		Status:    record.Status,
+		StartTime: record.StartTime,
+		Offset:    offset,
+		Size:      size,
+	})
+}
+
+// put adds an entry to every index
+func (ls *LogSessionStorage) put(entry *indexEntry) {
+	ls.sessions[entry.SessionID] = entry
+	if ls.byUser[entry.UserID] == nil {
+		ls.byUser[entry.UserID] = make(map[string]*indexEntry)
+	}
+	ls.byUser[entry.UserID][entry.SessionID] = entry
+	if ls.byStatus[entry.Status] == nil {
+		ls.byStatus[entry.Status] = make(map[string]*indexEntry)
+	}
+	ls.byStatus[entry.Status][entry.SessionID] = entry
+}
+
+// reset empties the in-memory index
+func (ls *LogSessionStorage) reset() {
+	ls.size = logHeaderSize
+	ls.unindexed = 0
+	ls.sessions = make(map[string]*indexEntry)
+	ls.byUser = make(map[string]map[string]*indexEntry)
+	ls.byStatus = make(map[models.SessionStatus]map[string]*indexEntry)
+}
+
+// read decodes the session stored in an indexed record
+func (ls *LogSessionStorage) read(entry *indexEntry) (*models.TrainingSession, error) {
+	data := make([]byte, entry.Size)
+	if _, err := ls.file.ReadAt(data, entry.Offset); err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", entry.SessionID, err)
+	}
+	record, _, err := readRecord(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", entry.SessionID, err)
+	}
+	session, _, err := decodeSession(record.Session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+	return session, nil
+}
+
+// readIndex loads the index file if it matches the open log, returning
+// nil if there is none yet
+func (ls *LogSessionStorage) readIndex() (*indexFile, error) {
+	data, err := os.ReadFile(ls.indexPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index indexFile
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("corrupt index file: %w", err)
+	}
+
+	info, err := ls.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case index.Generation != ls.generation:
+		return nil, fmt.Errorf("index is for log generation %d, not %d", index.Generation, ls.generation)
+	case index.LogSize < logHeaderSize || index.LogSize > info.Size():
+		return nil, fmt.Errorf("index covers %d bytes of a %d byte log", index.LogSize, info.Size())
+	}
+	for _, entry := range index.Sessions {
+		if entry.Offset < logHeaderSize || entry.Offset+entry.Size > index.LogSize {
+			return nil, fmt.Errorf("index entry for %s is outside the log", entry.SessionID)
+		}
+	}
+	return &index, nil
+}
+
+// writeIndex saves the in-memory index atomically
+func (ls *LogSessionStorage) writeIndex() error {
+	index := indexFile{Generation: ls.generation, LogSize: ls.size}
+	for _, entry := range ls.sessions {
+		index.Sessions = append(index.Sessions, entry)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+	if err := writeFileAtomic(ls.indexPath(), data, ""); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	ls.unindexed = 0
+	return nil
+}
+
+// logHeader builds the header of a log generation
+func logHeader(generation uint64) []byte {
+	header := make([]byte, logHeaderSize)
+	copy(header, logMagic)
+	binary.BigEndian.PutUint64(header[len(logMagic):], generation)
+	return header
+}
+
+// readLogHeader returns the generation of a log file, writing the header
+// of generation 1 if the file is empty
+func readLogHeader(file *os.File) (uint64, error) {
+	header := make([]byte, logHeaderSize)
+	n, err := file.ReadAt(header, 0)
+	if n == 0 && err == io.EOF {
+		if _, err := file.Write(logHeader(1)); err != nil {
+			return 0, fmt.Errorf("failed to write log header: %w", err)
+		}
+		return 1, file.Sync()
+	}
+	if err != nil || string(header[:len(logMagic)]) != logMagic {
+		return 0, fmt.Errorf("%s is not a session log", file.Name())
+	}
+	return binary.BigEndian.Uint64(header[len(logMagic):]), nil
+}
+
+// readRecord reads the next record, returning io.EOF at a clean end of log
+// and errTornRecord for a partial or damaged one
+func readRecord(reader io.Reader) (*logRecord, int64, error) {
+	var header [recordHeaderLen]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, 0, io.EOF
+		}
+		return nil, 0, errTornRecord
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return nil, 0, errTornRecord
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, errTornRecord
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errTornRecord
+	}
+	var record logRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, 0, errTornRecord
+	}
+	return &record, int64(recordHeaderLen) + int64(length), nil
+}
+
+// logPath returns the log file
+func (ls *LogSessionStorage) logPath() string {
+	return filepath.Join(ls.dir, logFileName)
+}
+
+// indexPath returns the index file
+func (ls *LogSessionStorage) indexPath() string {
+	return filepath.Join(ls.dir, indexFileName)
+}
+
+// lockPath returns the lock file guarding a session
+func (ls *LogSessionStorage) lockPath(sessionID string) string {
+	return filepath.Join(ls.dir, "locks", sessionID+".lock")
+}
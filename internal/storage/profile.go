@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/profile"
+)
+
+// ProfileNotFoundError reports a user with no profile
+type ProfileNotFoundError struct {
+	UserID string
+}
+
+// Error implements error
+func (e *ProfileNotFoundError) Error() string {
+	return fmt.Sprintf("no profile for user %q", e.UserID)
+}
+
+// ProfileStorage handles persistence of learner profiles and which one is
+// selected on this machine
+type ProfileStorage interface {
+	SaveProfile(p *profile.Profile) error
+	LoadProfile(userID string) (*profile.Profile, error)
+	ListProfiles() ([]*profile.Profile, error)
+	CurrentUser() (string, error)
+	SetCurrentUser(userID string) error
+}
+
+// FileProfileStorage implements ProfileStorage with a directory per user
+// holding profile.json and that user's sessions and review schedule, so
+// learners never see each other's data
+type FileProfileStorage struct {
+	basePath string
+}
+
+// NewFileProfileStorage creates a new file-based profile storage
+func NewFileProfileStorage(basePath string) *FileProfileStorage {
+	return &FileProfileStorage{
+		basePath: basePath,
+	}
+}
+
+// SaveProfile writes a profile to its user directory
+func (ps *FileProfileStorage) SaveProfile(p *profile.Profile) error {
+	if err := profile.ValidateID(p.ID); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ps.UserDir(p.ID), 0700); err != nil {
+		return fmt.Errorf("failed to create user directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	if err := writeFileAtomic(ps.profilePath(p.ID), data, ""); err != nil {
+		return fmt.Errorf("failed to write profile: %w", err)
+	}
+	return nil
+}
+
+// LoadProfile reads a user's profile, failing with ProfileNotFoundError if
+// they have none
+func (ps *FileProfileStorage) LoadProfile(userID string) (*profile.Profile, error) {
+	if err := profile.ValidateID(userID); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(ps.profilePath(userID))
+	if os.IsNotExist(err) {
+		return nil, &ProfileNotFoundError{UserID: userID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	var p profile.Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal profile: %w", err)
+	}
+	return &p, nil
+}
+
+// ListProfiles returns every profile, ordered by user ID
+func (ps *FileProfileStorage) ListProfiles() ([]*profile.Profile, error) {
+	entries, err := os.ReadDir(ps.basePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users directory: %w", err)
+	}
+
+	var profiles []*profile.Profile
+	for _, entry := range entries {
+		if !entry.IsDir() || profile.ValidateID(entry.Name()) != nil {
+			continue
+		}
+		p, err := ps.LoadProfile(entry.Name())
+		var notFound *ProfileNotFoundError
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].ID < profiles[j].ID })
+	return profiles, nil
+}
+
+// CurrentUser returns the user selected with SetCurrentUser, or
+// profile.DefaultID if none has been
+func (ps *FileProfileStorage) CurrentUser() (string, error) {
+	data, err := os.ReadFile(ps.currentPath())
+	if os.IsNotExist(err) {
+		return profile.DefaultID, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read current user: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// SetCurrentUser selects the user later commands run as
+func (ps *FileProfileStorage) SetCurrentUser(userID string) error {
+	if _, err := ps.LoadProfile(userID); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(ps.currentPath(), []byte(userID+"\n"), ""); err != nil {
+		return fmt.Errorf("failed to write current user: %w", err)
+	}
+	return nil
+}
+
+// UserDir returns the directory holding everything stored for a user
+func (ps *FileProfileStorage) UserDir(userID string) string {
+	return filepath.Join(ps.basePath, userID)
+}
+
+// SessionDir returns the directory holding a user's sessions
+func (ps *FileProfileStorage) SessionDir(userID string) string {
+	return filepath.Join(ps.UserDir(userID), "sessions")
+}
+
+// ReviewDir returns the directory holding a user's review schedule
+func (ps *FileProfileStorage) ReviewDir(userID string) string {
+	return filepath.Join(ps.UserDir(userID), "reviews")
+}
+
+// ArchiveDir returns the directory holding a user's archived sessions
+func (ps *FileProfileStorage) ArchiveDir(userID string) string {
+	return filepath.Join(ps.UserDir(userID), "archive")
+}
+
+// AdoptLegacyData moves sessions and reviews saved before profiles existed,
+// when everyone trained as profile.DefaultID, into the default user's
+// directory. It does nothing once the default profile exists.
+func (ps *FileProfileStorage) AdoptLegacyData(sessionsDir, reviewsDir string) (bool, error) {
+	if _, err := os.Stat(ps.profilePath(profile.DefaultID)); err == nil {
+		return false, nil
+	}
+	sessions, sessionsErr := os.Stat(sessionsDir)
+	deck := filepath.Join(reviewsDir, profile.DefaultID+".json")
+	_, deckErr := os.Stat(deck)
+	if (sessionsErr != nil || !sessions.IsDir()) && deckErr != nil {
+		return false, nil
+	}
+
+	p, err := profile.New(profile.DefaultID, "")
+	if err != nil {
+		return false, err
+	}
+	if err := os.MkdirAll(ps.UserDir(p.ID), 0700); err != nil {
+		return false, fmt.Errorf("failed to create user directory: %w", err)
+	}
+	if sessionsErr == nil && sessions.IsDir() {
+		if err := os.Rename(sessionsDir, ps.SessionDir(p.ID)); err != nil {
+			return false, fmt.Errorf("failed to move sessions: %w", err)
+		}
+	}
+	if deckErr == nil {
+		if err := os.MkdirAll(ps.ReviewDir(p.ID), 0700); err != nil {
+			return false, fmt.Errorf("failed to create review directory: %w", err)
+		}
+		if err := os.Rename(deck, filepath.Join(ps.ReviewDir(p.ID), p.ID+".json")); err != nil {
+			return false, fmt.Errorf("failed to move review schedule: %w", err)
+		}
+	}
+	return true, ps.SaveProfile(p)
+}
+
+// profilePath returns the file holding a user's profile
+func (ps *FileProfileStorage) profilePath(userID string) string {
+	return filepath.Join(ps.UserDir(userID), "profile.json")
+}
+
+// currentPath returns the file naming the selected user
+func (ps *FileProfileStorage) currentPath() string {
+	return filepath.Join(ps.basePath, ".current")
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// SessionQuery selects sessions by owner, status and start time. Zero
+// fields match every session.
+type SessionQuery struct {
+	UserID string
+	Status models.SessionStatus
+	From   time.Time // Started at or after
+	To     time.Time // Started before
+}
+
+// Querier is implemented by storage that can filter sessions itself
+type Querier interface {
+	// QuerySessions returns the sessions matching query, oldest first
+	QuerySessions(query SessionQuery) ([]*models.TrainingSession, error)
+}
+
+// matches reports whether a session with these fields is selected
+func (q SessionQuery) matches(userID string, status models.SessionStatus, start time.Time) bool {
+	switch {
+	case q.UserID != "" && userID != q.UserID:
+		return false
+	case q.Status != "" && status != q.Status:
+		return false
+	case !q.From.IsZero() && start.Before(q.From):
+		return false
+	case !q.To.IsZero() && !start.Before(q.To):
+		return false
+	}
+	return true
+}
+
+// QuerySessions returns the sessions matching query, oldest first. Every
+// session file is read; use LogSessionStorage for large histories.
+func (fs *FileSessionStorage) QuerySessions(query SessionQuery) ([]*models.TrainingSession, error) {
+	if err := fs.ensureBasePath(); err != nil {
+		return nil, fmt.Errorf("failed to ensure base path: %w", err)
+	}
+	ids, err := fs.sessionIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*models.TrainingSession
+	for _, sessionID := range ids {
+		session, err := fs.LoadSession(sessionID)
+		if err != nil {
+			log.Printf("Warning: Skipping corrupted session file %s.json: %v", sessionID, err)
+			continue
+		}
+		if query.matches(session.UserID, session.Status, session.StartTime) {
+			sessions = append(sessions, session)
+		}
+	}
+	sortByStart(sessions)
+	return sessions, nil
+}
+
+// QuerySessions returns the sessions in any storage matching query, oldest
+// first, letting storage that implements Querier filter them itself
+func QuerySessions(sessions SessionStorage, query SessionQuery) ([]*models.TrainingSession, error) {
+	if querier, ok := sessions.(Querier); ok {
+		return querier.QuerySessions(query)
+	}
+	if query.UserID == "" {
+		return nil, fmt.Errorf("storage can't list sessions without a user")
+	}
+
+	all, err := sessions.ListSessions(query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*models.TrainingSession
+	for _, session := range all {
+		if query.matches(session.UserID, session.Status, session.StartTime) {
+			matched = append(matched, session)
+		}
+	}
+	sortByStart(matched)
+	return matched, nil
+}
+
+// sortByStart orders sessions oldest first
+func sortByStart(sessions []*models.TrainingSession) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// RetentionPolicy decides how long completed sessions are kept, measured
+// from when they started. Zero durations keep sessions forever.
+type RetentionPolicy struct {
+	ArchiveAfter time.Duration // Move completed sessions this old to the archive
+	DeleteAfter  time.Duration // Delete completed sessions this old, archived or not
+}
+
+// RetentionResult lists the sessions a policy archived and deleted
+type RetentionResult struct {
+	Archived []string
+	Deleted  []string
+}
+
+// Apply archives and deletes a user's completed sessions that the policy
+// no longer keeps. Sessions another process holds are left for next time.
+func (p RetentionPolicy) Apply(userID string, sessions, archive SessionStorage, now time.Time) (RetentionResult, error) {
+	var result RetentionResult
+	if p.ArchiveAfter <= 0 && p.DeleteAfter <= 0 {
+		return result, nil
+	}
+
+	// Sessions old enough to delete are deleted from the archive too
+	older := func(age time.Duration) SessionQuery {
+		return SessionQuery{UserID: userID, Status: models.SessionCompleted, To: now.Add(-age)}
+	}
+	var failures []error
+	if p.DeleteAfter > 0 {
+		for _, store := range []SessionStorage{sessions, archive} {
+			expired, err := QuerySessions(store, older(p.DeleteAfter))
+			if err != nil {
+				return result, fmt.Errorf("failed to list expired sessions: %w", err)
+			}
+			for _, session := range expired {
+				err := RemoveSession(store, session.SessionID)
+				if err == nil {
+					result.Deleted = append(result.Deleted, session.SessionID)
+				} else if !errors.Is(err, ErrSessionInUse) {
+					failures = append(failures, err)
+				}
+			}
+		}
+	}
+
+	if p.ArchiveAfter > 0 {
+		stale, err := QuerySessions(sessions, older(p.ArchiveAfter))
+		if err != nil {
+			return result, fmt.Errorf("failed to list sessions to archive: %w", err)
+		}
+		for _, session := range stale {
+			err := MoveSession(session.SessionID, sessions, archive)
+			if err == nil {
+				result.Archived = append(result.Archived, session.SessionID)
+			} else if !errors.Is(err, ErrSessionInUse) {
+				failures = append(failures, err)
+			}
+		}
+	}
+	return result, errors.Join(failures...)
+}
+
+// RemoveSession deletes a session, failing with ErrSessionInUse rather than
+// deleting it from under another process
+func RemoveSession(sessions SessionStorage, sessionID string) error {
+	if locker, ok := sessions.(Locker); ok {
+		unlock, err := locker.LockSession(sessionID)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	if err := sessions.DeleteSession(sessionID); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// MoveSession moves a session from one storage to another, failing with
+// ErrSessionInUse if another process holds it
+func MoveSession(sessionID string, from, to SessionStorage) error {
+	if locker, ok := from.(Locker); ok {
+		unlock, err := locker.LockSession(sessionID)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	session, err := from.LoadSession(sessionID)
+	if err != nil {
+		return err
+	}
+	if err := to.SaveSession(session); err != nil {
+		return fmt.Errorf("failed to move session %s: %w", sessionID, err)
+	}
+	if err := from.DeleteSession(sessionID); err != nil {
+		return fmt.Errorf("failed to remove moved session %s: %w", sessionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cmyers78/claude/internal/review"
+)
+
+// ReviewStorage handles persistence of spaced-repetition decks
+type ReviewStorage interface {
+	LoadDeck(userID string) (*review.Deck, error)
+	SaveDeck(deck *review.Deck) error
+}
+
+// FileReviewStorage implements ReviewStorage with one JSON file per user
+type FileReviewStorage struct {
+	basePath string
+}
+
+// NewFileReviewStorage creates a new file-based review storage
+func NewFileReviewStorage(basePath string) *FileReviewStorage {
+	return &FileReviewStorage{
+		basePath: basePath,
+	}
+}
+
+// LoadDeck loads a user's deck, or returns an empty one if they have none yet
+func (fs *FileReviewStorage) LoadDeck(userID string) (*review.Deck, error) {
+	data, err := os.ReadFile(fs.deckPath(userID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return review.NewDeck(userID), nil
+		}
+		return nil, fmt.Errorf("failed to read review deck: %w", err)
+	}
+
+	deck := review.NewDeck(userID)
+	if err := json.Unmarshal(data, deck); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal review deck: %w", err)
+	}
+	return deck, nil
+}
+
+// SaveDeck saves a user's deck to disk, replacing the old one atomically
+func (fs *FileReviewStorage) SaveDeck(deck *review.Deck) error {
+	if err := os.MkdirAll(fs.basePath, 0755); err != nil {
+		return fmt.Errorf("failed to create base path: %w", err)
+	}
+
+	data, err := json.MarshalIndent(deck, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal review deck: %w", err)
+	}
+
+	if err := writeFileAtomic(fs.deckPath(deck.UserID), data, ""); err != nil {
+		return fmt.Errorf("failed to write review deck: %w", err)
+	}
+	return nil
+}
+
+// deckPath returns the file holding a user's deck
+func (fs *FileReviewStorage) deckPath(userID string) string {
+	return filepath.Join(fs.basePath, fmt.Sprintf("%s.json", userID))
+}
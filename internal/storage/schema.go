@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// SchemaVersion is the session file format this version of the trainer writes
+const SchemaVersion = 1
+
+// sessionFile is the versioned envelope every session file is written in
+type sessionFile struct {
+	SchemaVersion int                     `json:"schema_version"`
+	Session       *models.TrainingSession `json:"session"`
+}
+
+// Migration upgrades a decoded session file from one schema version to the
+// next. Files are migrated as generic JSON, so old formats never need Go types.
+type Migration struct {
+	From        int
+	Description string
+	Upgrade     func(session map[string]any) error
+}
+
+// migrations lists every upgrade in order; migrations[i] upgrades version i
+var migrations = []Migration{
+	{From: 0, Description: "wrap in a versioned envelope; snake_case progress and config fields; durations as strings", Upgrade: upgradeV0},
+}
+
+// VersionError reports a session file written by a newer trainer
+type VersionError struct {
+	Version int
+}
+
+// Error implements error
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("session file has schema version %d, newer than the supported version %d", e.Version, SchemaVersion)
+}
+
+// encodeSession writes a session in the current schema
+func encodeSession(session *models.TrainingSession) ([]byte, error) {
+	return json.MarshalIndent(sessionFile{SchemaVersion: SchemaVersion, Session: session}, "", "  ")
+}
+
+// decodeSession reads a session file of any supported schema version,
+// upgrading it in memory, and reports the version it was written in
+func decodeSession(data []byte) (*models.TrainingSession, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var file map[string]any
+	if err := decoder.Decode(&file); err != nil {
+		return nil, 0, err
+	}
+
+	// Version 0 files are a bare session without an envelope
+	version := 0
+	body := file
+	if raw, ok := file["schema_version"]; ok {
+		number, ok := raw.(json.Number)
+		v, err := number.Int64()
+		if !ok || err != nil {
+			return nil, 0, fmt.Errorf("invalid schema_version %v", raw)
+		}
+		version = int(v)
+		if body, ok = file["session"].(map[string]any); !ok {
+			return nil, version, fmt.Errorf("schema version %d file has no session", version)
+		}
+	}
+	if version > SchemaVersion || version < 0 {
+		return nil, version, &VersionError{Version: version}
+	}
+
+	for _, migration := range migrations[version:] {
+		if err := migration.Upgrade(body); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate from schema version %d: %w", migration.From, err)
+		}
+	}
+
+	upgraded, err := json.Marshal(body)
+	if err != nil {
+		return nil, version, err
+	}
+	var session models.TrainingSession
+	if err := json.Unmarshal(upgraded, &session); err != nil {
+		return nil, version, err
+	}
+	return &session, version, nil
+}
+
+// upgradeV0 renames the untagged Go field names of progress and config to
+// snake_case and turns nanosecond durations into duration strings
+func upgradeV0(session map[string]any) error {
+	if progress, ok := session["progress"].([]any); ok {
+		for _, entry := range progress {
+			fields, ok := entry.(map[string]any)
+			if !ok {
+				return fmt.Errorf("progress entry is %T, not an object", entry)
+			}
+			renameFields(fields, map[string]string{
+				"ExerciseID":     "exercise_id",
+				"StartTime":      "start_time",
+				"CompletedAt":    "completed_at",
+				"Attempts":       "attempts",
+				"Score":          "score",
+				"TimeSpent":      "time_spent",
+				"HintsUsed":      "hints_used",
+				"CompileErrors":  "compile_errors",
+				"WrongAnswers":   "wrong_answers",
+				"AttemptHistory": "attempt_history",
+				"Challenges":     "challenges",
+			})
+			if err := durationField(fields, "time_spent"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if config, ok := session["config"].(map[string]any); ok {
+		renameFields(config, map[string]string{
+			"MaxAttempts":        "max_attempts",
+			"TimeLimit":          "time_limit",
+			"ExerciseTimeFactor": "exercise_time_factor",
+			"TimeWarnings":       "time_warnings",
+			"ShowHints":          "show_hints",
+			"AdaptivePacing":     "adaptive_pacing",
+			"CognitiveLoad":      "cognitive_load",
+			"MasteryThreshold":   "mastery_threshold",
+		})
+		if err := durationField(config, "time_limit"); err != nil {
+			return err
+		}
+		if warnings, ok := config["time_warnings"].([]any); ok {
+			for i := range warnings {
+				converted, err := durationString(warnings[i])
+				if err != nil {
+					return fmt.Errorf("time_warnings[%d]: %w", i, err)
+				}
+				warnings[i] = converted
+			}
+		}
+	}
+
+	return durationField(session, "active_time")
+}
+
+// renameFields moves each field present under an old name to its new name
+func renameFields(fields map[string]any, names map[string]string) {
+	for old, renamed := range names {
+		if value, ok := fields[old]; ok {
+			delete(fields, old)
+			fields[renamed] = value
+		}
+	}
+}
+
+// durationField converts a nanosecond field, if present, to a duration string
+func durationField(fields map[string]any, name string) error {
+	value, ok := fields[name]
+	if !ok || value == nil {
+		return nil
+	}
+	converted, err := durationString(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	fields[name] = converted
+	return nil
+}
+
+// durationString converts nanoseconds to a duration string
+func durationString(value any) (string, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return "", fmt.Errorf("want nanoseconds, got %v", value)
+	}
+	ns, err := number.Int64()
+	if err != nil {
+		return "", err
+	}
+	return time.Duration(ns).String(), nil
+}
@@ -1,11 +1,12 @@
 package storage
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/cmyers78/claude/internal/models"
@@ -19,7 +20,23 @@ type SessionStorage interface {
 	DeleteSession(sessionID string) error
 }
 
-// FileSessionStorage implements SessionStorage using local file system
+// ErrSessionNotFound is returned when loading a session that isn't stored
+var ErrSessionNotFound = errors.New("session not found")
+
+// ErrSessionInUse is returned when another trainer process holds a session
+var ErrSessionInUse = errors.New("session in use")
+
+// Locker is implemented by storage that can reserve a session for one
+// process at a time
+type Locker interface {
+	// LockSession reserves a session, failing with ErrSessionInUse if another
+	// process holds it, and returns a function that releases it
+	LockSession(sessionID string) (unlock func() error, err error)
+}
+
+// FileSessionStorage implements SessionStorage using local file system.
+// Each session is written atomically to <id>.json, the copy it replaced is
+// kept as <id>.json.bak to recover from, and <id>.lock guards resumption.
 type FileSessionStorage struct {
 	basePath string
 }
@@ -38,41 +55,70 @@ func (fs *FileSessionStorage) SaveSession(session *models.TrainingSession) error
 	}
 
 	session.LastActivity = time.Now()
-	
-	data, err := json.MarshalIndent(session, "", "  ")
+
+	data, err := encodeSession(session)
 	if err != nil {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	filename := fmt.Sprintf("%s.json", session.SessionID)
-	path := filepath.Join(fs.basePath, filename)
-	
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	path := fs.sessionPath(session.SessionID)
+
+	// Keep the current file as the backup only if it is intact
+	backup := ""
+	if _, err := readSessionFile(path); err == nil {
+		backup = path + backupSuffix
+	}
+	if err := writeFileAtomic(path, data, backup); err != nil {
 		return fmt.Errorf("failed to write session file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadSession loads a training session from disk
+// LoadSession loads a training session from disk. If the session file is
+// missing or corrupt, it is restored from the previous good copy.
 func (fs *FileSessionStorage) LoadSession(sessionID string) (*models.TrainingSession, error) {
-	filename := fmt.Sprintf("%s.json", sessionID)
-	path := filepath.Join(fs.basePath, filename)
-	
-	data, err := os.ReadFile(path)
-	if err != nil {
+	path := fs.sessionPath(sessionID)
+
+	session, err := readSessionFile(path)
+	if err == nil {
+		return session, nil
+	}
+	var versionErr *VersionError
+	if errors.As(err, &versionErr) {
+		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	}
+
+	backup, backupErr := readSessionFile(path + backupSuffix)
+	if backupErr != nil {
+		if os.IsNotExist(err) && os.IsNotExist(backupErr) {
+			return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+		}
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("session not found: %s", sessionID)
+			return nil, fmt.Errorf("failed to read session backup: %w", backupErr)
 		}
 		return nil, fmt.Errorf("failed to read session file: %w", err)
 	}
 
-	var session models.TrainingSession
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+	data, encodeErr := encodeSession(backup)
+	if encodeErr == nil {
+		encodeErr = writeFileAtomic(path, data, "")
+	}
+	if encodeErr != nil {
+		return nil, fmt.Errorf("failed to restore session from backup: %w", encodeErr)
 	}
+	log.Printf("Warning: Restored session %s from backup after: %v", sessionID, err)
+	return backup, nil
+}
 
-	return &session, nil
+// readSessionFile reads and decodes one session file
+func readSessionFile(path string) (*models.TrainingSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session, _, err := decodeSession(data)
+	return session, err
 }
 
 // ListSessions returns all sessions for a given user
@@ -81,46 +127,160 @@ func (fs *FileSessionStorage) ListSessions(userID string) ([]*models.TrainingSes
 		return nil, fmt.Errorf("failed to ensure base path: %w", err)
 	}
 
-	files, err := os.ReadDir(fs.basePath)
+	ids, err := fs.sessionIDs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+		return nil, err
 	}
 
 	var sessions []*models.TrainingSession
-	for _, file := range files {
-		if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
-			sessionID := file.Name()[:len(file.Name())-5] // Remove .json extension
-			session, err := fs.LoadSession(sessionID)
-			if err != nil {
-				// Log corrupted session files for troubleshooting
-				log.Printf("Warning: Skipping corrupted session file %s: %v", file.Name(), err)
-				continue
-			}
-			if session.UserID == userID {
-				sessions = append(sessions, session)
-			}
+	for _, sessionID := range ids {
+		session, err := fs.LoadSession(sessionID)
+		if err != nil {
+			// Log corrupted session files for troubleshooting
+			log.Printf("Warning: Skipping corrupted session file %s.json: %v", sessionID, err)
+			continue
+		}
+		if session.UserID == userID {
+			sessions = append(sessions, session)
 		}
 	}
 
 	return sessions, nil
 }
 
-// DeleteSession removes a session from disk
+// sessionIDs lists the sessions stored in the base directory. A session may
+// only have its backup if a crash interrupted a save.
+func (fs *FileSessionStorage) sessionIDs() ([]string, error) {
+	files, err := os.ReadDir(fs.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		sessionID, ok := strings.CutSuffix(strings.TrimSuffix(file.Name(), backupSuffix), ".json")
+		if !file.IsDir() && ok && !seen[sessionID] && !strings.HasPrefix(sessionID, ".") {
+			seen[sessionID] = true
+			ids = append(ids, sessionID)
+		}
+	}
+	return ids, nil
+}
+
+// DeleteSession removes a session and its backup from disk. The lock file is
+// left in place, as another process may still hold a lock on it.
 func (fs *FileSessionStorage) DeleteSession(sessionID string) error {
-	filename := fmt.Sprintf("%s.json", sessionID)
-	path := filepath.Join(fs.basePath, filename)
-	
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete session file: %w", err)
+	path := fs.sessionPath(sessionID)
+
+	for _, file := range []string{path, path + backupSuffix} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete session file: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// LockSession reserves a session for this process until unlock is called
+// or the process exits
+func (fs *FileSessionStorage) LockSession(sessionID string) (func() error, error) {
+	if err := fs.ensureBasePath(); err != nil {
+		return nil, fmt.Errorf("failed to ensure base path: %w", err)
+	}
+	unlock, err := acquireLock(fs.lockPath(sessionID))
+	if errors.Is(err, ErrSessionInUse) {
+		return nil, fmt.Errorf("session %s is open in another trainer process: %w", sessionID, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session: %w", err)
+	}
+	return unlock, nil
+}
+
+// backupSuffix names the previous good copy of a session file
+const backupSuffix = ".bak"
+
+// sessionPath returns the file holding a session
+func (fs *FileSessionStorage) sessionPath(sessionID string) string {
+	return filepath.Join(fs.basePath, fmt.Sprintf("%s.json", sessionID))
+}
+
+// lockPath returns the lock file guarding a session
+func (fs *FileSessionStorage) lockPath(sessionID string) string {
+	return filepath.Join(fs.basePath, fmt.Sprintf("%s.lock", sessionID))
+}
+
+// MigrationResult describes one session file upgraded by Migrate
+type MigrationResult struct {
+	SessionID string
+	From      int    // Schema version the file was written in
+	Backup    string // Copy of the original file
+}
+
+// Migrate upgrades every session file older than SchemaVersion in place,
+// first copying the original to <session>.json.v<version>.bak. A file that
+// can't be migrated is left untouched and reported in the returned error.
+func (fs *FileSessionStorage) Migrate() ([]MigrationResult, error) {
+	files, err := os.ReadDir(fs.basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	var results []MigrationResult
+	var failures []error
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(fs.basePath, file.Name())
+		result, err := migrateFile(path)
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", file.Name(), err))
+			continue
+		}
+		if result != nil {
+			results = append(results, *result)
+		}
+	}
+	return results, errors.Join(failures...)
+}
+
+// migrateFile upgrades one session file, returning nil if it is current
+func migrateFile(path string) (*MigrationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	session, version, err := decodeSession(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == SchemaVersion {
+		return nil, nil
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+	upgraded, err := encodeSession(session)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, upgraded, ""); err != nil {
+		return nil, fmt.Errorf("failed to write upgraded file: %w", err)
+	}
+	return &MigrationResult{SessionID: session.SessionID, From: version, Backup: backup}, nil
+}
+
 // ensureBasePath creates the base directory if it doesn't exist
 func (fs *FileSessionStorage) ensureBasePath() error {
 	if err := os.MkdirAll(fs.basePath, 0755); err != nil {
 		return fmt.Errorf("failed to create base path: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
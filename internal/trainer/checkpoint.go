@@ -0,0 +1,118 @@
+package trainer
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// checkpoint saves the session as active so a crash loses at most the
+// challenge in progress
+func (t *CLTTrainer) checkpoint() {
+	if t.storage == nil {
+		return
+	}
+	if err := t.saveSession(models.SessionActive); err != nil {
+		t.presenter.Error(fmt.Errorf("Error saving checkpoint: %w", err))
+	}
+}
+
+// stop ends training after a challenge that didn't complete: a pause saves
+// the session for resuming, a quit marks it abandoned
+func (t *CLTTrainer) stop(outcome challengeOutcome) {
+	if outcome.paused {
+		t.pause()
+		return
+	}
+	if !outcome.quit {
+		return
+	}
+	if t.storage != nil && t.stored {
+		if err := t.saveSession(models.SessionAbandoned); err != nil {
+			t.presenter.Error(fmt.Errorf("Error saving session: %w", err))
+		}
+	}
+	t.emit(SessionAbandoned)
+}
+
+// Interrupt asks a running session to save a paused state and stop, as
+// SIGINT and SIGTERM do. It is safe to call from any goroutine.
+func (t *CLTTrainer) Interrupt() {
+	select {
+	case t.interrupts <- struct{}{}:
+	default: // An interrupt is already pending
+	}
+}
+
+// IgnoreSignals leaves SIGINT and SIGTERM to the caller, which calls
+// Interrupt itself, as a server running many sessions does
+func (t *CLTTrainer) IgnoreSignals() {
+	t.ignoreSignals = true
+}
+
+// watchSignals turns SIGINT and SIGTERM into interrupts until the returned
+// function is called
+func (t *CLTTrainer) watchSignals() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				t.Interrupt()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// saveOnPanic saves a paused state if training panics, then lets the panic
+// continue
+func (t *CLTTrainer) saveOnPanic() {
+	if r := recover(); r != nil {
+		if t.storage != nil {
+			if err := t.saveSession(models.SessionPaused); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving session after panic: %v\n", err)
+			}
+		}
+		panic(r)
+	}
+}
+
+// ResumableSessions returns a user's paused sessions, plus active ones left
+// behind by a crash. Active sessions another process holds are left out.
+func ResumableSessions(userID string, sessionStorage storage.SessionStorage) ([]*models.TrainingSession, error) {
+	sessions, err := sessionStorage.ListSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	locker, _ := sessionStorage.(storage.Locker)
+	var resumable []*models.TrainingSession
+	for _, session := range sessions {
+		switch session.Status {
+		case models.SessionPaused:
+			resumable = append(resumable, session)
+		case models.SessionActive:
+			if locker != nil {
+				unlock, err := locker.LockSession(session.SessionID)
+				if err != nil {
+					continue // Still running elsewhere
+				}
+				unlock()
+			}
+			resumable = append(resumable, session)
+		}
+	}
+	return resumable, nil
+}
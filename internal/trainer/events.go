@@ -0,0 +1,93 @@
+package trainer
+
+import (
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// EventType names something that happened in a training session
+type EventType string
+
+const (
+	SessionStarted    EventType = "session_started"
+	SessionResumed    EventType = "session_resumed"
+	ExerciseStarted   EventType = "exercise_started"
+	AnswerSubmitted   EventType = "answer_submitted"
+	ExerciseCompleted EventType = "exercise_completed"
+	SessionPaused     EventType = "session_paused"
+	SessionCompleted  EventType = "session_completed"
+	SessionAbandoned  EventType = "session_abandoned"
+)
+
+// Event describes one thing that happened in a session, for recording
+// learning activity outside the session file
+type Event struct {
+	Type       EventType
+	At         time.Time
+	UserID     string
+	SessionID  string
+	ActiveTime time.Duration // Time trained in the session so far
+
+	// Set for exercise and answer events
+	Exercise *models.Exercise
+	Progress *models.LearningProgress // The exercise's progress, as of the event
+	Attempt  *models.AttemptRecord    // The answer submitted, for AnswerSubmitted
+}
+
+// Listener is told about session events as they happen. Listeners run on
+// the trainer's goroutine, so should hand off anything slow.
+type Listener interface {
+	Event(event Event)
+}
+
+// ListenerFunc adapts a function to a Listener
+type ListenerFunc func(event Event)
+
+// Event implements Listener
+func (f ListenerFunc) Event(event Event) {
+	f(event)
+}
+
+// AddListener registers a listener for the trainer's session events
+func (t *CLTTrainer) AddListener(listener Listener) {
+	t.listeners = append(t.listeners, listener)
+}
+
+// emit tells every listener about a session event
+func (t *CLTTrainer) emit(eventType EventType) {
+	t.emitEvent(Event{Type: eventType})
+}
+
+// emitExercise tells every listener about an event in the current
+// exercise, timed as the exercise's progress records it
+func (t *CLTTrainer) emitExercise(eventType EventType, attempt *models.AttemptRecord) {
+	exercise := t.exercises[t.current]
+	progress := t.progress[t.current]
+	event := Event{Type: eventType, Exercise: &exercise, Progress: &progress, Attempt: attempt}
+	switch {
+	case attempt != nil:
+		event.At = attempt.SubmittedAt
+	case eventType == ExerciseStarted:
+		event.At = progress.StartTime
+	case eventType == ExerciseCompleted && progress.CompletedAt != nil:
+		event.At = *progress.CompletedAt
+	}
+	t.emitEvent(event)
+}
+
+// emitEvent fills in the session's details and tells every listener
+func (t *CLTTrainer) emitEvent(event Event) {
+	if len(t.listeners) == 0 {
+		return
+	}
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	event.UserID = t.userID
+	event.SessionID = t.getOrCreateSessionID()
+	event.ActiveTime = t.activeTime()
+	for _, listener := range t.listeners {
+		listener.Event(event)
+	}
+}
@@ -0,0 +1,165 @@
+package trainer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// answerSentinel on a line of its own ends a multi-line answer
+const answerSentinel = "."
+
+// readAnswer reads a learner's answer. A single line is returned as is,
+// unless it leaves brackets open, in which case reading continues until
+// they are closed. It reports eof when input has run out.
+func readAnswer(reader *bufio.Reader, out io.Writer) (answer string, eof bool) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", true
+	}
+
+	answer = strings.TrimRight(line, "\r\n")
+	if bracketDepth(answer) <= 0 {
+		return strings.TrimSpace(answer), err == io.EOF
+	}
+
+	rest, eof := readUntil(reader, out, func(lines []string) bool {
+		return bracketDepth(answer+"\n"+strings.Join(lines, "\n")) <= 0
+	})
+	if rest != "" {
+		answer += "\n" + rest
+	}
+	return strings.TrimSpace(answer), eof
+}
+
+// readMultiline reads lines until the sentinel line or EOF
+func readMultiline(reader *bufio.Reader, out io.Writer) (answer string, eof bool) {
+	answer, eof = readUntil(reader, out, func([]string) bool { return false })
+	return strings.TrimSpace(answer), eof
+}
+
+// readUntil collects lines until done reports true, a sentinel line is
+// read, or input runs out. The sentinel itself is not included. A
+// continuation prompt is written to out before each line.
+func readUntil(reader *bufio.Reader, out io.Writer, done func(lines []string) bool) (string, bool) {
+	var lines []string
+	for {
+		fmt.Fprint(out, "... ")
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return strings.Join(lines, "\n"), true
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == answerSentinel {
+			return strings.Join(lines, "\n"), false
+		}
+
+		lines = append(lines, line)
+		if done(lines) {
+			return strings.Join(lines, "\n"), false
+		}
+		if err == io.EOF {
+			return strings.Join(lines, "\n"), true
+		}
+	}
+}
+
+// bracketDepth counts unclosed (, [ and { outside strings, runes and comments
+func bracketDepth(code string) int {
+	depth := 0
+	var quote rune
+	lineComment, blockComment, escaped := false, false, false
+
+	runes := []rune(code)
+	for i, r := range runes {
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case lineComment:
+			lineComment = r != '\n'
+		case blockComment:
+			if r == '/' && i > 0 && runes[i-1] == '*' {
+				blockComment = false
+			}
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if r == '\\' && quote != '`' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '/' && next == '/':
+			lineComment = true
+		case r == '/' && next == '*':
+			blockComment = true
+		case r == '"' || r == '\'' || r == '`':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		}
+	}
+
+	// An unterminated raw string spans lines just like an open bracket
+	if quote == '`' {
+		depth++
+	}
+	return depth
+}
+
+// editAnswer writes the template to a temporary .go file, opens it in the
+// learner's editor and returns the saved contents once the editor exits
+func editAnswer(template string) (string, error) {
+	file, err := os.CreateTemp("", "challenge-*.go")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	if _, err := file.WriteString(template + "\n"); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write template: %w", err)
+	}
+
+	editor := strings.Fields(editorCommand())
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %q failed: %w", editor[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// editorCommand picks the editor from $VISUAL, then $EDITOR, then a platform default
+func editorCommand() string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(key)); editor != "" {
+			return editor
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
@@ -0,0 +1,106 @@
+package trainer
+
+import (
+	"slices"
+
+	"github.com/cmyers78/claude/internal/mastery"
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// maxPracticeRounds caps the extra challenges posed for unmastered concepts,
+// so a learner who keeps struggling still moves on
+const maxPracticeRounds = 2
+
+// newMastery creates the mastery model, restoring any saved estimates. With
+// no threshold configured, mastery is tracked against DefaultThreshold but
+// doesn't hold the learner back.
+func newMastery(config models.TrainerConfig, saved []models.ConceptMastery) *mastery.Model {
+	threshold := config.MasteryThreshold
+	if threshold <= 0 {
+		threshold = mastery.DefaultThreshold
+	}
+	return mastery.Restore(mastery.DefaultParams, threshold, saved)
+}
+
+// observe updates the mastery of a challenge's concepts from how it went
+func (t *CLTTrainer) observe(challenge models.Challenge, entry models.ChallengeProgress) {
+	t.mastery.Record(challenge.Concepts, mastery.Outcome{
+		WrongAttempts: entry.Attempts - btoi(entry.Solved),
+		HintsUsed:     entry.HintsUsed,
+		Solved:        entry.Solved,
+	})
+}
+
+// practice re-poses challenges for concepts from the posed challenges that
+// haven't reached the mastery threshold, so the trainer moves on only once
+// they are mastered or the practice rounds run out. Practice answers are
+// kept in the attempt history but don't count towards the exercise score.
+func (t *CLTTrainer) practice(exercise models.Exercise, posed []int) bool {
+	if t.config.MasteryThreshold <= 0 {
+		return true
+	}
+
+	var concepts []string
+	for _, i := range posed {
+		concepts = append(concepts, exercise.Challenges[i].Concepts...)
+	}
+
+	for round := 0; round < maxPracticeRounds; round++ {
+		weak := t.mastery.Unmastered(concepts)
+		if len(weak) == 0 {
+			return true
+		}
+		t.presenter.Practice(weak)
+
+		for _, i := range practiceSet(exercise, posed, weak) {
+			challenge := exercise.Challenges[i]
+			t.presenter.Challenge(t.offered(challenge), i+1, len(exercise.Challenges))
+
+			outcome := t.runSingleChallenge(challenge, i, models.ChallengeProgress{})
+			t.progress[t.current].AttemptHistory = append(t.progress[t.current].AttemptHistory, outcome.history...)
+			for a := range outcome.history {
+				t.emitExercise(AnswerSubmitted, &outcome.history[a])
+			}
+			if !outcome.completed {
+				t.stop(outcome)
+				return false
+			}
+			t.checkpoint()
+			if outcome.timedOut {
+				return true
+			}
+			var entry models.ChallengeProgress
+			tally(&entry, outcome)
+			t.observe(challenge, entry)
+		}
+	}
+	return true
+}
+
+// practiceSet picks posed challenges in order, keeping each one that covers
+// a weak concept not already covered
+func practiceSet(exercise models.Exercise, posed []int, weak []string) []int {
+	var picked []int
+	covered := make(map[string]bool)
+	for _, i := range posed {
+		adds := false
+		for _, concept := range exercise.Challenges[i].Concepts {
+			if slices.Contains(weak, concept) && !covered[concept] {
+				covered[concept] = true
+				adds = true
+			}
+		}
+		if adds {
+			picked = append(picked, i)
+		}
+	}
+	return picked
+}
+
+// btoi converts a bool to 1 or 0
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package trainer
+
+import (
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/review"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+// Presenter renders a training session. The trainer decides what happens and
+// when; a Presenter only decides how it looks, so the same session can run in
+// a terminal, a web page or a chat bot.
+type Presenter interface {
+	Welcome()
+	Resumed(pausedAt time.Time, position, total int, exercise models.Exercise, challenge int)
+	LearningGoals(exercise models.Exercise)
+	Examples(exercise models.Exercise)
+	ReadyPrompt()
+	ChallengesHeader()
+	Challenge(challenge models.Challenge, number, total int)
+	AnswerPrompt()
+	MultilineInstructions()
+	Submitting(code string)
+	EditUnchanged()
+	Help()
+	Hint(hint string)
+	HintsOff()
+	Solution(reason SolutionReason, solution string)
+	Correct(attempts, hintsUsed int)
+	CompileErrors(diagnostics []validation.Diagnostic)
+	TestFailures(failures *validation.TestFailures)
+	OutputMismatch(diff string)
+	RunError(err error)
+	Retry(attempts int, hints bool) // hints says whether the learner may ask for one
+	ExtraExample(example models.Example)
+	Adapted(decision models.AdaptiveDecision)
+	Practice(concepts []string)
+	TimeRemaining(session, exercise time.Duration)
+	TimeWarning(scope TimeScope, remaining time.Duration)
+	TimeUp(scope TimeScope)
+	Interrupted()
+	SessionSaved()
+	Error(err error)
+	ExerciseCompleted(exercise models.Exercise, progress models.LearningProgress)
+	Unlocked(exercises []models.Exercise)
+	Results(summary Summary)
+
+	ReviewStart(due int, next time.Time)
+	ReviewCard(exercise models.Exercise, number, total int)
+	Reviewed(card review.Card)
+	ReviewDone(reviewed, remaining int)
+}
+
+// Input supplies a learner's commands and answers. Each method reports eof
+// once no more input will arrive.
+type Input interface {
+	// ReadLine reads one line, such as the go-ahead to start challenges
+	ReadLine() (line string, eof bool)
+	// ReadAnswer reads an answer or command
+	ReadAnswer() (answer string, eof bool)
+	// ReadMultiline reads an answer that spans lines
+	ReadMultiline() (answer string, eof bool)
+	// Edit lets the learner write a whole program starting from template
+	Edit(template string) (string, error)
+}
+
+// SolutionReason says why a solution is being revealed
+type SolutionReason int
+
+const (
+	HintsExhausted SolutionReason = iota // Asked for a hint after the last one
+	Skipped                              // Skipped the challenge
+	OutOfAttempts                        // Used every attempt
+	OutOfTime                            // The exercise's time limit ran out
+)
+
+// Summary describes a finished or interrupted session
+type Summary struct {
+	Completed     []ExerciseResult
+	Total         int // Exercises in the curriculum
+	TotalTime     time.Duration
+	Attempts      int
+	CompileErrors int
+	WrongAnswers  int
+	HintsUsed     int
+
+	Mastery          []models.ConceptMastery // Per-concept estimates, in the order first practiced
+	MasteryThreshold float64
+}
+
+// ExerciseResult pairs a completed exercise with its progress
+type ExerciseResult struct {
+	Exercise models.Exercise
+	Progress models.LearningProgress
+}
+
+// AverageAttempts returns attempts per completed exercise
+func (s Summary) AverageAttempts() float64 {
+	if len(s.Completed) == 0 {
+		return 0
+	}
+	return float64(s.Attempts) / float64(len(s.Completed))
+}
+
+// AverageScore returns the mean score of completed exercises
+func (s Summary) AverageScore() float64 {
+	if len(s.Completed) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, result := range s.Completed {
+		total += result.Progress.Score
+	}
+	return total / float64(len(s.Completed))
+}
@@ -0,0 +1,106 @@
+package trainer
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/review"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// Reviewer serves the challenges a learner's spaced-repetition deck says
+// are due, from any exercise, and reschedules them from the answers
+type Reviewer struct {
+	runner    *CLTTrainer // Runs each challenge exactly as training does
+	exercises map[string]models.Exercise
+	deck      *review.Deck
+	store     storage.ReviewStorage
+}
+
+// NewReviewer creates a reviewer over the given exercises. Only MaxAttempts
+// and ShowHints are taken from config; reviews have no time limits or
+// adaptive pacing.
+func NewReviewer(exercises []models.Exercise, config models.TrainerConfig, deck *review.Deck, store storage.ReviewStorage) *Reviewer {
+	byID := make(map[string]models.Exercise, len(exercises))
+	for _, exercise := range exercises {
+		byID[exercise.ID] = exercise
+	}
+
+	reviewConfig := models.TrainerConfig{MaxAttempts: config.MaxAttempts, ShowHints: config.ShowHints}
+	runner := NewCLTTrainer(exercises, reviewConfig, deck.UserID, nil)
+	return &Reviewer{runner: runner, exercises: byID, deck: deck, store: store}
+}
+
+// SetIO replaces the terminal on os.Stdin and os.Stdout with another front-end
+func (r *Reviewer) SetIO(presenter Presenter, input Input) {
+	r.runner.SetIO(presenter, input)
+}
+
+// ImportSessions schedules challenges from training sessions not yet imported
+func ImportSessions(deck *review.Deck, sessions []*models.TrainingSession) int {
+	imported := 0
+	for _, session := range sessions {
+		imported += deck.Import(session)
+	}
+	return imported
+}
+
+// Due returns the cards due now that still match a known challenge
+func (r *Reviewer) Due(now time.Time) []*review.Card {
+	var due []*review.Card
+	for _, card := range r.deck.Due(now) {
+		if _, ok := r.challenge(card); ok {
+			due = append(due, card)
+		}
+	}
+	return due
+}
+
+// challenge finds the exercise and challenge a card is for
+func (r *Reviewer) challenge(card *review.Card) (models.Exercise, bool) {
+	exercise, ok := r.exercises[card.ExerciseID]
+	if !ok || card.Challenge < 0 || card.Challenge >= len(exercise.Challenges) {
+		return models.Exercise{}, false
+	}
+	return exercise, true
+}
+
+// Run reviews every due card until they are done or the learner quits,
+// saving the deck after each one. It returns the number reviewed.
+func (r *Reviewer) Run() (int, error) {
+	t := r.runner
+	if t.presenter == nil || t.input == nil {
+		terminal := NewTerminal(os.Stdin, os.Stdout)
+		t.SetIO(terminal, terminal)
+	}
+
+	due := r.Due(time.Now())
+	next, _ := r.deck.NextDue()
+	t.presenter.ReviewStart(len(due), next)
+
+	reviewed := 0
+	for i, card := range due {
+		exercise, _ := r.challenge(card)
+		challenge := exercise.Challenges[card.Challenge]
+		t.presenter.ReviewCard(exercise, i+1, len(due))
+		t.presenter.Challenge(t.offered(challenge), card.Challenge+1, len(exercise.Challenges))
+
+		outcome := t.runSingleChallenge(challenge, card.Challenge, models.ChallengeProgress{})
+		if !outcome.completed {
+			break // Learner quit or paused; reviews are saved as they go
+		}
+
+		quality := review.Quality(outcome.attempts, outcome.hintsUsed, outcome.solved)
+		card = r.deck.Record(card.ExerciseID, card.Challenge, quality, time.Now())
+		reviewed++
+		if err := r.store.SaveDeck(r.deck); err != nil {
+			return reviewed, fmt.Errorf("failed to save review deck: %w", err)
+		}
+		t.presenter.Reviewed(*card)
+	}
+
+	t.presenter.ReviewDone(reviewed, len(due)-reviewed)
+	return reviewed, nil
+}
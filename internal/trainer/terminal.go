@@ -0,0 +1,482 @@
+package trainer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/review"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+// Terminal presents a training session as text and reads answers line by line
+type Terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewTerminal creates a terminal front-end reading from in and writing to out
+func NewTerminal(in io.Reader, out io.Writer) *Terminal {
+	return &Terminal{in: bufio.NewReader(in), out: out}
+}
+
+// printf writes formatted output; write errors leave nothing useful to do
+func (term *Terminal) printf(format string, args ...any) {
+	fmt.Fprintf(term.out, format, args...)
+}
+
+// println writes a line of output
+func (term *Terminal) println(args ...any) {
+	fmt.Fprintln(term.out, args...)
+}
+
+// ReadLine implements Input
+func (term *Terminal) ReadLine() (string, bool) {
+	line, err := term.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", true
+	}
+	return strings.TrimSpace(line), false
+}
+
+// ReadAnswer implements Input
+func (term *Terminal) ReadAnswer() (string, bool) {
+	return readAnswer(term.in, term.out)
+}
+
+// ReadMultiline implements Input
+func (term *Terminal) ReadMultiline() (string, bool) {
+	return readMultiline(term.in, term.out)
+}
+
+// Edit implements Input
+func (term *Terminal) Edit(template string) (string, error) {
+	return editAnswer(template)
+}
+
+// Welcome introduces the training with clear expectations
+func (term *Terminal) Welcome() {
+	term.println("🧠 Go Trainer with Cognitive Load Theory")
+	term.println("=========================================")
+	term.println()
+	term.println("This trainer uses proven learning science principles:")
+	term.println("• Worked examples before practice")
+	term.println("• Progressive disclosure of complexity")
+	term.println("• Multiple practice opportunities")
+	term.println("• Adaptive pacing based on your progress")
+	term.println()
+	term.println("Commands: 'hint', 'skip', 'multi', 'edit', 'pause', 'quit', 'help'")
+	term.println()
+}
+
+// Resumed reports where a resumed session picks up
+func (term *Terminal) Resumed(pausedAt time.Time, position, total int, exercise models.Exercise, challenge int) {
+	term.printf("🔄 Resuming session from %s\n", pausedAt.Format("2006-01-02 15:04:05"))
+	term.printf("📍 Current position: Exercise %d/%d (%s)", position, total, exercise.Title)
+	if challenge > 0 && challenge <= len(exercise.Challenges) {
+		term.printf(", challenge %d/%d", challenge, len(exercise.Challenges))
+	}
+	term.println()
+	term.println()
+}
+
+// LearningGoals clearly states what the learner will achieve
+func (term *Terminal) LearningGoals(exercise models.Exercise) {
+	term.printf("📋 %s\n", exercise.Title)
+	term.printf("Description: %s\n\n", exercise.Description)
+
+	term.println("🎯 Learning Goals:")
+	for i, goal := range exercise.LearningGoals {
+		term.printf("   %d. %s\n", i+1, goal)
+	}
+	term.println()
+
+	if len(exercise.Prerequisites) > 0 {
+		term.println("📚 Prerequisites:")
+		for _, prereq := range exercise.Prerequisites {
+			term.printf("   • %s\n", prereq)
+		}
+		term.println()
+	}
+
+	term.printf("⏱️  Estimated time: %d minutes\n\n", exercise.EstimatedTime)
+}
+
+// Examples implements the worked example effect
+func (term *Terminal) Examples(exercise models.Exercise) {
+	term.println("📖 Examples (Study these carefully):")
+	term.println("=====================================")
+
+	for i, example := range exercise.Examples {
+		term.printf("\n%d. %s\n", i+1, example.Title)
+		term.println(strings.Repeat("-", len(example.Title)+3))
+
+		term.printf("Code:\n%s\n\n", formatCodeBlock(example.Code))
+		term.printf("Explanation: %s\n", example.Explanation)
+
+		if example.Output != "" {
+			term.printf("Output: %s\n", example.Output)
+		}
+
+		term.println()
+	}
+}
+
+// ReadyPrompt asks the learner to continue once they have studied the examples
+func (term *Terminal) ReadyPrompt() {
+	term.printf("\nPress Enter when ready to try the challenges...")
+}
+
+// ChallengesHeader introduces the practice challenges
+func (term *Terminal) ChallengesHeader() {
+	term.println("🎯 Practice Challenges:")
+	term.println("======================")
+}
+
+// Challenge shows the task and its template
+func (term *Terminal) Challenge(challenge models.Challenge, number, total int) {
+	term.printf("\nChallenge %d/%d\n", number, total)
+	term.println(strings.Repeat("-", 15))
+	term.printf("Task: %s\n\n", challenge.Description)
+	term.printf("Template:\n%s\n\n", formatCodeBlock(challenge.Template))
+}
+
+// AnswerPrompt asks for an answer or command
+func (term *Terminal) AnswerPrompt() {
+	term.printf("Your solution: ")
+}
+
+// MultilineInstructions explains how to finish a multi-line answer
+func (term *Terminal) MultilineInstructions() {
+	term.printf("Enter your code. Finish with a line containing only %q.\n", answerSentinel)
+}
+
+// Submitting echoes code written in an editor before it is checked
+func (term *Terminal) Submitting(code string) {
+	term.printf("Submitting:\n%s\n", formatCodeBlock(code))
+}
+
+// EditUnchanged reports that the editor was closed without changes
+func (term *Terminal) EditUnchanged() {
+	term.println("No changes saved, so nothing was submitted.")
+}
+
+// Help provides contextual assistance
+func (term *Terminal) Help() {
+	term.println("\n📚 Available Commands:")
+	term.println("  hint  - Get a helpful hint for the current challenge")
+	term.println("  skip  - Skip the current challenge and see the solution")
+	term.println("  multi - Type a multi-line answer, ending with a line containing only " + answerSentinel)
+	term.println("  edit  - Open the template in $VISUAL or $EDITOR and submit it when you save and quit")
+	term.println("  pause - Save your progress and exit (resume later)")
+	term.println("  quit  - Exit the trainer without saving")
+	term.println("  help  - Show this help message")
+	term.println()
+}
+
+// Hint shows the next hint
+func (term *Terminal) Hint(hint string) {
+	term.printf("💡 Hint: %s\n", hint)
+}
+
+// HintsOff refuses a hint when hints are turned off
+func (term *Terminal) HintsOff() {
+	term.println("Hints are turned off for this session. Type 'skip' to see the solution.")
+}
+
+// Solution reveals a challenge's solution
+func (term *Terminal) Solution(reason SolutionReason, solution string) {
+	switch reason {
+	case Skipped:
+		term.printf("⏭️  Skipped. Solution: %s\n", solution)
+	case OutOfAttempts:
+		term.printf("Max attempts reached. Solution: %s\n", solution)
+	case OutOfTime:
+		term.printf("Solution: %s\n", solution)
+	default:
+		term.printf("💡 Solution: %s\n", solution)
+	}
+}
+
+// Correct provides elaborative feedback for a passing answer
+func (term *Terminal) Correct(attempts, hintsUsed int) {
+	term.println("✅ Excellent! That's correct!")
+
+	if attempts == 1 && hintsUsed == 0 {
+		term.println("🌟 Perfect on first try!")
+	} else if attempts <= 2 {
+		term.println("👍 Good work!")
+	} else {
+		term.println("💪 Great persistence!")
+	}
+}
+
+// CompileErrors reports compiler diagnostics against the learner's own lines
+func (term *Terminal) CompileErrors(diagnostics []validation.Diagnostic) {
+	term.println("🛠️  Your code doesn't compile yet:")
+	for _, diagnostic := range diagnostics {
+		term.printf("   %s\n", diagnostic)
+	}
+	term.println("Fix the error above and try again.")
+}
+
+// TestFailures lists the hidden tests an answer failed
+func (term *Terminal) TestFailures(failures *validation.TestFailures) {
+	if len(failures.Report.Failures) == 0 {
+		term.printf("❌ %s\n", failures)
+		return
+	}
+	term.println("❌ Your code compiles, but some tests failed:")
+	for _, test := range failures.Report.Failures {
+		term.printf("   • %s\n", test.Name)
+		for _, message := range test.Messages {
+			term.printf("       %s\n", message)
+		}
+	}
+}
+
+// OutputMismatch shows how a program's output differs from what was expected
+func (term *Terminal) OutputMismatch(diff string) {
+	term.println("❌ Your program compiles, but its output isn't right:")
+	term.println("   (- expected, + your output)")
+	for _, line := range strings.Split(diff, "\n") {
+		term.printf("   %s\n", line)
+	}
+}
+
+// RunError reports a program that compiled but failed to run properly
+func (term *Terminal) RunError(err error) {
+	term.printf("❌ Your program compiles, but %s\n", err)
+}
+
+// Retry gives targeted help based on CLT principles
+func (term *Terminal) Retry(attempts int, hints bool) {
+	if attempts == 1 {
+		// First mistake: gentle guidance
+		term.println("❌ Not quite right. Compare your answer with the examples above.")
+	} else if !hints {
+		term.println("❌ Still not correct. Review the examples and check each part of your answer.")
+	} else if attempts == 2 {
+		// Second mistake: more specific help
+		term.println("❌ Still not correct. Type 'hint' for guidance, or review the examples.")
+	} else {
+		// Multiple mistakes: direct support
+		term.println("❌ Let's break this down. Type 'hint' for step-by-step help.")
+	}
+}
+
+// ExtraExample shows a support example before the next challenge
+func (term *Terminal) ExtraExample(example models.Example) {
+	term.printf("\n📖 %s\n", example.Title)
+	term.println(strings.Repeat("-", len(example.Title)+3))
+	term.printf("Code:\n%s\n\n", formatCodeBlock(example.Code))
+	term.printf("Explanation: %s\n", example.Explanation)
+	if example.Output != "" {
+		term.printf("Output: %s\n", example.Output)
+	}
+	term.println()
+}
+
+// Adapted explains an adaptive pacing decision
+func (term *Terminal) Adapted(decision models.AdaptiveDecision) {
+	switch decision.Action {
+	case models.ActionExtraExample:
+		term.printf("\n🧩 Let's study one more worked example first (you %s).\n", decision.Reason)
+	case models.ActionSkipChallenges:
+		term.printf("\n🚀 You %s, so we'll skip %d challenge(s) and go to the final one.\n", decision.Reason, decision.Skipped)
+	case models.ActionLevelUp:
+		term.printf("📈 Moving up to %s level: you %s.\n\n", decision.Level, decision.Reason)
+	case models.ActionLevelDown:
+		term.printf("📉 Switching to %s level for more support: you %s.\n\n", decision.Level, decision.Reason)
+	}
+}
+
+// Practice explains why challenges are being posed again
+func (term *Terminal) Practice(concepts []string) {
+	term.printf("\n🔄 Let's practice %s a little more before moving on.\n", strings.Join(concepts, ", "))
+}
+
+// TimeRemaining shows the time left under each limit; 0 means no limit
+func (term *Terminal) TimeRemaining(session, exercise time.Duration) {
+	var parts []string
+	if session > 0 {
+		parts = append(parts, formatRemaining(session)+" left in session")
+	}
+	if exercise > 0 {
+		parts = append(parts, formatRemaining(exercise)+" left for this exercise")
+	}
+	term.printf("⏳ %s\n", strings.Join(parts, " · "))
+}
+
+// TimeWarning warns that a limit is close
+func (term *Terminal) TimeWarning(scope TimeScope, remaining time.Duration) {
+	term.printf("\n⚠️  Only %s left in this %s!\n", formatRemaining(remaining), scope)
+}
+
+// TimeUp reports that a limit was reached
+func (term *Terminal) TimeUp(scope TimeScope) {
+	if scope == ExerciseTime {
+		term.println("\n⌛ Time's up for this exercise. Moving on.")
+		return
+	}
+	term.println("\n⌛ Session time limit reached. Pausing your session.")
+}
+
+// Interrupted acknowledges Ctrl-C or a termination signal
+func (term *Terminal) Interrupted() {
+	term.println("\n\n⚠️  Interrupted. Saving your progress...")
+}
+
+// SessionSaved confirms a paused session was stored
+func (term *Terminal) SessionSaved() {
+	term.println("💾 Session saved! Use 'claude trainer resume' to continue later.")
+}
+
+// Error reports a problem the learner can act on
+func (term *Terminal) Error(err error) {
+	term.printf("❌ %v\n", err)
+}
+
+// ExerciseCompleted shows the score for a finished exercise
+func (term *Terminal) ExerciseCompleted(exercise models.Exercise, progress models.LearningProgress) {
+	term.printf("✅ %s completed!\n", exercise.Title)
+	term.printf("Time spent: %.1f minutes\n", progress.TimeSpent.Minutes())
+	term.printf("Score: %.1f/100\n\n", progress.Score)
+}
+
+// Unlocked lists exercises that just became available
+func (term *Terminal) Unlocked(exercises []models.Exercise) {
+	titles := make([]string, len(exercises))
+	for i, exercise := range exercises {
+		titles[i] = exercise.Title
+	}
+	term.printf("🔓 Unlocked: %s\n\n", strings.Join(titles, ", "))
+}
+
+// Results provides a comprehensive learning summary
+func (term *Terminal) Results(summary Summary) {
+	term.println("\n🎉 Training Complete!")
+	term.println("====================")
+
+	completed := len(summary.Completed)
+	term.printf("Exercises completed: %d/%d\n", completed, summary.Total)
+	term.printf("Total time: %.1f minutes\n", summary.TotalTime.Minutes())
+
+	term.printf("Total attempts: %d\n", summary.Attempts)
+	term.printf("  Didn't compile: %d\n", summary.CompileErrors)
+	term.printf("  Compiled but incorrect: %d\n", summary.WrongAnswers)
+	term.printf("Hints used: %d\n", summary.HintsUsed)
+	if completed > 0 {
+		term.printf("Average attempts per exercise: %.1f\n", summary.AverageAttempts())
+		term.printf("Average score: %.1f/100\n", summary.AverageScore())
+	}
+
+	// Individual exercise scores
+	if completed > 0 {
+		term.println("\n📊 Exercise Scores:")
+		for _, result := range summary.Completed {
+			term.printf("  %s: %.1f/100\n", result.Exercise.Title, result.Progress.Score)
+		}
+	}
+
+	// Learning reinforcement
+	if len(summary.Mastery) > 0 {
+		term.println("\n🧠 Concept Mastery:")
+		for _, estimate := range summary.Mastery {
+			status := "📈"
+			if estimate.Probability >= summary.MasteryThreshold {
+				status = "✅"
+			}
+			term.printf("  %s %-22s %3.0f%% (%d/%d correct)\n", status, estimate.Concept, estimate.Probability*100, estimate.Correct, estimate.Observations)
+		}
+	} else {
+		term.println("\n🧠 Key Concepts Learned:")
+		for i, result := range summary.Completed {
+			term.printf("  %d. %s\n", i+1, result.Exercise.Title)
+			for _, goal := range result.Exercise.LearningGoals {
+				term.printf("     • %s\n", goal)
+			}
+		}
+	}
+
+	term.println("\n🚀 Next Steps:")
+	term.println("  • Practice these concepts in your own projects")
+	term.println("  • Explore Go's standard library")
+	term.println("  • Join the Go community online")
+}
+
+// ReviewStart introduces a review session
+func (term *Terminal) ReviewStart(due int, next time.Time) {
+	term.println("🔁 Spaced Repetition Review")
+	term.println("===========================")
+	switch {
+	case due > 0:
+		term.printf("%d challenge(s) due for review.\n", due)
+		term.println("Commands: 'hint', 'skip', 'multi', 'edit', 'quit', 'help'")
+	case next.IsZero():
+		term.println("Nothing to review yet. Complete some training first.")
+	default:
+		term.printf("Nothing due right now. Next review: %s\n", next.Format("2006-01-02 15:04"))
+	}
+}
+
+// ReviewCard names the exercise a review challenge comes from
+func (term *Terminal) ReviewCard(exercise models.Exercise, number, total int) {
+	term.printf("\n🔁 Review %d/%d: %s\n", number, total, exercise.Title)
+}
+
+// Reviewed shows when a reviewed challenge will come back
+func (term *Terminal) Reviewed(card review.Card) {
+	if card.Interval == 1 {
+		term.println("📅 Next review: tomorrow")
+		return
+	}
+	term.printf("📅 Next review: in %d days (%s)\n", card.Interval, card.Due.Format("2006-01-02"))
+}
+
+// ReviewDone summarizes a review session
+func (term *Terminal) ReviewDone(reviewed, remaining int) {
+	if reviewed == 0 && remaining == 0 {
+		return
+	}
+	term.printf("\n✅ Reviewed %d challenge(s)", reviewed)
+	if remaining > 0 {
+		term.printf(", %d still due", remaining)
+	}
+	term.println(".")
+}
+
+// formatRemaining renders a duration as m:ss, or h:mm:ss from an hour up
+func formatRemaining(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// formatCodeBlock formats code for clean terminal display
+func formatCodeBlock(code string) string {
+	lines := strings.Split(code, "\n")
+	var formatted strings.Builder
+
+	// Add top border
+	formatted.WriteString("┌" + strings.Repeat("─", 60) + "┐\n")
+
+	// Add code lines with side borders and indentation
+	for _, line := range lines {
+		// Ensure line fits within border, truncate if necessary
+		if len(line) > 56 {
+			line = line[:53] + "..."
+		}
+		formatted.WriteString(fmt.Sprintf("│  %-56s  │\n", line))
+	}
+
+	// Add bottom border
+	formatted.WriteString("└" + strings.Repeat("─", 60) + "┘")
+
+	return formatted.String()
+}
@@ -0,0 +1,217 @@
+package trainer
+
+import (
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// TimeScope says which limit a timing event is about
+type TimeScope int
+
+const (
+	SessionTime  TimeScope = iota // TrainerConfig.TimeLimit for one sitting
+	ExerciseTime                  // The current exercise's share of its estimate
+)
+
+// String returns a readable scope name
+func (s TimeScope) String() string {
+	if s == ExerciseTime {
+		return "exercise"
+	}
+	return "session"
+}
+
+// inputRead is the result of one Input call made in the background
+type inputRead struct {
+	text string
+	eof  bool
+}
+
+// timeUp reports which limit expired while waiting for input, or that the
+// trainer was interrupted
+type timeUp struct {
+	expired     bool
+	scope       TimeScope
+	interrupted bool
+}
+
+// stopped reports whether waiting for input ended without any input
+func (u timeUp) stopped() bool {
+	return u.expired || u.interrupted
+}
+
+// timers tracks the session and exercise deadlines and the warnings already given
+type timers struct {
+	sessionDeadline  time.Time // Zero when there is no session limit
+	exerciseDeadline time.Time // Zero when there is no exercise limit
+	warned           map[TimeScope]map[time.Duration]bool
+}
+
+// sessionRemaining returns the time left in the session, or 0 without a limit
+func (t *CLTTrainer) sessionRemaining() time.Duration {
+	return remaining(t.timers.sessionDeadline)
+}
+
+// exerciseRemaining returns the time left for the exercise, or 0 without a limit
+func (t *CLTTrainer) exerciseRemaining() time.Duration {
+	return remaining(t.timers.exerciseDeadline)
+}
+
+// remaining returns the time until deadline, at least a nanosecond so that
+// an expired limit is not mistaken for no limit
+func remaining(deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return 0
+	}
+	return max(time.Until(deadline), time.Nanosecond)
+}
+
+// startSessionTimer sets the session deadline. The limit applies to each
+// sitting, so a session paused by its limit can always be resumed.
+func (t *CLTTrainer) startSessionTimer() {
+	t.runStart = time.Now()
+	t.timers.warned = map[TimeScope]map[time.Duration]bool{}
+	if t.config.TimeLimit > 0 {
+		t.timers.sessionDeadline = t.runStart.Add(t.config.TimeLimit)
+	}
+}
+
+// startExerciseTimer sets the exercise deadline from its estimated time,
+// less any time already spent on it before a pause
+func (t *CLTTrainer) startExerciseTimer(exercise models.Exercise) {
+	t.timers.exerciseDeadline = time.Time{}
+	delete(t.timers.warned, ExerciseTime)
+	if t.config.ExerciseTimeFactor > 0 && exercise.EstimatedTime > 0 {
+		limit := time.Duration(float64(exercise.EstimatedTime) * float64(time.Minute) * t.config.ExerciseTimeFactor)
+		t.timers.exerciseDeadline = time.Now().Add(limit - t.progress[t.current].TimeSpent)
+	}
+}
+
+// activeTime returns the time spent training across every run of the session
+func (t *CLTTrainer) activeTime() time.Duration {
+	return t.activeBefore + time.Since(t.runStart)
+}
+
+// await runs read in the background so deadlines, warnings and interrupts
+// take effect even while the learner is idle. A read cut short by a deadline is kept and
+// handed to the next call instead of being lost.
+func (t *CLTTrainer) await(read func() (string, bool)) (string, bool, timeUp) {
+	if t.pending == nil {
+		pending := make(chan inputRead, 1)
+		go func() {
+			text, eof := read()
+			pending <- inputRead{text: text, eof: eof}
+		}()
+		t.pending = pending
+	}
+
+	for {
+		if scope, expired := t.expired(); expired {
+			return "", false, timeUp{expired: true, scope: scope}
+		}
+		t.warn()
+
+		var timer *time.Timer
+		var tick <-chan time.Time
+		if wait := t.untilNextEvent(); wait > 0 {
+			timer = time.NewTimer(wait)
+			tick = timer.C
+		}
+
+		select {
+		case <-t.interrupts:
+			if timer != nil {
+				timer.Stop()
+			}
+			return "", false, timeUp{interrupted: true}
+		case result := <-t.pending:
+			t.pending = nil
+			if timer != nil {
+				timer.Stop()
+			}
+			return result.text, result.eof, timeUp{}
+		case <-tick:
+		}
+	}
+}
+
+// expired reports the first limit that has run out
+func (t *CLTTrainer) expired() (TimeScope, bool) {
+	now := time.Now()
+	if !t.timers.sessionDeadline.IsZero() && !now.Before(t.timers.sessionDeadline) {
+		return SessionTime, true
+	}
+	if !t.timers.exerciseDeadline.IsZero() && !now.Before(t.timers.exerciseDeadline) {
+		return ExerciseTime, true
+	}
+	return 0, false
+}
+
+// warn presents each configured warning once, when its threshold is crossed
+func (t *CLTTrainer) warn() {
+	for _, scope := range []TimeScope{SessionTime, ExerciseTime} {
+		left := t.remainingFor(scope)
+		if left == 0 {
+			continue
+		}
+		// Only the tightest threshold crossed is shown; wider ones are marked seen
+		var crossed time.Duration
+		for _, threshold := range t.config.TimeWarnings {
+			if left <= threshold && !t.warnedAt(scope, threshold) {
+				t.markWarned(scope, threshold)
+				if crossed == 0 || threshold < crossed {
+					crossed = threshold
+				}
+			}
+		}
+		if crossed > 0 {
+			t.presenter.TimeWarning(scope, left)
+		}
+	}
+}
+
+// untilNextEvent returns how long until the next deadline or warning, or 0
+// if nothing is scheduled
+func (t *CLTTrainer) untilNextEvent() time.Duration {
+	var next time.Duration
+	consider := func(d time.Duration) {
+		if d > 0 && (next == 0 || d < next) {
+			next = d
+		}
+	}
+	for _, scope := range []TimeScope{SessionTime, ExerciseTime} {
+		left := t.remainingFor(scope)
+		if left == 0 {
+			continue
+		}
+		consider(left)
+		for _, threshold := range t.config.TimeWarnings {
+			if !t.warnedAt(scope, threshold) {
+				consider(left - threshold)
+			}
+		}
+	}
+	return next
+}
+
+// remainingFor returns the time left under one limit, or 0 without a limit
+func (t *CLTTrainer) remainingFor(scope TimeScope) time.Duration {
+	if scope == ExerciseTime {
+		return t.exerciseRemaining()
+	}
+	return t.sessionRemaining()
+}
+
+// warnedAt reports whether a warning threshold has already been shown
+func (t *CLTTrainer) warnedAt(scope TimeScope, threshold time.Duration) bool {
+	return t.timers.warned[scope][threshold]
+}
+
+// markWarned records that a warning threshold has been shown
+func (t *CLTTrainer) markWarned(scope TimeScope, threshold time.Duration) {
+	if t.timers.warned[scope] == nil {
+		t.timers.warned[scope] = map[time.Duration]bool{}
+	}
+	t.timers.warned[scope][threshold] = true
+}
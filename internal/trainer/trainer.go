@@ -1,242 +1,704 @@
 package trainer
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/cmyers78/claude/internal/adaptive"
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/mastery"
 	"github.com/cmyers78/claude/internal/models"
 	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/validation"
 )
 
 // CLTTrainer implements Cognitive Load Theory principles
 type CLTTrainer struct {
 	config     models.TrainerConfig
 	exercises  []models.Exercise
+	curriculum *exercises.Graph // Decides which exercise comes next
 	progress   []models.LearningProgress
 	current    int
 	startTime  time.Time
 	sessionID  string
 	userID     string
 	storage    storage.SessionStorage
+	presenter  Presenter
+	input      Input
+	resumedAt  *time.Time       // When the resumed session was paused, if it was
+	unlock     func() error     // Releases the resumed session's lock
+	engine     *adaptive.Engine // Nil unless AdaptivePacing is on
+	mastery    *mastery.Model
+	decisions  []models.AdaptiveDecision
+
+	// Time-boxing: active time from earlier runs, when this run began, the
+	// current deadlines and a read still waiting for the learner
+	activeBefore time.Duration
+	runStart     time.Time
+	timers       timers
+	pending      chan inputRead
+
+	exerciseStart time.Time // When work on the current exercise resumed in this run
+
+	interrupts    chan struct{} // Signals or Interrupt calls asking to pause and stop
+	ignoreSignals bool          // Leave SIGINT and SIGTERM to the caller
+
+	listeners []Listener
+	stored    bool // The session is in storage, so quitting marks it abandoned
 }
 
 // NewCLTTrainer creates a new trainer with CLT principles
 func NewCLTTrainer(exercises []models.Exercise, config models.TrainerConfig, userID string, sessionStorage storage.SessionStorage) *CLTTrainer {
 	return &CLTTrainer{
-		config:    config,
-		exercises: exercises,
-		progress:  make([]models.LearningProgress, len(exercises)),
-		current:   0,
-		startTime: time.Now(),
-		userID:    userID,
-		storage:   sessionStorage,
+		config:     config,
+		exercises:  exercises,
+		curriculum: newCurriculum(exercises),
+		engine:     newEngine(config),
+		mastery:    newMastery(config, nil),
+		progress:   make([]models.LearningProgress, len(exercises)),
+		current:    0,
+		startTime:  time.Now(),
+		userID:     userID,
+		storage:    sessionStorage,
+		interrupts: make(chan struct{}, 1),
 	}
 }
 
 // NewCLTTrainerFromSession creates a trainer from a saved session
 func NewCLTTrainerFromSession(session *models.TrainingSession, exercises []models.Exercise, sessionStorage storage.SessionStorage) *CLTTrainer {
 	return &CLTTrainer{
-		config:    session.Config,
-		exercises: exercises,
-		progress:  session.Progress,
-		current:   session.CurrentIndex,
-		startTime: session.StartTime,
-		sessionID: session.SessionID,
-		userID:    session.UserID,
-		storage:   sessionStorage,
+		config:       session.Config,
+		exercises:    exercises,
+		curriculum:   newCurriculum(exercises),
+		engine:       newEngine(session.Config),
+		mastery:      newMastery(session.Config, session.Mastery),
+		decisions:    session.Decisions,
+		progress:     padProgress(session.Progress, len(exercises)),
+		current:      session.CurrentIndex,
+		startTime:    session.StartTime,
+		activeBefore: session.ActiveTime,
+		sessionID:    session.SessionID,
+		userID:       session.UserID,
+		storage:      sessionStorage,
+		interrupts:   make(chan struct{}, 1),
+		stored:       true,
 	}
 }
 
+// SetIO replaces the terminal on os.Stdin and os.Stdout with another front-end
+func (t *CLTTrainer) SetIO(presenter Presenter, input Input) {
+	t.presenter = presenter
+	t.input = input
+}
+
+// Level returns the learner's cognitive level, as adaptive pacing has left it
+func (t *CLTTrainer) Level() models.CognitiveLevel {
+	return t.config.CognitiveLoad
+}
+
 // Start begins the training session with CLT-informed pacing
 func (t *CLTTrainer) Start() {
-	t.showWelcome()
-	
-	reader := bufio.NewReader(os.Stdin)
-	
-	for t.current < len(t.exercises) {
+	if t.presenter == nil || t.input == nil {
+		terminal := NewTerminal(os.Stdin, os.Stdout)
+		t.SetIO(terminal, terminal)
+	}
+	defer t.release()
+	defer t.saveOnPanic()
+	if !t.ignoreSignals {
+		defer t.watchSignals()()
+	}
+	t.startSessionTimer()
+
+	t.presenter.Welcome()
+	if t.resumedAt != nil && t.current < len(t.exercises) {
+		challenge := 0
+		if t.inProgress(t.current) {
+			challenge = t.nextChallenge() + 1
+		}
+		t.presenter.Resumed(*t.resumedAt, t.current+1, len(t.exercises), t.exercises[t.current], challenge)
+	}
+	if t.resumedAt != nil {
+		t.emit(SessionResumed)
+	} else {
+		t.emit(SessionStarted)
+	}
+
+	for {
+		next, ok := t.nextExercise()
+		if !ok {
+			// Curriculum finished
+			if t.storage != nil {
+				if err := t.saveSession(models.SessionCompleted); err != nil {
+					t.presenter.Error(fmt.Errorf("Error saving session: %w", err))
+				}
+			}
+			t.emit(SessionCompleted)
+			break
+		}
+		t.current = next
 		exercise := t.exercises[t.current]
-		t.startExercise(exercise)
-		
+		if !t.inProgress(t.current) {
+			t.startExercise(exercise)
+		}
+		t.exerciseStart = time.Now()
+		t.startExerciseTimer(exercise)
+
 		// Show learning goals first (reduce extraneous load)
-		t.showLearningGoals(exercise)
-		
+		t.presenter.LearningGoals(exercise)
+
 		// Progressive disclosure: examples before challenges
-		t.showExamples(exercise)
-		
+		t.presenter.Examples(exercise)
+
 		// Wait for learner to process examples
-		fmt.Print("\nPress Enter when ready to try the challenges...")
-		reader.ReadString('\n')
-		
-		// Present challenges with faded guidance
-		completed := t.runChallenges(exercise, reader)
-		
+		t.presenter.ReadyPrompt()
+		completed := true
+		if _, _, up := t.await(t.input.ReadLine); up.stopped() {
+			completed = t.handleTimeUp(up)
+		} else {
+			// Present challenges with faded guidance
+			completed = t.runChallenges(exercise)
+		}
+
 		if completed {
 			t.completeExercise(exercise)
-			t.current++
+			t.adjustLevel(exercise)
+			t.showUnlocked(exercise)
+			t.checkpoint()
 		} else {
 			break // User quit
 		}
 	}
-	
-	t.showFinalResults()
-}
-
-// showWelcome introduces the training with clear expectations
-func (t *CLTTrainer) showWelcome() {
-	fmt.Println("🧠 Go Trainer with Cognitive Load Theory")
-	fmt.Println("=========================================")
-	fmt.Println()
-	fmt.Println("This trainer uses proven learning science principles:")
-	fmt.Println("• Worked examples before practice")
-	fmt.Println("• Progressive disclosure of complexity")
-	fmt.Println("• Multiple practice opportunities")
-	fmt.Println("• Adaptive pacing based on your progress")
-	fmt.Println()
-	fmt.Println("Commands: 'hint', 'skip', 'pause', 'quit', 'help'")
-	fmt.Println()
-}
-
-// showLearningGoals clearly states what the learner will achieve
-func (t *CLTTrainer) showLearningGoals(exercise models.Exercise) {
-	fmt.Printf("📋 %s\n", exercise.Title)
-	fmt.Printf("Description: %s\n\n", exercise.Description)
-	
-	fmt.Println("🎯 Learning Goals:")
-	for i, goal := range exercise.LearningGoals {
-		fmt.Printf("   %d. %s\n", i+1, goal)
-	}
-	fmt.Println()
-	
-	if len(exercise.Prerequisites) > 0 {
-		fmt.Println("📚 Prerequisites:")
-		for _, prereq := range exercise.Prerequisites {
-			fmt.Printf("   • %s\n", prereq)
-		}
-		fmt.Println()
-	}
-	
-	fmt.Printf("⏱️  Estimated time: %d minutes\n\n", exercise.EstimatedTime)
-}
-
-// showExamples implements worked example effect
-func (t *CLTTrainer) showExamples(exercise models.Exercise) {
-	fmt.Println("📖 Examples (Study these carefully):")
-	fmt.Println("=====================================")
-	
-	for i, example := range exercise.Examples {
-		fmt.Printf("\n%d. %s\n", i+1, example.Title)
-		fmt.Println(strings.Repeat("-", len(example.Title)+3))
-		
-		fmt.Printf("Code:\n%s\n\n", t.FormatCodeBlock(example.Code))
-		fmt.Printf("Explanation: %s\n", example.Explanation)
-		
-		if example.Output != "" {
-			fmt.Printf("Output: %s\n", example.Output)
-		}
-		
-		fmt.Println()
-	}
-}
-
-// runChallenges implements faded guidance and completion effect
-func (t *CLTTrainer) runChallenges(exercise models.Exercise, reader *bufio.Reader) bool {
-	fmt.Println("🎯 Practice Challenges:")
-	fmt.Println("======================")
-	
-	for i, challenge := range exercise.Challenges {
-		fmt.Printf("\nChallenge %d/%d\n", i+1, len(exercise.Challenges))
-		fmt.Println(strings.Repeat("-", 15))
-		
-		completed, attempts, hintsUsed := t.runSingleChallenge(challenge, i, reader)
-		
-		// Aggregate progress for the exercise
-		t.progress[t.current].Attempts += attempts
-		t.progress[t.current].HintsUsed += hintsUsed
-		
-		if !completed {
-			return false // User quit
-		}
-	}
-	
-	return true
-}
-
-// runSingleChallenge handles individual challenge with adaptive support
-func (t *CLTTrainer) runSingleChallenge(challenge models.Challenge, challengeNum int, reader *bufio.Reader) (bool, int, int) {
-	fmt.Printf("Task: %s\n\n", challenge.Description)
-	fmt.Printf("Template:\n%s\n\n", t.FormatCodeBlock(challenge.Template))
-	
-	attempts := 0
-	hintsUsed := 0
-	
-	for attempts < t.config.MaxAttempts {
-		fmt.Print("Your solution: ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-		
+
+	t.presenter.Results(t.summary())
+}
+
+// release gives up the session lock once training ends
+func (t *CLTTrainer) release() {
+	if err := t.Release(); err != nil {
+		t.presenter.Error(fmt.Errorf("Error releasing session: %w", err))
+	}
+}
+
+// Release gives up the session lock taken when the session was resumed or
+// first saved. Start releases it when training ends; front-ends call it
+// for a session they resumed but couldn't start.
+func (t *CLTTrainer) Release() error {
+	if t.unlock == nil {
+		return nil
+	}
+	err := t.unlock()
+	t.unlock = nil
+	return err
+}
+
+// newEngine creates the adaptive pacing engine if the config asks for one
+func newEngine(config models.TrainerConfig) *adaptive.Engine {
+	if !config.AdaptivePacing {
+		return nil
+	}
+	return adaptive.New(config.CognitiveLoad)
+}
+
+// padProgress makes room for exercises added since a session was saved
+func padProgress(progress []models.LearningProgress, n int) []models.LearningProgress {
+	for len(progress) < n {
+		progress = append(progress, models.LearningProgress{})
+	}
+	return progress
+}
+
+// newCurriculum builds the prerequisite graph for the trainer's exercises.
+// Prerequisites outside the list are treated as already met, so a trainer
+// can be given part of a curriculum. It returns nil if the prerequisites
+// form a cycle, in which case exercises are taken in list order.
+func newCurriculum(list []models.Exercise) *exercises.Graph {
+	present := make(map[string]bool, len(list))
+	for _, exercise := range list {
+		present[exercise.ID] = true
+	}
+
+	scoped := make([]models.Exercise, len(list))
+	for i, exercise := range list {
+		exercise.Prerequisites = nil
+		for _, prereq := range list[i].Prerequisites {
+			if present[prereq] {
+				exercise.Prerequisites = append(exercise.Prerequisites, prereq)
+			}
+		}
+		scoped[i] = exercise
+	}
+
+	graph, err := exercises.NewGraph(scoped)
+	if err != nil {
+		return nil
+	}
+	return graph
+}
+
+// nextExercise picks the index of the exercise to work on: the one already
+// in progress, or else the first whose prerequisites are all completed
+func (t *CLTTrainer) nextExercise() (int, bool) {
+	if t.current < len(t.exercises) && t.inProgress(t.current) {
+		return t.current, true
+	}
+
+	completed := t.completedIDs()
+	if t.curriculum != nil {
+		id, ok := t.curriculum.Next(completed)
+		if !ok {
+			return 0, false
+		}
+		return t.exerciseIndex(id), true
+	}
+
+	for i, progress := range t.progress {
+		if progress.CompletedAt == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// completedIDs lists the exercises finished so far
+func (t *CLTTrainer) completedIDs() []string {
+	var ids []string
+	for i, progress := range t.progress {
+		if progress.CompletedAt != nil {
+			ids = append(ids, t.exercises[i].ID)
+		}
+	}
+	return ids
+}
+
+// exerciseIndex returns the position of an exercise in the trainer's list
+func (t *CLTTrainer) exerciseIndex(id string) int {
+	for i, exercise := range t.exercises {
+		if exercise.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// showUnlocked tells the learner which exercises completing this one opened up
+func (t *CLTTrainer) showUnlocked(exercise models.Exercise) {
+	if t.curriculum == nil {
+		return
+	}
+	before := slices.DeleteFunc(t.completedIDs(), func(id string) bool { return id == exercise.ID })
+	var unlocked []models.Exercise
+	for _, id := range t.curriculum.Unlocks(exercise.ID, before) {
+		unlocked = append(unlocked, t.exercises[t.exerciseIndex(id)])
+	}
+	if len(unlocked) > 0 {
+		t.presenter.Unlocked(unlocked)
+	}
+}
+
+// runChallenges implements faded guidance and completion effect. A resumed
+// exercise continues at the first challenge not yet finished.
+func (t *CLTTrainer) runChallenges(exercise models.Exercise) bool {
+	t.presenter.ChallengesHeader()
+
+	// Rebuild pacing state from challenges finished before a pause
+	var results []adaptive.ChallengeResult
+	var posed []int
+	for _, done := range t.progress[t.current].Challenges {
+		if done.CompletedAt != nil {
+			results = append(results, adaptive.ChallengeResult{Attempts: done.Attempts, HintsUsed: done.HintsUsed, Solved: done.Solved})
+			posed = append(posed, done.Challenge)
+		}
+	}
+	supportShown := t.supportShown(exercise)
+
+	for i := t.nextChallenge(); i < len(exercise.Challenges); i++ {
+		challenge := exercise.Challenges[i]
+		t.presenter.Challenge(t.offered(challenge), i+1, len(exercise.Challenges))
+
+		entry := t.challengeProgress(i)
+		outcome := t.runSingleChallenge(challenge, i, *entry)
+
+		// Aggregate progress for the challenge and the exercise
+		tally(entry, outcome)
+		t.progress[t.current].Attempts += outcome.attempts
+		t.progress[t.current].HintsUsed += outcome.hintsUsed
+		t.progress[t.current].CompileErrors += outcome.compileErrors
+		t.progress[t.current].WrongAnswers += outcome.wrongAnswers
+		t.progress[t.current].AttemptHistory = append(t.progress[t.current].AttemptHistory, outcome.history...)
+		for a := range outcome.history {
+			t.emitExercise(AnswerSubmitted, &outcome.history[a])
+		}
+
+		if !outcome.completed {
+			t.stop(outcome)
+			return false
+		}
+		t.checkpoint()
+		if outcome.timedOut {
+			return true // Exercise time is up; remaining challenges are skipped
+		}
+		t.observe(challenge, *entry)
+		posed = append(posed, i)
+
+		if t.engine == nil {
+			continue
+		}
+		result := adaptive.ChallengeResult{Attempts: entry.Attempts, HintsUsed: entry.HintsUsed, Solved: entry.Solved}
+		results = append(results, result)
+		remaining := len(exercise.Challenges) - i - 1
+
+		// Fluent learners skip to the final challenge; struggling ones get another worked example
+		if skip, reason := t.engine.Skip(results, remaining); skip > 0 {
+			t.decide(models.AdaptiveDecision{ExerciseID: exercise.ID, Challenge: i, Action: models.ActionSkipChallenges, Reason: reason, Skipped: skip})
+			i += skip
+		} else if reason, ok := t.engine.Support(result); ok && remaining > 0 && supportShown < len(exercise.SupportExamples) {
+			example := exercise.SupportExamples[supportShown]
+			supportShown++
+			t.decide(models.AdaptiveDecision{ExerciseID: exercise.ID, Challenge: i, Action: models.ActionExtraExample, Reason: reason, Example: example.Title})
+			t.presenter.ExtraExample(example)
+		}
+	}
+
+	return t.practice(exercise, posed)
+}
+
+// adjustLevel lets adaptive pacing move the learner's cognitive level after
+// an exercise, based on the exercises completed so far
+func (t *CLTTrainer) adjustLevel(exercise models.Exercise) {
+	if t.engine == nil {
+		return
+	}
+
+	var completed []int
+	for i, progress := range t.progress {
+		if progress.CompletedAt != nil {
+			completed = append(completed, i)
+		}
+	}
+	slices.SortStableFunc(completed, func(a, b int) int {
+		return t.progress[a].CompletedAt.Compare(*t.progress[b].CompletedAt)
+	})
+	recent := make([]adaptive.Performance, len(completed))
+	for i, index := range completed {
+		recent[i] = adaptive.PerformanceOf(t.exercises[index], t.progress[index])
+	}
+
+	if action, reason, changed := t.engine.Adjust(recent); changed {
+		t.config.CognitiveLoad = t.engine.Level
+		t.decide(models.AdaptiveDecision{ExerciseID: exercise.ID, Challenge: -1, Action: action, Reason: reason})
+	}
+}
+
+// decide records an adaptive decision in the session and tells the learner
+func (t *CLTTrainer) decide(decision models.AdaptiveDecision) {
+	decision.At = time.Now()
+	decision.Level = t.engine.Level
+	t.decisions = append(t.decisions, decision)
+	t.presenter.Adapted(decision)
+}
+
+// challengeOutcome summarizes how a learner worked through one challenge in
+// this sitting
+type challengeOutcome struct {
+	completed     bool
+	solved        bool // Answered correctly, rather than skipped or revealed
+	skipped       bool // The learner asked to skip to the solution
+	paused        bool // The learner paused, input closed, the session ran out of time or the trainer was interrupted
+	quit          bool // The learner quit
+	attempts      int
+	hintsUsed     int
+	compileErrors int
+	wrongAnswers  int
+	timedOut      bool // The exercise ran out of time during this challenge
+	history       []models.AttemptRecord
+}
+
+// offered returns a challenge as the learner is shown it, without hints
+// when they are turned off
+func (t *CLTTrainer) offered(challenge models.Challenge) models.Challenge {
+	if !t.config.ShowHints {
+		challenge.Hints = nil
+	}
+	return challenge
+}
+
+// runSingleChallenge handles individual challenge with adaptive support.
+// Attempts and hints from before a pause, in prior, carry over.
+func (t *CLTTrainer) runSingleChallenge(challenge models.Challenge, challengeNum int, prior models.ChallengeProgress) challengeOutcome {
+	outcome := challengeOutcome{}
+
+	// stop ends the challenge when a limit expires or the trainer is
+	// interrupted while waiting for input
+	stop := func(up timeUp) challengeOutcome {
+		if up.interrupted {
+			t.presenter.Interrupted()
+			outcome.paused = true
+			return outcome
+		}
+		t.presenter.TimeUp(up.scope)
+		if up.scope == SessionTime {
+			outcome.paused = true
+			return outcome
+		}
+		t.presenter.Solution(OutOfTime, challenge.Solution)
+		outcome.completed = true
+		outcome.timedOut = true
+		return outcome
+	}
+
+	for prior.Attempts+outcome.attempts < t.config.MaxAttempts {
+		if t.sessionRemaining() > 0 || t.exerciseRemaining() > 0 {
+			t.presenter.TimeRemaining(t.sessionRemaining(), t.exerciseRemaining())
+		}
+		t.presenter.AnswerPrompt()
+		input, eof, up := t.await(t.input.ReadAnswer)
+		if up.stopped() {
+			return stop(up)
+		}
+		if eof && input == "" {
+			// Input closed; nothing more can be answered, so keep what was done
+			input = "quit"
+			if t.storage != nil {
+				input = "pause"
+			}
+		}
+
+		// Multi-line answers: typed until a sentinel line, or written in an editor
+		switch strings.ToLower(input) {
+		case "multi":
+			t.presenter.MultilineInstructions()
+			input, _, up = t.await(t.input.ReadMultiline)
+			if up.stopped() {
+				return stop(up)
+			}
+			if input == "" {
+				continue
+			}
+		case "edit":
+			edited, err := t.input.Edit(challenge.Template)
+			if err != nil {
+				t.presenter.Error(err)
+				continue
+			}
+			if edited == strings.TrimSpace(challenge.Template) {
+				t.presenter.EditUnchanged()
+				continue
+			}
+			t.presenter.Submitting(edited)
+			input = edited
+		}
+
 		switch strings.ToLower(input) {
 		case "quit":
-			return false, attempts, hintsUsed
+			outcome.quit = true
+			return outcome
 		case "pause":
-			if err := t.pauseSession(); err != nil {
-				fmt.Printf("❌ Error saving session: %v\n", err)
-			} else {
-				fmt.Println("💾 Session saved! Use 'claude trainer resume' to continue later.")
-			}
-			return false, attempts, hintsUsed
+			outcome.paused = true
+			return outcome
 		case "help":
-			t.showHelp()
+			t.presenter.Help()
 			continue
 		case "hint":
-			if hintsUsed < len(challenge.Hints) {
-				fmt.Printf("💡 Hint: %s\n", challenge.Hints[hintsUsed])
-				hintsUsed++
+			if !t.config.ShowHints {
+				t.presenter.HintsOff()
+			} else if hint := prior.HintsUsed + outcome.hintsUsed; hint < len(challenge.Hints) {
+				t.presenter.Hint(challenge.Hints[hint])
+				outcome.hintsUsed++
 			} else {
-				fmt.Printf("💡 Solution: %s\n", challenge.Solution)
+				t.presenter.Solution(HintsExhausted, challenge.Solution)
 			}
 			continue
 		case "skip":
-			fmt.Printf("⏭️  Skipped. Solution: %s\n", challenge.Solution)
-			return true, attempts, hintsUsed
+			t.presenter.Solution(Skipped, challenge.Solution)
+			outcome.skipped = true
+			outcome.completed = true
+			return outcome
 		default:
-			attempts++
-			if challenge.Validator(input) {
-				fmt.Println("✅ Excellent! That's correct!")
-				
+			outcome.attempts++
+			attempts := prior.Attempts + outcome.attempts
+			passed, result := t.evaluateAnswer(challenge, input)
+			record := models.AttemptRecord{
+				Challenge:   challengeNum,
+				Attempt:     attempts,
+				SubmittedAt: time.Now(),
+				Answer:      input,
+				Passed:      passed,
+				FailedTests: failedTests(result),
+			}
+			if passed {
+				outcome.history = append(outcome.history, record)
+
 				// Provide elaborative feedback for learning
-				if attempts == 1 && hintsUsed == 0 {
-					fmt.Println("🌟 Perfect on first try!")
-				} else if attempts <= 2 {
-					fmt.Println("👍 Good work!")
-				} else {
-					fmt.Println("💪 Great persistence!")
-				}
-				return true, attempts, hintsUsed
+				t.presenter.Correct(attempts, prior.HintsUsed+outcome.hintsUsed)
+				outcome.solved = true
+				outcome.completed = true
+				return outcome
+			}
+
+			// Separate code that doesn't compile from code that compiles but misses the goal
+			_, diagnostics := validation.Compile(challenge.Template, input)
+			record.CompileError = len(diagnostics) > 0
+			outcome.history = append(outcome.history, record)
+			if record.CompileError {
+				outcome.compileErrors++
+				t.presenter.CompileErrors(diagnostics)
 			} else {
-				t.provideAdaptiveFeedback(attempts, challengeNum, challenge)
+				outcome.wrongAnswers++
+				if !t.showRunFeedback(result) {
+					t.presenter.Retry(attempts, t.config.ShowHints)
+				}
 			}
 		}
 	}
-	
-	fmt.Printf("Max attempts reached. Solution: %s\n", challenge.Solution)
-	return true, attempts, hintsUsed
+
+	t.presenter.Solution(OutOfAttempts, challenge.Solution)
+	outcome.completed = true
+	return outcome
+}
+
+// nextChallenge returns the index of the challenge to continue the current
+// exercise from: the one left unfinished, or the one after the last finished
+func (t *CLTTrainer) nextChallenge() int {
+	entries := t.progress[t.current].Challenges
+	for _, entry := range entries {
+		if entry.CompletedAt == nil {
+			return entry.Challenge
+		}
+	}
+	if len(entries) == 0 {
+		return 0
+	}
+	return entries[len(entries)-1].Challenge + 1
+}
+
+// challengeProgress returns the current exercise's entry for a challenge,
+// starting one if the challenge hasn't been posed yet
+func (t *CLTTrainer) challengeProgress(challenge int) *models.ChallengeProgress {
+	progress := &t.progress[t.current]
+	for i := range progress.Challenges {
+		if progress.Challenges[i].Challenge == challenge {
+			return &progress.Challenges[i]
+		}
+	}
+	progress.Challenges = append(progress.Challenges, models.ChallengeProgress{Challenge: challenge, StartedAt: time.Now()})
+	return &progress.Challenges[len(progress.Challenges)-1]
+}
+
+// tally adds a challenge outcome to the challenge's progress
+func tally(entry *models.ChallengeProgress, outcome challengeOutcome) {
+	entry.Attempts += outcome.attempts
+	entry.HintsUsed += outcome.hintsUsed
+	entry.Solved = outcome.solved
+	entry.Skipped = outcome.skipped
+	if outcome.completed {
+		now := time.Now()
+		entry.CompletedAt = &now
+	}
+}
+
+// supportShown counts the support examples already shown for an exercise
+func (t *CLTTrainer) supportShown(exercise models.Exercise) int {
+	shown := 0
+	for _, decision := range t.decisions {
+		if decision.ExerciseID == exercise.ID && decision.Action == models.ActionExtraExample {
+			shown++
+		}
+	}
+	return shown
+}
+
+// handleTimeUp responds to a limit that expired, or an interrupt, outside a
+// challenge. When the session runs out or is interrupted it is paused and
+// saved, and false is returned to stop training; when only the exercise runs
+// out, true is returned so training moves on.
+func (t *CLTTrainer) handleTimeUp(up timeUp) bool {
+	if up.interrupted {
+		t.presenter.Interrupted()
+		t.pause()
+		return false
+	}
+	t.presenter.TimeUp(up.scope)
+	if up.scope == ExerciseTime {
+		return true
+	}
+	t.pause()
+	return false
 }
 
-// provideAdaptiveFeedback gives targeted help based on CLT principles
-func (t *CLTTrainer) provideAdaptiveFeedback(attempts int, challengeNum int, challenge models.Challenge) {
-	if attempts == 1 {
-		// First mistake: gentle guidance
-		fmt.Println("❌ Not quite right. Compare your answer with the examples above.")
-	} else if attempts == 2 {
-		// Second mistake: more specific help
-		fmt.Println("❌ Still not correct. Type 'hint' for guidance, or review the examples.")
+// pause saves the session as paused and tells the learner how it went
+func (t *CLTTrainer) pause() {
+	if err := t.pauseSession(); err != nil {
+		t.presenter.Error(fmt.Errorf("Error saving session: %w", err))
 	} else {
-		// Multiple mistakes: direct support
-		fmt.Println("❌ Let's break this down. Type 'hint' for step-by-step help.")
+		t.presenter.SessionSaved()
+	}
+	t.emit(SessionPaused)
+}
+
+// evaluateAnswer checks an answer against the challenge's rules, falling back
+// to a hand-written Validator for challenges without rules
+func (t *CLTTrainer) evaluateAnswer(challenge models.Challenge, input string) (bool, *validation.Result) {
+	checks := challenge.Checks()
+	if len(checks) == 0 {
+		return challenge.Validator != nil && challenge.Validator(input), nil
+	}
+	result := validation.Evaluate(challenge.Template, input, checks...)
+	return result.Passed(), result
+}
+
+// showRunFeedback explains why a compiling program behaved incorrectly.
+// It returns false when the failure wasn't about running the program.
+func (t *CLTTrainer) showRunFeedback(result *validation.Result) bool {
+	if result == nil {
+		return false
+	}
+	for _, failure := range result.Failures {
+		var mismatch *validation.OutputMismatch
+		var runErr *validation.RunError
+		var testFailures *validation.TestFailures
+		switch {
+		case errors.As(failure, &testFailures):
+			t.presenter.TestFailures(testFailures)
+			return true
+		case errors.As(failure, &mismatch):
+			t.presenter.OutputMismatch(mismatch.Diff())
+			return true
+		case errors.As(failure, &runErr):
+			t.presenter.RunError(runErr)
+			return true
+		}
 	}
+	return false
+}
+
+// failedTests lists the hidden tests an evaluated answer failed, if any
+func failedTests(result *validation.Result) []string {
+	if result == nil {
+		return nil
+	}
+	for _, failure := range result.Failures {
+		var testFailures *validation.TestFailures
+		if errors.As(failure, &testFailures) {
+			return testFailures.Report.FailedNames()
+		}
+	}
+	return nil
+}
+
+// inProgress reports whether an exercise was started but not completed
+func (t *CLTTrainer) inProgress(index int) bool {
+	progress := t.progress[index]
+	return !progress.StartTime.IsZero() && progress.CompletedAt == nil
+}
+
+// exerciseTime returns the time spent on the current exercise across every
+// sitting
+func (t *CLTTrainer) exerciseTime() time.Duration {
+	return t.progress[t.current].TimeSpent + time.Since(t.exerciseStart)
 }
 
 // startExercise initializes tracking for an exercise
@@ -248,30 +710,30 @@ func (t *CLTTrainer) startExercise(exercise models.Exercise) {
 		Score:      0.0,
 		HintsUsed:  0,
 	}
+	t.emitExercise(ExerciseStarted, nil)
 }
 
 // completeExercise finalizes tracking for an exercise
 func (t *CLTTrainer) completeExercise(exercise models.Exercise) {
 	now := time.Now()
 	t.progress[t.current].CompletedAt = &now
-	t.progress[t.current].TimeSpent = now.Sub(t.progress[t.current].StartTime)
-	
+	t.progress[t.current].TimeSpent = t.exerciseTime()
+
 	// Calculate score based on CLT principles
 	t.progress[t.current].Score = t.calculateScore(exercise)
-	
-	fmt.Printf("✅ %s completed!\n", exercise.Title)
-	fmt.Printf("Time spent: %.1f minutes\n", t.progress[t.current].TimeSpent.Minutes())
-	fmt.Printf("Score: %.1f/100\n\n", t.progress[t.current].Score)
+
+	t.presenter.ExerciseCompleted(exercise, t.progress[t.current])
+	t.emitExercise(ExerciseCompleted, nil)
 }
 
 // calculateScore implements CLT-based scoring algorithm
 func (t *CLTTrainer) calculateScore(exercise models.Exercise) float64 {
 	progress := t.progress[t.current]
 	numChallenges := len(exercise.Challenges)
-	
+
 	// Base score for completion
 	baseScore := 60.0
-	
+
 	// Bonus for efficiency (fewer attempts relative to max possible)
 	maxPossibleAttempts := numChallenges * t.config.MaxAttempts
 	if maxPossibleAttempts > 0 {
@@ -279,7 +741,7 @@ func (t *CLTTrainer) calculateScore(exercise models.Exercise) float64 {
 		efficiencyBonus := efficiencyRatio * 30.0 // Up to 30 points for efficiency
 		baseScore += efficiencyBonus
 	}
-	
+
 	// Penalty for excessive hint usage
 	if progress.HintsUsed > 0 {
 		// Lose 2 points per hint, but cap the penalty
@@ -289,7 +751,7 @@ func (t *CLTTrainer) calculateScore(exercise models.Exercise) float64 {
 		}
 		baseScore -= hintPenalty
 	}
-	
+
 	// Bonus for fast completion (relative to estimated time)
 	estimatedMinutes := float64(exercise.EstimatedTime)
 	actualMinutes := progress.TimeSpent.Minutes()
@@ -298,7 +760,7 @@ func (t *CLTTrainer) calculateScore(exercise models.Exercise) float64 {
 		speedBonus := speedRatio * 10.0 // Up to 10 points for speed
 		baseScore += speedBonus
 	}
-	
+
 	// Ensure score is between 0 and 100
 	if baseScore < 0 {
 		baseScore = 0
@@ -306,121 +768,132 @@ func (t *CLTTrainer) calculateScore(exercise models.Exercise) float64 {
 	if baseScore > 100 {
 		baseScore = 100
 	}
-	
+
 	return baseScore
 }
 
-// showHelp provides contextual assistance
-func (t *CLTTrainer) showHelp() {
-	fmt.Println("\n📚 Available Commands:")
-	fmt.Println("  hint  - Get a helpful hint for the current challenge")
-	fmt.Println("  skip  - Skip the current challenge and see the solution")
-	fmt.Println("  pause - Save your progress and exit (resume later)")
-	fmt.Println("  quit  - Exit the trainer without saving")
-	fmt.Println("  help  - Show this help message")
-	fmt.Println()
-}
-
-// showFinalResults provides comprehensive learning summary
-func (t *CLTTrainer) showFinalResults() {
-	fmt.Println("\n🎉 Training Complete!")
-	fmt.Println("====================")
-	
-	totalTime := time.Since(t.startTime)
-	completed := 0
-	
-	for _, progress := range t.progress {
-		if progress.CompletedAt != nil {
-			completed++
-		}
-	}
-	
-	fmt.Printf("Exercises completed: %d/%d\n", completed, len(t.exercises))
-	fmt.Printf("Total time: %.1f minutes\n", totalTime.Minutes())
-	
-	// Learning analytics summary
-	totalAttempts := 0
-	totalHints := 0
-	totalScore := 0.0
-	for _, progress := range t.progress[:completed] {
-		totalAttempts += progress.Attempts
-		totalHints += progress.HintsUsed
-		totalScore += progress.Score
-	}
-	
-	fmt.Printf("Total attempts: %d\n", totalAttempts)
-	fmt.Printf("Hints used: %d\n", totalHints)
-	if completed > 0 {
-		fmt.Printf("Average attempts per exercise: %.1f\n", float64(totalAttempts)/float64(completed))
-		fmt.Printf("Average score: %.1f/100\n", totalScore/float64(completed))
-	}
-	
-	// Individual exercise scores
-	if completed > 0 {
-		fmt.Println("\n📊 Exercise Scores:")
-		for i, progress := range t.progress[:completed] {
-			fmt.Printf("  %s: %.1f/100\n", t.exercises[i].Title, progress.Score)
-		}
-	}
-	
-	// Learning reinforcement
-	fmt.Println("\n🧠 Key Concepts Learned:")
-	for i, exercise := range t.exercises[:completed] {
-		fmt.Printf("  %d. %s\n", i+1, exercise.Title)
-		for _, goal := range exercise.LearningGoals {
-			fmt.Printf("     • %s\n", goal)
-		}
-	}
-	
-	fmt.Println("\n🚀 Next Steps:")
-	fmt.Println("  • Practice these concepts in your own projects")
-	fmt.Println("  • Explore Go's standard library")
-	fmt.Println("  • Join the Go community online")
+// summary gathers the learning analytics shown at the end of a session
+func (t *CLTTrainer) summary() Summary {
+	summary := Summary{
+		Total:            len(t.exercises),
+		TotalTime:        time.Since(t.startTime),
+		Mastery:          t.mastery.Estimates(),
+		MasteryThreshold: t.mastery.Threshold,
+	}
+	for i, progress := range t.progress {
+		if progress.CompletedAt == nil {
+			continue
+		}
+		summary.Completed = append(summary.Completed, ExerciseResult{Exercise: t.exercises[i], Progress: progress})
+		summary.Attempts += progress.Attempts
+		summary.HintsUsed += progress.HintsUsed
+		summary.CompileErrors += progress.CompileErrors
+		summary.WrongAnswers += progress.WrongAnswers
+	}
+	return summary
 }
 
 // FormatCodeBlock formats code for clean terminal display
 func (t *CLTTrainer) FormatCodeBlock(code string) string {
-	lines := strings.Split(code, "\n")
-	var formatted strings.Builder
-	
-	// Add top border
-	formatted.WriteString("┌" + strings.Repeat("─", 60) + "┐\n")
-	
-	// Add code lines with side borders and indentation
-	for _, line := range lines {
-		// Ensure line fits within border, truncate if necessary
-		if len(line) > 56 {
-			line = line[:53] + "..."
-		}
-		formatted.WriteString(fmt.Sprintf("│  %-56s  │\n", line))
-	}
-	
-	// Add bottom border
-	formatted.WriteString("└" + strings.Repeat("─", 60) + "┘")
-	
-	return formatted.String()
+	return formatCodeBlock(code)
 }
 
 // pauseSession saves the current training state
 func (t *CLTTrainer) pauseSession() error {
+	return t.saveSession(models.SessionPaused)
+}
+
+// saveSession stores the training state with the given status. Completed
+// sessions are kept so their attempt history can feed spaced repetition.
+func (t *CLTTrainer) saveSession(status models.SessionStatus) error {
 	if t.storage == nil {
 		return fmt.Errorf("no storage configured")
 	}
 
+	// Count the time spent so far on an unfinished exercise
+	progress := slices.Clone(t.progress)
+	if t.current < len(progress) && t.inProgress(t.current) {
+		progress[t.current].TimeSpent = t.exerciseTime()
+	}
+
 	now := time.Now()
 	session := &models.TrainingSession{
 		UserID:       t.userID,
 		SessionID:    t.getOrCreateSessionID(),
 		Config:       t.config,
-		Progress:     t.progress,
+		Progress:     progress,
 		CurrentIndex: t.current,
 		StartTime:    t.startTime,
+		ActiveTime:   t.activeTime(),
+		Decisions:    t.decisions,
+		Mastery:      t.mastery.Estimates(),
+		Exercises:    t.exerciseIDs(),
 		LastActivity: now,
-		PausedAt:     &now,
-		Status:       models.SessionPaused,
+		Status:       status,
+	}
+	if status == models.SessionPaused {
+		session.PausedAt = &now
 	}
 
-	return t.storage.SaveSession(session)
+	// Hold a new session from its first save until training ends, so no
+	// other process takes it for a crashed one, deletes or archives it
+	if t.unlock == nil && !t.stored {
+		if locker, ok := t.storage.(storage.Locker); ok {
+			unlock, err := locker.LockSession(session.SessionID)
+			if err != nil {
+				return err
+			}
+			t.unlock = unlock
+		}
+	}
+
+	if err := t.storage.SaveSession(session); err != nil {
+		return err
+	}
+	t.stored = true
+	return nil
+}
+
+// exerciseIDs lists the IDs of the exercises being trained, in order
+func (t *CLTTrainer) exerciseIDs() []string {
+	ids := make([]string, len(t.exercises))
+	for i, exercise := range t.exercises {
+		ids[i] = exercise.ID
+	}
+	return ids
+}
+
+// sessionExercises picks the exercises a session was training, in the
+// order its progress was recorded in, from the ones available now
+func sessionExercises(session *models.TrainingSession, available []models.Exercise) ([]models.Exercise, error) {
+	if len(session.Exercises) == 0 {
+		return available, nil
+	}
+	byID := make(map[string]models.Exercise, len(available))
+	for _, exercise := range available {
+		byID[exercise.ID] = exercise
+	}
+	list := make([]models.Exercise, len(session.Exercises))
+	for i, id := range session.Exercises {
+		exercise, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("exercise %q from this session is no longer available", id)
+		}
+		list[i] = exercise
+	}
+	return list, nil
+}
+
+// SessionID returns the session's ID, naming a new session after its user
+// and start time
+func (t *CLTTrainer) SessionID() string {
+	return t.getOrCreateSessionID()
+}
+
+// SetSessionID names a new session before training starts, for front-ends
+// that start several sessions for a user at once
+func (t *CLTTrainer) SetSessionID(id string) {
+	t.sessionID = id
 }
 
 // getOrCreateSessionID returns existing session ID or creates a new one
@@ -433,17 +906,49 @@ func (t *CLTTrainer) getOrCreateSessionID() string {
 
 // ResumeSession loads and continues a paused training session
 func ResumeSession(sessionID string, exercises []models.Exercise, sessionStorage storage.SessionStorage) (*CLTTrainer, error) {
+	return resumeSession(sessionID, models.SessionPaused, exercises, sessionStorage)
+}
+
+// RecoverSession continues a session that was interrupted while active, by a
+// crash or a closed terminal, from its last checkpoint
+func RecoverSession(sessionID string, exercises []models.Exercise, sessionStorage storage.SessionStorage) (*CLTTrainer, error) {
+	return resumeSession(sessionID, models.SessionActive, exercises, sessionStorage)
+}
+
+// resumeSession continues a session saved with the given status
+func resumeSession(sessionID string, status models.SessionStatus, exercises []models.Exercise, sessionStorage storage.SessionStorage) (trainer *CLTTrainer, err error) {
+	// Hold the session until training ends, so no other process resumes it
+	unlock := func() error { return nil }
+	if locker, ok := sessionStorage.(storage.Locker); ok {
+		if unlock, err = locker.LockSession(sessionID); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				unlock()
+			}
+		}()
+	}
+
 	session, err := sessionStorage.LoadSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load session: %w", err)
 	}
 
-	if session.Status != models.SessionPaused {
-		return nil, fmt.Errorf("session is not paused (status: %s)", session.Status)
+	if session.Status != status {
+		return nil, fmt.Errorf("session is not %s (status: %s)", status, session.Status)
+	}
+
+	exercises, err = sessionExercises(session, exercises)
+	if err != nil {
+		return nil, err
 	}
 
 	// Store pause time before clearing it
 	pausedAt := session.PausedAt
+	if pausedAt == nil {
+		pausedAt = &session.LastActivity
+	}
 
 	// Update session status to active
 	session.Status = models.SessionActive
@@ -453,14 +958,9 @@ func ResumeSession(sessionID string, exercises []models.Exercise, sessionStorage
 		return nil, fmt.Errorf("failed to update session status: %w", err)
 	}
 
-	trainer := NewCLTTrainerFromSession(session, exercises, sessionStorage)
-	
-	if pausedAt != nil {
-		fmt.Printf("🔄 Resuming session from %s\n", pausedAt.Format("2006-01-02 15:04:05"))
-	}
-	fmt.Printf("📍 Current position: Exercise %d/%d (%s)\n", 
-		session.CurrentIndex+1, len(exercises), exercises[session.CurrentIndex].Title)
-	fmt.Println()
+	trainer = NewCLTTrainerFromSession(session, exercises, sessionStorage)
+	trainer.resumedAt = pausedAt
+	trainer.unlock = unlock
 
 	return trainer, nil
 }
@@ -468,4 +968,4 @@ func ResumeSession(sessionID string, exercises []models.Exercise, sessionStorage
 // ListUserSessions returns all training sessions for a user
 func ListUserSessions(userID string, sessionStorage storage.SessionStorage) ([]*models.TrainingSession, error) {
 	return sessionStorage.ListSessions(userID)
-}
\ No newline at end of file
+}
@@ -0,0 +1,68 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/sandbox"
+)
+
+// TestFailures reports hidden tests that the learner's code did not pass
+type TestFailures struct {
+	Report *sandbox.TestReport
+}
+
+// Error implements error
+func (e *TestFailures) Error() string {
+	switch {
+	case !e.Report.Built:
+		return "code does not compile with the challenge tests:\n" + e.Report.BuildOutput
+	case e.Report.TimedOut:
+		return "tests took too long and were stopped"
+	case e.Report.OutOfMemory:
+		return "tests used too much memory and were stopped"
+	}
+	return fmt.Sprintf("%d test(s) failed: %s", len(e.Report.Failures), strings.Join(e.Report.FailedNames(), ", "))
+}
+
+// testsRule compiles the answer with a hidden test file and runs it
+type testsRule struct {
+	tests  string
+	runner *sandbox.Runner
+}
+
+// PassesTests requires the spliced program to pass a hidden _test.go file
+// in package main. Like OutputMatches, it is skipped when no Go toolchain
+// is installed.
+func PassesTests(testSource string) Rule {
+	return &testsRule{tests: testSource}
+}
+
+// PassesTestsWith is PassesTests with a specific sandbox runner
+func PassesTestsWith(runner *sandbox.Runner, testSource string) Rule {
+	return &testsRule{tests: testSource, runner: runner}
+}
+
+func (*testsRule) deferred() {}
+
+// Check implements Rule
+func (r *testsRule) Check(sub *Submission) error {
+	runner := r.runner
+	if runner == nil {
+		runner = sandbox.Default()
+	}
+
+	report, err := runner.Test(context.Background(), sub.Source, r.tests)
+	if errors.Is(err, sandbox.ErrToolchainUnavailable) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return &TestFailures{Report: report}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cmyers78/claude/internal/sandbox"
+)
+
+// deferredRule marks rules that are costly to check. Evaluate only runs them
+// once every structural rule has passed.
+type deferredRule interface {
+	Rule
+	deferred()
+}
+
+// CompileError reports a submission that go/types rejected
+type CompileError struct {
+	Diagnostics []Diagnostic
+}
+
+// Error implements error
+func (e *CompileError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return "code does not compile"
+	}
+	return "code does not compile: " + e.Diagnostics[0].String()
+}
+
+// compilesRule requires the spliced program to type-check
+type compilesRule struct{}
+
+// Compiles requires the answer, merged into the template, to compile
+func Compiles() Rule {
+	return compilesRule{}
+}
+
+func (compilesRule) deferred() {}
+
+// Check implements Rule
+func (compilesRule) Check(sub *Submission) error {
+	if diagnostics := TypeCheck(sub); len(diagnostics) > 0 {
+		return &CompileError{Diagnostics: diagnostics}
+	}
+	return nil
+}
+
+// OutputMismatch reports a program whose output differs from what was expected
+type OutputMismatch struct {
+	Expected string
+	Actual   string
+}
+
+// Error implements error
+func (e *OutputMismatch) Error() string {
+	return "program output does not match the expected output"
+}
+
+// Diff returns a line-by-line comparison of expected and actual output
+func (e *OutputMismatch) Diff() string {
+	return Diff(e.Expected, e.Actual)
+}
+
+// RunError reports a program that built but failed while running
+type RunError struct {
+	Result *sandbox.Result
+}
+
+// Error implements error
+func (e *RunError) Error() string {
+	switch {
+	case e.Result.TimedOut:
+		return "program took too long and was stopped"
+	case e.Result.OutOfMemory:
+		return "program used too much memory and was stopped"
+	case !e.Result.Built:
+		return "program failed to build:\n" + e.Result.BuildOutput
+	}
+	stderr := strings.TrimSpace(e.Result.Stderr)
+	if stderr == "" {
+		return fmt.Sprintf("program exited with status %d", e.Result.ExitCode)
+	}
+	return fmt.Sprintf("program exited with status %d:\n%s", e.Result.ExitCode, stderr)
+}
+
+// outputRule runs the program and compares what it prints
+type outputRule struct {
+	expected string
+	runner   *sandbox.Runner
+}
+
+// OutputMatches runs the spliced program in the sandbox and requires its
+// stdout to equal expected, ignoring trailing whitespace on each line.
+// When no Go toolchain is installed the check is skipped, leaving the
+// decision to the other rules.
+func OutputMatches(expected string) Rule {
+	return &outputRule{expected: expected}
+}
+
+// OutputMatchesWith is OutputMatches with a specific sandbox runner
+func OutputMatchesWith(runner *sandbox.Runner, expected string) Rule {
+	return &outputRule{expected: expected, runner: runner}
+}
+
+func (*outputRule) deferred() {}
+
+// Check implements Rule
+func (r *outputRule) Check(sub *Submission) error {
+	runner := r.runner
+	if runner == nil {
+		runner = sandbox.Default()
+	}
+
+	result, err := runner.Run(context.Background(), sub.Source)
+	if errors.Is(err, sandbox.ErrToolchainUnavailable) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !result.Built || result.TimedOut || result.OutOfMemory || result.ExitCode != 0 {
+		return &RunError{Result: result}
+	}
+
+	if normalizeOutput(result.Stdout) != normalizeOutput(r.expected) {
+		return &OutputMismatch{Expected: r.expected, Actual: result.Stdout}
+	}
+	return nil
+}
+
+// normalizeOutput trims trailing whitespace from each line and the whole text
+func normalizeOutput(output string) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff compares expected and actual output line by line. Matching lines are
+// prefixed with two spaces, expected-only lines with "- " and actual-only
+// lines with "+ ".
+func Diff(expected, actual string) string {
+	a := strings.Split(normalizeOutput(expected), "\n")
+	b := strings.Split(normalizeOutput(actual), "\n")
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return strings.TrimRight(diff.String(), "\n")
+}
@@ -0,0 +1,707 @@
+package validation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// Rule is a single structural check against a parsed submission
+type Rule interface {
+	// Check returns nil when the submission satisfies the rule, or an error
+	// describing what is missing in terms a learner can act on
+	Check(sub *Submission) error
+}
+
+// RuleFunc adapts an ordinary function to the Rule interface
+type RuleFunc func(sub *Submission) error
+
+// Check calls f(sub)
+func (f RuleFunc) Check(sub *Submission) error {
+	return f(sub)
+}
+
+// Result is the outcome of evaluating an answer against a set of rules
+type Result struct {
+	Submission *Submission
+	ParseError error   // Set when the answer could not be parsed
+	Failures   []error // Rules the submission did not satisfy
+}
+
+// Passed reports whether the answer parsed and satisfied every rule
+func (r *Result) Passed() bool {
+	return r.ParseError == nil && len(r.Failures) == 0
+}
+
+// Evaluate parses an answer in the context of its template and applies rules
+func Evaluate(template, answer string, rules ...Rule) *Result {
+	sub, err := Parse(template, answer)
+	if err != nil {
+		return &Result{ParseError: err}
+	}
+
+	result := &Result{Submission: sub}
+	var deferred []Rule
+	for _, rule := range rules {
+		if _, ok := rule.(deferredRule); ok {
+			deferred = append(deferred, rule)
+			continue
+		}
+		if err := rule.Check(sub); err != nil {
+			result.Failures = append(result.Failures, err)
+		}
+	}
+
+	// Compiling and running are only worth it once the structure is right
+	for _, rule := range deferred {
+		if len(result.Failures) > 0 {
+			break
+		}
+		if err := rule.Check(sub); err != nil {
+			result.Failures = append(result.Failures, err)
+		}
+	}
+	return result
+}
+
+// Validator builds a models.Challenge validator from structural rules
+func Validator(template string, rules ...Rule) func(string) bool {
+	return func(answer string) bool {
+		return Evaluate(template, answer, rules...).Passed()
+	}
+}
+
+// VarRule checks a variable declaration. Build one with Var.
+type VarRule struct {
+	Name        string
+	Type        string // Required explicit type, if any
+	Literal     string // Required composite literal type of the value, if any
+	MinElements int    // Minimum elements in the composite literal
+	Form        string // "var", "short" or "" for either
+	Inferred    bool   // Type must be left to inference
+	Initialized bool   // A value must be assigned
+}
+
+// Var requires the learner to declare a variable with the given name
+func Var(name string) *VarRule {
+	return &VarRule{Name: name}
+}
+
+// WithType requires an explicit type, as in `var name string = ...`
+func (r *VarRule) WithType(typ string) *VarRule {
+	r.Type = typ
+	r.Form = "var"
+	return r
+}
+
+// WithInferredType requires `var` without a type so Go infers it from the value
+func (r *VarRule) WithInferredType() *VarRule {
+	r.Inferred = true
+	r.Initialized = true
+	r.Form = "var"
+	return r
+}
+
+// Short requires the short declaration form `name := ...`
+func (r *VarRule) Short() *VarRule {
+	r.Form = "short"
+	r.Initialized = true
+	return r
+}
+
+// WithValue requires the declaration to assign a value
+func (r *VarRule) WithValue() *VarRule {
+	r.Initialized = true
+	return r
+}
+
+// WithLiteral requires the value to be a composite literal such as []string{...}
+func (r *VarRule) WithLiteral(typ string, minElements int) *VarRule {
+	r.Literal = typ
+	r.MinElements = minElements
+	r.Initialized = true
+	return r
+}
+
+// Check implements Rule
+func (r *VarRule) Check(sub *Submission) error {
+	var problem error
+	found := false
+
+	sub.Inspect(func(n ast.Node) bool {
+		if found {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.ValueSpec:
+			if node.Names == nil || !isVarSpec(sub, node) {
+				return true
+			}
+			for i, ident := range node.Names {
+				if ident.Name != r.Name {
+					continue
+				}
+				var value ast.Expr
+				if i < len(node.Values) {
+					value = node.Values[i]
+				}
+				if problem = r.checkDecl("var", node.Type, value); problem == nil {
+					found = true
+				}
+			}
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || ident.Name != r.Name {
+					continue
+				}
+				var value ast.Expr
+				if len(node.Rhs) == len(node.Lhs) {
+					value = node.Rhs[i]
+				} else if len(node.Rhs) == 1 {
+					value = node.Rhs[0]
+				}
+				if problem = r.checkDecl("short", nil, value); problem == nil {
+					found = true
+				}
+			}
+		}
+		return true
+	})
+
+	if found {
+		return nil
+	}
+	if problem != nil {
+		return problem
+	}
+	return fmt.Errorf("declare a variable named %s", r.Name)
+}
+
+// checkDecl verifies a single declaration of the variable
+func (r *VarRule) checkDecl(form string, typ ast.Expr, value ast.Expr) error {
+	switch {
+	case r.Form == "var" && form != "var":
+		return fmt.Errorf("declare %s with the var keyword instead of :=", r.Name)
+	case r.Form == "short" && form != "short":
+		return fmt.Errorf("declare %s with := instead of var", r.Name)
+	case r.Type != "" && typ == nil:
+		return fmt.Errorf("give %s the explicit type %s", r.Name, r.Type)
+	case r.Type != "" && types.ExprString(typ) != r.Type:
+		return fmt.Errorf("%s should have type %s, not %s", r.Name, r.Type, types.ExprString(typ))
+	case r.Inferred && typ != nil:
+		return fmt.Errorf("leave out the type of %s and let Go infer it", r.Name)
+	case r.Initialized && value == nil:
+		return fmt.Errorf("assign a value to %s", r.Name)
+	}
+
+	if r.Literal != "" {
+		lit, ok := value.(*ast.CompositeLit)
+		if !ok || lit.Type == nil || types.ExprString(lit.Type) != r.Literal {
+			return fmt.Errorf("initialize %s with a %s literal", r.Name, r.Literal)
+		}
+		if len(lit.Elts) < r.MinElements {
+			return fmt.Errorf("%s should start with at least %d elements", r.Name, r.MinElements)
+		}
+	}
+	return nil
+}
+
+// isVarSpec reports whether a value spec belongs to a var declaration
+func isVarSpec(sub *Submission, spec *ast.ValueSpec) bool {
+	isVar := false
+	ast.Inspect(sub.File, func(n ast.Node) bool {
+		if gen, ok := n.(*ast.GenDecl); ok {
+			for _, s := range gen.Specs {
+				if s == spec {
+					isVar = gen.Tok == token.VAR
+					return false
+				}
+			}
+		}
+		return !isVar
+	})
+	return isVar
+}
+
+// ConstRule checks a constant declaration. Build one with Const.
+type ConstRule struct {
+	Name    string
+	Value   string // Required literal value, if any
+	Grouped bool   // Must be declared inside a const ( ... ) block
+}
+
+// Const requires the learner to declare a constant with the given name
+func Const(name string) *ConstRule {
+	return &ConstRule{Name: name}
+}
+
+// WithValue requires the constant to be set to the given literal
+func (r *ConstRule) WithValue(value string) *ConstRule {
+	r.Value = value
+	return r
+}
+
+// InBlock requires the constant to be declared in a grouped const block
+func (r *ConstRule) InBlock() *ConstRule {
+	r.Grouped = true
+	return r
+}
+
+// Check implements Rule
+func (r *ConstRule) Check(sub *Submission) error {
+	var problem error
+	found := false
+
+	sub.Inspect(func(n ast.Node) bool {
+		gen, ok := n.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST || found {
+			return !found
+		}
+		for _, s := range gen.Specs {
+			spec := s.(*ast.ValueSpec)
+			for i, ident := range spec.Names {
+				if ident.Name != r.Name {
+					continue
+				}
+				switch {
+				case r.Grouped && !gen.Lparen.IsValid():
+					problem = fmt.Errorf("declare %s inside a const ( ... ) block", r.Name)
+				case r.Value != "" && (i >= len(spec.Values) || types.ExprString(spec.Values[i]) != r.Value):
+					problem = fmt.Errorf("%s should be %s", r.Name, r.Value)
+				default:
+					found = true
+				}
+			}
+		}
+		return false
+	})
+
+	if found {
+		return nil
+	}
+	if problem != nil {
+		return problem
+	}
+	return fmt.Errorf("declare a constant named %s", r.Name)
+}
+
+// FuncRule checks a function or method declaration. Build one with Func or Method.
+type FuncRule struct {
+	Name      string
+	Receiver  string   // Receiver base type for methods
+	Pointer   *bool    // Required receiver kind, if any
+	Params    []string // Required parameter types, if set
+	Results   []string // Required result types, if set
+	Operators []token.Token
+	Fields    []string // Receiver fields the body must access
+	NeedsBody bool     // Body must contain a return statement
+}
+
+// Func requires the learner to define a function with the given name
+func Func(name string) *FuncRule {
+	return &FuncRule{Name: name}
+}
+
+// Method requires the learner to define a method on the given receiver type
+func Method(receiver, name string) *FuncRule {
+	return &FuncRule{Name: name, Receiver: receiver}
+}
+
+// WithParams requires the parameter types, one entry per parameter
+func (r *FuncRule) WithParams(types ...string) *FuncRule {
+	r.Params = types
+	return r
+}
+
+// WithResults requires the result types, one entry per result
+func (r *FuncRule) WithResults(types ...string) *FuncRule {
+	r.Results = types
+	r.NeedsBody = true
+	return r
+}
+
+// WithPointerReceiver requires a receiver such as (a *Account)
+func (r *FuncRule) WithPointerReceiver() *FuncRule {
+	pointer := true
+	r.Pointer = &pointer
+	return r
+}
+
+// WithValueReceiver requires a receiver such as (a Account)
+func (r *FuncRule) WithValueReceiver() *FuncRule {
+	pointer := false
+	r.Pointer = &pointer
+	return r
+}
+
+// Using requires the body to apply each operator, e.g. token.MUL
+func (r *FuncRule) Using(ops ...token.Token) *FuncRule {
+	r.Operators = append(r.Operators, ops...)
+	return r
+}
+
+// Accessing requires the body to read the named fields through the receiver
+func (r *FuncRule) Accessing(fields ...string) *FuncRule {
+	r.Fields = append(r.Fields, fields...)
+	return r
+}
+
+// Check implements Rule
+func (r *FuncRule) Check(sub *Submission) error {
+	var decl *ast.FuncDecl
+	sub.Inspect(func(n ast.Node) bool {
+		if fn, ok := n.(*ast.FuncDecl); ok && fn.Name.Name == r.Name && receiverType(fn) == r.Receiver {
+			decl = fn
+		}
+		return decl == nil
+	})
+
+	if decl == nil {
+		if r.Receiver != "" {
+			return fmt.Errorf("define a method %s on %s", r.Name, r.Receiver)
+		}
+		return fmt.Errorf("define a function named %s", r.Name)
+	}
+
+	if r.Pointer != nil {
+		isPointer := isPointerReceiver(decl)
+		if *r.Pointer && !isPointer {
+			return fmt.Errorf("%s should have a pointer receiver (*%s)", r.Name, r.Receiver)
+		}
+		if !*r.Pointer && isPointer {
+			return fmt.Errorf("%s should have a value receiver (%s)", r.Name, r.Receiver)
+		}
+	}
+
+	if r.Params != nil {
+		if got := fieldTypes(decl.Type.Params); !equalTypes(got, r.Params) {
+			return fmt.Errorf("%s should take (%s), not (%s)", r.Name, strings.Join(r.Params, ", "), strings.Join(got, ", "))
+		}
+	}
+
+	if r.Results != nil {
+		if got := fieldTypes(decl.Type.Results); !equalTypes(got, r.Results) {
+			return fmt.Errorf("%s should return (%s), not (%s)", r.Name, strings.Join(r.Results, ", "), strings.Join(got, ", "))
+		}
+	}
+
+	if decl.Body == nil {
+		return fmt.Errorf("%s needs a body", r.Name)
+	}
+
+	if r.NeedsBody && len(r.Results) > 0 && !contains(decl.Body, func(n ast.Node) bool {
+		_, ok := n.(*ast.ReturnStmt)
+		return ok
+	}) {
+		return fmt.Errorf("%s should return a value", r.Name)
+	}
+
+	for _, op := range r.Operators {
+		op := op
+		if !contains(decl.Body, func(n ast.Node) bool { return usesOperator(n, op) }) {
+			return fmt.Errorf("%s should use the %s operator", r.Name, op)
+		}
+	}
+
+	recv := receiverName(decl)
+	for _, field := range r.Fields {
+		field := field
+		if !contains(decl.Body, func(n ast.Node) bool {
+			sel, ok := n.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != field {
+				return false
+			}
+			ident, ok := sel.X.(*ast.Ident)
+			return ok && ident.Name == recv
+		}) {
+			return fmt.Errorf("%s should use %s.%s", r.Name, recv, field)
+		}
+	}
+	return nil
+}
+
+// StructRule checks a struct type declaration. Build one with Struct.
+type StructRule struct {
+	Name       string
+	FieldNames []string
+	FieldTypes map[string]string
+	Embedded   []string
+}
+
+// Struct requires the learner to declare a struct type with the given name
+func Struct(name string) *StructRule {
+	return &StructRule{Name: name, FieldTypes: map[string]string{}}
+}
+
+// WithFields requires named fields of any type
+func (r *StructRule) WithFields(names ...string) *StructRule {
+	r.FieldNames = append(r.FieldNames, names...)
+	return r
+}
+
+// WithField requires a named field of a specific type
+func (r *StructRule) WithField(name, typ string) *StructRule {
+	r.FieldNames = append(r.FieldNames, name)
+	r.FieldTypes[name] = typ
+	return r
+}
+
+// Embedding requires an embedded (anonymous) field of the given type
+func (r *StructRule) Embedding(typ string) *StructRule {
+	r.Embedded = append(r.Embedded, typ)
+	return r
+}
+
+// Check implements Rule
+func (r *StructRule) Check(sub *Submission) error {
+	var st *ast.StructType
+	sub.Inspect(func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == r.Name {
+			if s, ok := spec.Type.(*ast.StructType); ok {
+				st = s
+			}
+		}
+		return st == nil
+	})
+
+	if st == nil {
+		return fmt.Errorf("define a struct type named %s", r.Name)
+	}
+
+	fields := map[string]string{}
+	embedded := map[string]bool{}
+	for _, field := range st.Fields.List {
+		typ := types.ExprString(field.Type)
+		if len(field.Names) == 0 {
+			embedded[strings.TrimPrefix(typ, "*")] = true
+			continue
+		}
+		for _, name := range field.Names {
+			fields[name.Name] = typ
+		}
+	}
+
+	for _, typ := range r.Embedded {
+		if !embedded[typ] {
+			if _, named := fields[typ]; named {
+				return fmt.Errorf("embed %s in %s without a field name", typ, r.Name)
+			}
+			return fmt.Errorf("%s should embed %s", r.Name, typ)
+		}
+	}
+
+	for _, name := range r.FieldNames {
+		typ, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("%s is missing the field %s", r.Name, name)
+		}
+		if want, typed := r.FieldTypes[name]; typed && typ != want {
+			return fmt.Errorf("%s.%s should have type %s, not %s", r.Name, name, want, typ)
+		}
+	}
+	return nil
+}
+
+// Calls requires a call to the named function or conversion, e.g. "append".
+// When args are given, the call must pass them, in order, as identifiers.
+func Calls(name string, args ...string) Rule {
+	return RuleFunc(func(sub *Submission) error {
+		found := false
+		sub.Inspect(func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || found || types.ExprString(call.Fun) != name || len(call.Args) < len(args) {
+				return !found
+			}
+			for i, arg := range args {
+				if types.ExprString(call.Args[i]) != arg {
+					return true
+				}
+			}
+			found = true
+			return false
+		})
+		if found {
+			return nil
+		}
+		if len(args) > 0 {
+			return fmt.Errorf("call %s(%s)", name, strings.Join(args, ", "))
+		}
+		return fmt.Errorf("call %s", name)
+	})
+}
+
+// UsesOperator requires a binary or assignment operator with the given operand
+// on its left-hand side. An empty operand matches any expression.
+func UsesOperator(op token.Token, operand string) Rule {
+	return RuleFunc(func(sub *Submission) error {
+		found := false
+		sub.Inspect(func(n ast.Node) bool {
+			if found || !usesOperator(n, op) {
+				return !found
+			}
+			if operand == "" {
+				found = true
+				return false
+			}
+			switch expr := n.(type) {
+			case *ast.BinaryExpr:
+				found = leftmost(expr) == operand
+			case *ast.AssignStmt:
+				found = len(expr.Lhs) == 1 && types.ExprString(expr.Lhs[0]) == operand
+			}
+			return !found
+		})
+		if found {
+			return nil
+		}
+		if operand != "" {
+			return fmt.Errorf("use %s with %s", op, operand)
+		}
+		return fmt.Errorf("use the %s operator", op)
+	})
+}
+
+// Indexes requires an index expression on the named value, e.g. s[0]
+func Indexes(name string) Rule {
+	return RuleFunc(func(sub *Submission) error {
+		found := false
+		sub.Inspect(func(n ast.Node) bool {
+			if index, ok := n.(*ast.IndexExpr); ok && types.ExprString(index.X) == name {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return nil
+		}
+		return fmt.Errorf("index into %s, e.g. %s[0]", name, name)
+	})
+}
+
+// RangesOver requires a for ... range loop. An empty name matches any collection.
+func RangesOver(name string) Rule {
+	return RuleFunc(func(sub *Submission) error {
+		found := false
+		sub.Inspect(func(n ast.Node) bool {
+			if loop, ok := n.(*ast.RangeStmt); ok && (name == "" || types.ExprString(loop.X) == name) {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return nil
+		}
+		if name != "" {
+			return fmt.Errorf("loop over %s with range", name)
+		}
+		return fmt.Errorf("use a for ... range loop")
+	})
+}
+
+// receiverType returns the base type name of a method receiver, or ""
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(types.ExprString(fn.Recv.List[0].Type), "*")
+}
+
+// receiverName returns the name bound to a method receiver, or ""
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 || len(fn.Recv.List[0].Names) == 0 {
+		return ""
+	}
+	return fn.Recv.List[0].Names[0].Name
+}
+
+// isPointerReceiver reports whether a method has a pointer receiver
+func isPointerReceiver(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return false
+	}
+	_, ok := fn.Recv.List[0].Type.(*ast.StarExpr)
+	return ok
+}
+
+// fieldTypes flattens a parameter or result list into one type per entry
+func fieldTypes(fields *ast.FieldList) []string {
+	result := []string{}
+	if fields == nil {
+		return result
+	}
+	for _, field := range fields.List {
+		typ := types.ExprString(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			result = append(result, typ)
+		}
+	}
+	return result
+}
+
+// equalTypes compares two type lists element by element
+func equalTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether any node below root matches
+func contains(root ast.Node, match func(ast.Node) bool) bool {
+	found := false
+	ast.Inspect(root, func(n ast.Node) bool {
+		if found || n == nil {
+			return false
+		}
+		found = match(n)
+		return !found
+	})
+	return found
+}
+
+// usesOperator reports whether a node applies op directly or as op=
+func usesOperator(n ast.Node, op token.Token) bool {
+	switch expr := n.(type) {
+	case *ast.BinaryExpr:
+		return expr.Op == op
+	case *ast.AssignStmt:
+		return assignOperators[expr.Tok] == op
+	}
+	return false
+}
+
+// assignOperators maps compound assignments to their binary operator
+var assignOperators = map[token.Token]token.Token{
+	token.ADD_ASSIGN: token.ADD,
+	token.SUB_ASSIGN: token.SUB,
+	token.MUL_ASSIGN: token.MUL,
+	token.QUO_ASSIGN: token.QUO,
+	token.REM_ASSIGN: token.REM,
+}
+
+// leftmost returns the leftmost operand of a chain like a + b + c
+func leftmost(expr *ast.BinaryExpr) string {
+	x := expr.X
+	for {
+		inner, ok := x.(*ast.BinaryExpr)
+		if !ok {
+			return types.ExprString(x)
+		}
+		x = inner.X
+	}
+}
@@ -0,0 +1,198 @@
+package validation
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// RuleSpec is the declarative form of a Rule, used by exercise data files.
+// Kind selects the rule; the other fields apply to the kinds noted beside them.
+//
+//	{"kind": "var", "name": "name", "type": "string", "initialized": true}
+//	{"kind": "method", "receiver": "BankAccount", "name": "Deposit", "pointer_receiver": true}
+type RuleSpec struct {
+	Kind string `json:"kind"`
+	Name string `json:"name,omitempty"` // var, const, func, method, struct, calls, indexes, range
+
+	Type        string `json:"type,omitempty"`         // var: explicit type
+	Form        string `json:"form,omitempty"`         // var: "var", "short" or "inferred"
+	Initialized bool   `json:"initialized,omitempty"`  // var: a value must be assigned
+	Literal     string `json:"literal,omitempty"`      // var: composite literal type
+	MinElements int    `json:"min_elements,omitempty"` // var: elements in the literal
+
+	Value   string `json:"value,omitempty"`   // const: literal value
+	Grouped bool   `json:"grouped,omitempty"` // const: inside const ( ... )
+
+	Receiver        string   `json:"receiver,omitempty"`         // method: receiver type
+	PointerReceiver *bool    `json:"pointer_receiver,omitempty"` // method: receiver kind
+	Params          []string `json:"params,omitempty"`           // func, method
+	Results         []string `json:"results,omitempty"`          // func, method
+	Operators       []string `json:"operators,omitempty"`        // func, method: e.g. "*"
+	Accesses        []string `json:"accesses,omitempty"`         // method: receiver fields used
+
+	Fields []string `json:"fields,omitempty"` // struct: "Name" or "Name type"
+	Embeds []string `json:"embeds,omitempty"` // struct: embedded types
+
+	Args     []string `json:"args,omitempty"`     // calls: identifier arguments
+	Operator string   `json:"operator,omitempty"` // operator: e.g. "+"
+	Operand  string   `json:"operand,omitempty"`  // operator: left-hand operand
+}
+
+// SpecError reports an invalid RuleSpec field
+type SpecError struct {
+	Field string
+	Err   error
+}
+
+// Error implements error
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// operatorTokens maps operator spellings in data files to tokens
+var operatorTokens = map[string]token.Token{
+	"+": token.ADD,
+	"-": token.SUB,
+	"*": token.MUL,
+	"/": token.QUO,
+	"%": token.REM,
+}
+
+// Build turns a spec into a Rule, or reports the offending field
+func (s RuleSpec) Build() (Rule, error) {
+	required := func(field, value string) error {
+		if value == "" {
+			return &SpecError{Field: field, Err: fmt.Errorf("required for %q rules", s.Kind)}
+		}
+		return nil
+	}
+
+	switch s.Kind {
+	case "var":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		rule := Var(s.Name)
+		switch s.Form {
+		case "":
+		case "var":
+			rule.Form = "var"
+		case "short":
+			rule.Short()
+		case "inferred":
+			rule.WithInferredType()
+		default:
+			return nil, &SpecError{Field: "form", Err: fmt.Errorf("unknown form %q (want var, short or inferred)", s.Form)}
+		}
+		if s.Type != "" {
+			rule.WithType(s.Type)
+		}
+		if s.Initialized {
+			rule.WithValue()
+		}
+		if s.Literal != "" {
+			rule.WithLiteral(s.Literal, s.MinElements)
+		}
+		return rule, nil
+
+	case "const":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		rule := Const(s.Name).WithValue(s.Value)
+		if s.Grouped {
+			rule.InBlock()
+		}
+		return rule, nil
+
+	case "func", "method":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		rule := Func(s.Name)
+		if s.Kind == "method" {
+			if err := required("receiver", s.Receiver); err != nil {
+				return nil, err
+			}
+			rule = Method(s.Receiver, s.Name)
+		}
+		if s.PointerReceiver != nil {
+			if *s.PointerReceiver {
+				rule.WithPointerReceiver()
+			} else {
+				rule.WithValueReceiver()
+			}
+		}
+		if s.Params != nil {
+			rule.WithParams(s.Params...)
+		}
+		if s.Results != nil {
+			rule.WithResults(s.Results...)
+		}
+		for i, op := range s.Operators {
+			tok, ok := operatorTokens[op]
+			if !ok {
+				return nil, &SpecError{Field: fmt.Sprintf("operators[%d]", i), Err: fmt.Errorf("unknown operator %q", op)}
+			}
+			rule.Using(tok)
+		}
+		rule.Accessing(s.Accesses...)
+		return rule, nil
+
+	case "struct":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		rule := Struct(s.Name)
+		for i, field := range s.Fields {
+			parts := strings.Fields(field)
+			switch len(parts) {
+			case 1:
+				rule.WithFields(parts[0])
+			case 2:
+				rule.WithField(parts[0], parts[1])
+			default:
+				return nil, &SpecError{Field: fmt.Sprintf("fields[%d]", i), Err: fmt.Errorf("want \"Name\" or \"Name type\", got %q", field)}
+			}
+		}
+		for _, typ := range s.Embeds {
+			rule.Embedding(typ)
+		}
+		return rule, nil
+
+	case "calls":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		return Calls(s.Name, s.Args...), nil
+
+	case "operator":
+		tok, ok := operatorTokens[s.Operator]
+		if !ok {
+			return nil, &SpecError{Field: "operator", Err: fmt.Errorf("unknown operator %q", s.Operator)}
+		}
+		return UsesOperator(tok, s.Operand), nil
+
+	case "indexes":
+		if err := required("name", s.Name); err != nil {
+			return nil, err
+		}
+		return Indexes(s.Name), nil
+
+	case "range":
+		return RangesOver(s.Name), nil
+
+	case "compiles":
+		return Compiles(), nil
+
+	case "":
+		return nil, &SpecError{Field: "kind", Err: fmt.Errorf("required")}
+	}
+	return nil, &SpecError{Field: "kind", Err: fmt.Errorf("unknown rule kind %q", s.Kind)}
+}
@@ -0,0 +1,196 @@
+package validation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// SpliceMode describes where a learner's answer was placed inside a template
+type SpliceMode int
+
+const (
+	WholeFile  SpliceMode = iota // Answer is a complete Go file
+	TopLevel                     // Answer holds package-level declarations
+	Statements                   // Answer holds statements for the body of main
+)
+
+// Submission is a learner's answer parsed in the context of its challenge template
+type Submission struct {
+	Answer string         // What the learner typed
+	Source string         // Template with the answer spliced in
+	Mode   SpliceMode     // Where the answer was placed
+	Offset int            // Lines of template before the first answer line
+	Fset   *token.FileSet // Positions for File
+	File   *ast.File      // Parsed Source
+	Nodes  []ast.Node     // Declarations or statements written by the learner
+}
+
+// Parse splices an answer into a challenge template and parses the result.
+// Comments are discarded, so code that only appears in a comment never counts.
+func Parse(template, answer string) (*Submission, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, fmt.Errorf("empty answer")
+	}
+
+	if strings.HasPrefix(answer, "package ") || strings.TrimSpace(template) == "" {
+		return parseSubmission(answer, answer, WholeFile, 0)
+	}
+
+	body, bodyLine, topLine := insertionPoints(template)
+	modes := []SpliceMode{TopLevel, Statements}
+	if body {
+		modes = []SpliceMode{Statements, TopLevel}
+	}
+
+	var firstErr error
+	for _, mode := range modes {
+		line := topLine
+		if mode == Statements {
+			if bodyLine == 0 {
+				continue
+			}
+			line = bodyLine
+		}
+		sub, err := parseSubmission(answer, spliceLines(template, answer, line), mode, line)
+		if err == nil {
+			return sub, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// parseSubmission parses spliced source and collects the learner's nodes
+func parseSubmission(answer, source string, mode SpliceMode, offset int) (*Submission, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", source, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, syntaxError(err, answer, mode, offset)
+	}
+
+	sub := &Submission{
+		Answer: answer,
+		Source: source,
+		Mode:   mode,
+		Offset: offset,
+		Fset:   fset,
+		File:   file,
+	}
+
+	first, last := offset+1, offset+strings.Count(answer, "\n")+1
+	inAnswer := func(n ast.Node) bool {
+		if mode == WholeFile {
+			return true
+		}
+		line := fset.Position(n.Pos()).Line
+		return line >= first && line <= last
+	}
+
+	if mode == Statements {
+		if main := findMain(file); main != nil {
+			for _, stmt := range main.Body.List {
+				if inAnswer(stmt) {
+					sub.Nodes = append(sub.Nodes, stmt)
+				}
+			}
+		}
+	} else {
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+				continue
+			}
+			if inAnswer(decl) {
+				sub.Nodes = append(sub.Nodes, decl)
+			}
+		}
+	}
+
+	if len(sub.Nodes) == 0 {
+		return nil, fmt.Errorf("answer contains no Go code")
+	}
+	return sub, nil
+}
+
+// Inspect walks every node the learner wrote, depth first
+func (s *Submission) Inspect(fn func(ast.Node) bool) {
+	for _, n := range s.Nodes {
+		ast.Inspect(n, fn)
+	}
+}
+
+// AnswerLine maps a line in Source back to the line the learner typed.
+// It returns 0 for lines that belong to the template.
+func (s *Submission) AnswerLine(line int) int {
+	if s.Mode == WholeFile {
+		return line
+	}
+	answerLine := line - s.Offset
+	if answerLine < 1 || answerLine > strings.Count(s.Answer, "\n")+1 {
+		return 0
+	}
+	return answerLine
+}
+
+// insertionPoints finds where an answer belongs in the template. Placeholder
+// comments inside main mark a statement challenge; otherwise the answer is
+// placed after the last top-level comment, or at the end of the file.
+func insertionPoints(template string) (inBody bool, bodyLine, topLine int) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "template.go", template, parser.ParseComments)
+	topLine = strings.Count(template, "\n") + 1
+	if err != nil {
+		return false, 0, topLine
+	}
+
+	main := findMain(file)
+	if main != nil {
+		bodyLine = fset.Position(main.Body.Rbrace).Line - 1
+	}
+
+	lastTop := 0
+	for _, group := range file.Comments {
+		end := fset.Position(group.End()).Line
+		if main != nil && group.Pos() > main.Body.Lbrace && group.End() < main.Body.Rbrace {
+			inBody = true
+			bodyLine = end
+			continue
+		}
+		if group.Pos() > file.Name.End() && end > lastTop {
+			lastTop = end
+		}
+	}
+	if lastTop > 0 {
+		topLine = lastTop
+	}
+	return inBody, bodyLine, topLine
+}
+
+// spliceLines inserts the answer after the given template line
+func spliceLines(template, answer string, after int) string {
+	lines := strings.Split(template, "\n")
+	if after > len(lines) {
+		after = len(lines)
+	}
+
+	spliced := make([]string, 0, len(lines)+1)
+	spliced = append(spliced, lines[:after]...)
+	spliced = append(spliced, answer)
+	spliced = append(spliced, lines[after:]...)
+	return strings.Join(spliced, "\n")
+}
+
+// findMain returns the main function declaration, if any
+func findMain(file *ast.File) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return fn
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Diagnostic is a compiler-style error reported against the learner's answer
+type Diagnostic struct {
+	Line    int // Line in the answer, or 0 when the error is in the template
+	Column  int
+	Message string
+}
+
+// String formats the diagnostic the way the Go compiler does
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("template: %s", d.Message)
+	}
+	return fmt.Sprintf("line %d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// SyntaxError reports an answer that could not be parsed
+type SyntaxError struct {
+	Diagnostics []Diagnostic
+}
+
+// Error implements error
+func (e *SyntaxError) Error() string {
+	if len(e.Diagnostics) == 0 {
+		return "syntax error"
+	}
+	return e.Diagnostics[0].String()
+}
+
+// maxDiagnostics caps how many errors are shown so learners aren't overwhelmed
+const maxDiagnostics = 5
+
+var (
+	// importMu guards the shared importer, which caches packages between checks
+	importMu  sync.Mutex
+	stdImport = importer.Default()
+)
+
+// stdlibImporter resolves standard library packages only, so checking a
+// submission never reaches for the network or the module cache
+type stdlibImporter struct{}
+
+// Import implements types.Importer
+func (stdlibImporter) Import(path string) (*types.Package, error) {
+	if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+		return nil, fmt.Errorf("only standard library packages can be imported")
+	}
+	return stdImport.Import(path)
+}
+
+// Compile parses an answer in the context of its template and type-checks
+// the result. It returns nil diagnostics when the program compiles.
+func Compile(template, answer string) (*Submission, []Diagnostic) {
+	sub, err := Parse(template, answer)
+	if err != nil {
+		var syntax *SyntaxError
+		if errors.As(err, &syntax) {
+			return nil, syntax.Diagnostics
+		}
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	return sub, TypeCheck(sub)
+}
+
+// TypeCheck runs go/types over a parsed submission. Errors are mapped back
+// to the lines the learner typed and sorted with answer errors first.
+func TypeCheck(sub *Submission) []Diagnostic {
+	var diagnostics []Diagnostic
+	conf := types.Config{
+		Importer: stdlibImporter{},
+		Error: func(err error) {
+			if typeErr, ok := err.(types.Error); ok {
+				pos := sub.Fset.Position(typeErr.Pos)
+				diagnostics = append(diagnostics, sub.diagnostic(pos, typeErr.Msg))
+			}
+		},
+	}
+
+	importMu.Lock()
+	conf.Check("main", sub.Fset, []*ast.File{sub.File}, nil)
+	importMu.Unlock()
+
+	return sortDiagnostics(diagnostics)
+}
+
+// diagnostic converts a position in Source to a Diagnostic on the answer
+func (s *Submission) diagnostic(pos token.Position, msg string) Diagnostic {
+	line := s.AnswerLine(pos.Line)
+	column := pos.Column
+	if line == 0 {
+		column = 0
+	}
+	return Diagnostic{Line: line, Column: column, Message: msg}
+}
+
+// syntaxError maps parser errors on spliced source back to the answer
+func syntaxError(err error, answer string, mode SpliceMode, offset int) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return err
+	}
+
+	sub := &Submission{Answer: answer, Mode: mode, Offset: offset}
+	var diagnostics []Diagnostic
+	for _, e := range list {
+		diagnostics = append(diagnostics, sub.diagnostic(e.Pos, e.Msg))
+	}
+	return &SyntaxError{Diagnostics: sortDiagnostics(diagnostics)}
+}
+
+// sortDiagnostics orders answer errors by position, template errors last
+func sortDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i], diagnostics[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return a.Line != 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	if len(diagnostics) > maxDiagnostics {
+		diagnostics = diagnostics[:maxDiagnostics]
+	}
+	return diagnostics
+}
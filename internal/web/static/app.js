@@ -0,0 +1,396 @@
+// Go Fundamentals Trainer front-end. Everything it shows comes from the
+// trainer's JSON API under /api/; it loads nothing from other hosts, so it
+// works on a network with no internet access.
+'use strict';
+
+const $ = (id) => document.getElementById(id);
+
+const state = {
+  user: '',
+  passphrase: '',
+  session: '',       // ID of the session shown
+  current: null,     // Its latest state from the API
+  examplesKey: '',   // Which examples are rendered
+  challengeKey: '',  // Which challenge the editor holds
+  busy: false,       // A request to the session is in flight
+};
+
+// ---- API -------------------------------------------------------------------
+
+// api calls the trainer's API, returning the decoded response or throwing
+// the error it reports
+async function api(method, path, body) {
+  // The server only takes JSON, even for requests without a body
+  const headers = method === 'GET' ? {} : {'Content-Type': 'application/json'};
+  if (state.passphrase) headers['X-Trainer-Passphrase'] = state.passphrase;
+  const response = await fetch('/api' + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const data = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new Error(data.error || `${response.status} ${response.statusText}`);
+  }
+  return data;
+}
+
+function sessionsPath() {
+  return `/users/${encodeURIComponent(state.user)}/sessions`;
+}
+
+function sessionPath(suffix = '') {
+  return `${sessionsPath()}/${encodeURIComponent(state.session)}${suffix}`;
+}
+
+// ---- DOM helpers -----------------------------------------------------------
+
+// el creates an element with properties and children
+function el(tag, props = {}, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, props);
+  node.append(...children.filter((child) => child !== null && child !== undefined && child !== ''));
+  return node;
+}
+
+function escapeHTML(text) {
+  return text.replace(/[&<>"']/g, (c) => ({ '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;' })[c]);
+}
+
+function showError(message) {
+  const box = $('error');
+  box.textContent = message;
+  box.hidden = !message;
+}
+
+// ---- Syntax highlighting ---------------------------------------------------
+
+const KEYWORDS = new Set('break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var'.split(' '));
+const TYPES = new Set('any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr'.split(' '));
+const BUILTINS = new Set('append cap clear close complex copy delete false imag iota len make max min new nil panic print println real recover true'.split(' '));
+
+// One token per match: a comment, a string or rune (possibly unfinished, as
+// it is while typing), a number, or a word
+const TOKEN = /(\/\/[^\n]*|\/\*[\s\S]*?(?:\*\/|$))|("(?:[^"\\\n]|\\.)*"?|`[^`]*`?|'(?:[^'\\\n]|\\.)*'?)|(\b\d[\w.]*)|([A-Za-z_]\w*)/g;
+
+// highlight returns Go source as HTML with its tokens in classed spans
+function highlight(code) {
+  let html = '';
+  let last = 0;
+  for (const match of code.matchAll(TOKEN)) {
+    const [text, comment, string, number, word] = match;
+    let kind = '';
+    if (comment) kind = 'comment';
+    else if (string) kind = 'string';
+    else if (number) kind = 'number';
+    else if (KEYWORDS.has(word)) kind = 'keyword';
+    else if (TYPES.has(word)) kind = 'type';
+    else if (BUILTINS.has(word)) kind = 'builtin';
+    else if (code[match.index + text.length] === '(') kind = 'call';
+
+    html += escapeHTML(code.slice(last, match.index));
+    html += kind ? `<span class="tok-${kind}">${escapeHTML(text)}</span>` : escapeHTML(text);
+    last = match.index + text.length;
+  }
+  return html + escapeHTML(code.slice(last));
+}
+
+// codeBlock shows highlighted Go source
+function codeBlock(code) {
+  const block = el('code');
+  block.innerHTML = highlight(code);
+  return el('pre', { className: 'code' }, block);
+}
+
+// ---- Editor ----------------------------------------------------------------
+
+const editor = $('answer');
+const editorHighlight = document.querySelector('#highlight code');
+
+function updateHighlight() {
+  // A trailing newline needs a character after it to take up a line
+  editorHighlight.innerHTML = highlight(editor.value) + '\n';
+  syncScroll();
+}
+
+function syncScroll() {
+  const pre = $('highlight');
+  pre.scrollTop = editor.scrollTop;
+  pre.scrollLeft = editor.scrollLeft;
+}
+
+// insertText replaces the selection as typing would, keeping undo history
+function insertText(text) {
+  if (!document.execCommand('insertText', false, text)) {
+    editor.setRangeText(text, editor.selectionStart, editor.selectionEnd, 'end');
+  }
+  updateHighlight();
+}
+
+editor.addEventListener('input', updateHighlight);
+editor.addEventListener('scroll', syncScroll);
+editor.addEventListener('keydown', (event) => {
+  if (event.key === 'Enter' && (event.ctrlKey || event.metaKey)) {
+    event.preventDefault();
+    submitAnswer();
+  } else if (event.key === 'Tab' && !event.shiftKey) {
+    event.preventDefault();
+    insertText('\t');
+  } else if (event.key === 'Enter') {
+    // Keep the current line's indentation, one deeper after an opening bracket
+    event.preventDefault();
+    const before = editor.value.slice(0, editor.selectionStart);
+    const line = before.slice(before.lastIndexOf('\n') + 1);
+    let indent = line.match(/^\s*/)[0];
+    if (/[{(\[]\s*$/.test(line)) indent += '\t';
+    insertText('\n' + indent);
+  }
+});
+
+// goToLine puts the cursor at the start of a line of the editor
+function goToLine(line) {
+  const lines = editor.value.split('\n');
+  const offset = lines.slice(0, Math.max(0, line - 1)).reduce((sum, text) => sum + text.length + 1, 0);
+  editor.focus();
+  editor.setSelectionRange(offset, offset + (lines[line - 1] || '').length);
+}
+
+// ---- Rendering -------------------------------------------------------------
+
+const SOLUTION_HEADINGS = {
+  skipped: '⏭️ Skipped. The solution:',
+  out_of_attempts: 'Max attempts reached. The solution:',
+  out_of_time: 'Out of time. The solution:',
+  hints_exhausted: '💡 That was the last hint. The solution:',
+};
+
+// messageView shows one piece of feedback
+function messageView(message) {
+  const box = el('div', { className: `message message-${message.type}` });
+  switch (message.type) {
+    case 'solution':
+      box.append(el('p', { textContent: SOLUTION_HEADINGS[message.reason] || '💡 The solution:' }), codeBlock(message.code));
+      break;
+    case 'extra_example':
+      box.append(el('p', { textContent: '📖 One more worked example was added to the examples above. Study it before the next challenge.' }));
+      break;
+    case 'compile_errors': {
+      box.append(el('p', { textContent: "🛠️ Your code doesn't compile yet:" }));
+      const list = el('ul', { className: 'diagnostics' });
+      for (const diagnostic of message.diagnostics || []) {
+        const where = diagnostic.line > 0 ? `line ${diagnostic.line}:${diagnostic.column}` : 'template';
+        const item = el('li', {}, el('code', { textContent: where }), ' ', diagnostic.message);
+        if (diagnostic.line > 0) {
+          item.append(' ', el('button', { type: 'button', className: 'link', textContent: 'show', onclick: () => goToLine(diagnostic.line) }));
+        }
+        list.append(item);
+      }
+      box.append(list);
+      break;
+    }
+    case 'output_mismatch': {
+      box.append(el('p', { textContent: "❌ Your program compiles, but its output isn't right (- expected, + your output):" }));
+      const diff = el('pre', { className: 'diff' });
+      for (const line of (message.diff || '').split('\n')) {
+        const kind = line.startsWith('+') ? 'added' : line.startsWith('-') ? 'removed' : '';
+        diff.append(el('span', { className: kind, textContent: line + '\n' }));
+      }
+      box.append(diff);
+      break;
+    }
+    default:
+      box.append(el('pre', { className: 'text', textContent: message.text }));
+  }
+  return box;
+}
+
+function exampleView(example, open) {
+  return el('details', { className: 'example', open },
+    el('summary', { textContent: example.title }),
+    codeBlock(example.code),
+    el('p', { textContent: example.explanation }),
+    example.output ? el('p', { className: 'output' }, 'Output: ', el('code', { textContent: example.output })) : null);
+}
+
+// render shows a session's state
+function render(current) {
+  state.current = current;
+  $('welcome').hidden = true;
+  $('session').hidden = false;
+
+  $('session-title').textContent = `Session ${current.session_id}`;
+  $('progress').textContent = `${current.completed} of ${current.total} exercise(s) completed · ${current.status}${current.running ? '' : ' · not training'}`;
+  $('pause').hidden = !current.running;
+  $('resume').hidden = current.running || !['paused', 'active'].includes(current.status);
+  $('quit').disabled = !current.running || state.busy;
+  $('pause').disabled = state.busy;
+
+  const exercise = current.exercise;
+  $('exercise').hidden = !exercise;
+  if (exercise) {
+    $('exercise-title').textContent = exercise.title;
+    $('exercise-description').textContent = exercise.description;
+    $('goals').replaceChildren(...exercise.learning_goals.map((goal) => el('li', { textContent: goal })));
+    const key = `${exercise.id}/${exercise.examples.length}/${current.awaiting === 'ready'}`;
+    if (key !== state.examplesKey) {
+      state.examplesKey = key;
+      const studying = current.awaiting === 'ready';
+      $('examples').replaceChildren(...exercise.examples.map((example, i) =>
+        exampleView(example, studying || i === exercise.examples.length - 1)));
+    }
+  }
+  $('ready').hidden = current.awaiting !== 'ready';
+  $('ready').disabled = state.busy;
+
+  const challenge = current.challenge;
+  $('challenge').hidden = !challenge;
+  if (challenge) {
+    $('challenge-title').textContent = `Challenge ${challenge.number} of ${challenge.total}`;
+    $('challenge-description').textContent = challenge.description;
+    $('concepts').textContent = challenge.concepts && challenge.concepts.length ? `Concepts: ${challenge.concepts.join(', ')}` : '';
+    const key = `${exercise ? exercise.id : ''}/${challenge.number}`;
+    if (key !== state.challengeKey) {
+      state.challengeKey = key;
+      editor.value = challenge.template;
+      updateHighlight();
+    }
+    $('hints').replaceChildren(...(challenge.hints || []).map((hint) => el('li', { textContent: hint })));
+    const hint = $('hint');
+    hint.textContent = challenge.hints_left > 0 ? `Hint (${challenge.hints_left} left)` : 'No hints left';
+    hint.disabled = state.busy || challenge.hints_left === 0;
+  }
+  const answering = current.awaiting === 'answer' && !state.busy;
+  for (const id of ['submit', 'skip', 'help']) $(id).disabled = !answering;
+  if (current.awaiting !== 'answer') $('hint').disabled = true;
+  editor.readOnly = !current.running;
+
+  $('messages').replaceChildren(...current.messages.map(messageView));
+  $('status').textContent = statusText(current);
+}
+
+function statusText(current) {
+  if (state.busy) return 'Checking…';
+  if (!current.running) {
+    return current.status === 'paused' ? 'Paused and saved. Resume whenever you like.' : `This session is ${current.status}.`;
+  }
+  if (current.awaiting === 'ready') return 'Study the goals and examples, then start the challenges.';
+  if (current.awaiting === 'answer') return 'Edit the program and submit it. Press Ctrl+Enter to submit.';
+  return '';
+}
+
+// ---- Actions ---------------------------------------------------------------
+
+// act sends one request for the session shown and renders the response
+async function act(method, suffix, body) {
+  if (state.busy) return;
+  state.busy = true;
+  showError('');
+  if (state.current) render(state.current);
+  try {
+    const current = await api(method, sessionPath(suffix), body);
+    state.busy = false;
+    render(current);
+  } catch (err) {
+    state.busy = false;
+    showError(err.message);
+    if (state.current) render(state.current);
+  }
+  loadSessions();
+}
+
+function submitAnswer() {
+  if ($('submit').disabled) return;
+  act('POST', '/answer', { answer: editor.value });
+}
+
+function command(name) {
+  act('POST', '/commands', { command: name });
+}
+
+// open shows a session, resuming it if it isn't training
+async function open(sessionID, running) {
+  state.session = sessionID;
+  state.examplesKey = '';
+  state.challengeKey = '';
+  state.current = null;
+  await act(running ? 'GET' : 'POST', running ? '/current' : '/resume');
+}
+
+async function startSession(request) {
+  showError('');
+  try {
+    const current = await api('POST', sessionsPath(), request);
+    state.session = current.session_id;
+    state.examplesKey = '';
+    state.challengeKey = '';
+    render(current);
+  } catch (err) {
+    showError(err.message);
+  }
+  loadSessions();
+}
+
+async function loadSessions() {
+  if (!state.user) return;
+  try {
+    const sessions = await api('GET', sessionsPath());
+    $('sessions').replaceChildren(...sessions.map((session) => {
+      const canOpen = session.running || ['paused', 'active'].includes(session.status);
+      const label = `${session.completed}/${session.total} · ${session.running ? 'training' : session.status}`;
+      return el('li', { className: session.session_id === state.session ? 'selected' : '' },
+        el('span', {}, el('strong', { textContent: session.session_id }), el('br'), el('small', { textContent: label })),
+        canOpen ? el('button', { type: 'button', textContent: session.running ? 'Open' : 'Resume', onclick: () => open(session.session_id, session.running) }) : null);
+    }));
+  } catch (err) {
+    showError(err.message);
+  }
+}
+
+async function loadExercises() {
+  const exercises = await api('GET', '/exercises');
+  $('exercises').replaceChildren(...exercises.map((exercise) =>
+    el('li', {},
+      el('span', { title: exercise.description }, el('strong', { textContent: exercise.title }), el('br'),
+        el('small', { textContent: `${exercise.level} · ${exercise.challenges} challenge(s) · ~${exercise.estimated_minutes} min` })),
+      el('button', { type: 'button', textContent: 'Practice', onclick: () => startSession({ exercises: [exercise.id] }) }))));
+}
+
+$('sign-in').addEventListener('submit', async (event) => {
+  event.preventDefault();
+  state.user = $('user').value.trim();
+  state.passphrase = $('passphrase').value;
+  state.session = '';
+  state.current = null;
+  sessionStorage.setItem('trainer-user', state.user);
+  showError('');
+  $('session').hidden = true;
+  $('welcome').hidden = false;
+  try {
+    await api('GET', sessionsPath());
+    $('sidebar').hidden = false;
+    await Promise.all([loadSessions(), loadExercises()]);
+  } catch (err) {
+    $('sidebar').hidden = true;
+    showError(err.message);
+  }
+});
+
+$('start-curriculum').addEventListener('click', () => startSession({}));
+$('ready').addEventListener('click', () => command('ready'));
+$('submit').addEventListener('click', submitAnswer);
+$('hint').addEventListener('click', () => command('hint'));
+$('skip').addEventListener('click', () => command('skip'));
+$('help').addEventListener('click', () => command('help'));
+$('pause').addEventListener('click', () => act('POST', '/pause'));
+$('resume').addEventListener('click', () => act('POST', '/resume'));
+$('quit').addEventListener('click', () => {
+  if (confirm("Quit this session? It will be kept as abandoned and can't be resumed.")) command('quit');
+});
+$('reset').addEventListener('click', () => {
+  if (state.current && state.current.challenge) {
+    editor.value = state.current.challenge.template;
+    updateHighlight();
+  }
+});
+
+$('user').value = sessionStorage.getItem('trainer-user') || 'default';
@@ -0,0 +1,35 @@
+// Package web is the trainer's browser front-end: a single page, built
+// into the binary, that trains through the server package's JSON API. It
+// loads nothing from other hosts, so it works without a network.
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// static holds the page, its script and its styles
+//
+//go:embed static
+var static embed.FS
+
+// contentSecurityPolicy keeps the page to files and API calls from the
+// trainer itself
+const contentSecurityPolicy = "default-src 'self'; img-src 'self' data:; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+
+// Handler serves the front-end from the root of a site whose API is under
+// /api/
+func Handler() http.Handler {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // The directory is embedded above
+	}
+	fileServer := http.FileServerFS(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", contentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-cache")
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,264 @@
+package xapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// DefaultActivityBase prefixes activity IDs when no base is configured
+const DefaultActivityBase = "https://github.com/cmyers78/claude/trainer"
+
+// DefaultPassingScore is the exercise score out of 100 that passes
+const DefaultPassingScore = 70
+
+// Builder turns training session events into xAPI statements:
+//
+//	session started     attempted   the course
+//	session resumed     resumed     the course
+//	session paused      suspended   the course
+//	session completed   completed   the course
+//	session abandoned   terminated  the course
+//	exercise started    attempted   the exercise
+//	answer submitted    answered    the challenge
+//	exercise completed  completed   the exercise, then passed or failed it
+//
+// Statement IDs are derived from the session and the event, so building a
+// statement again, live or from a saved session, gives the same ID.
+type Builder struct {
+	ActivityBase string  // IRI prefix of activity IDs; DefaultActivityBase if empty
+	HomePage     string  // System learner accounts belong to; ActivityBase if empty
+	LearnerName  string  // Learner's display name, if known
+	PassingScore float64 // Exercise score that passes; DefaultPassingScore if zero
+}
+
+// Statements builds the statements an event records
+func (b Builder) Statements(event trainer.Event) []Statement {
+	course := b.course()
+	switch event.Type {
+	case trainer.SessionStarted:
+		return []Statement{b.statement(event, "attempted", Attempted, course, nil, nil)}
+	case trainer.SessionResumed:
+		return []Statement{b.statement(event, "resumed/"+stamp(event.At), Resumed, course, nil, nil)}
+	case trainer.SessionPaused:
+		result := &Result{Completion: boolean(false), Duration: isoDuration(event.ActiveTime)}
+		return []Statement{b.statement(event, "suspended/"+stamp(event.At), Suspended, course, result, nil)}
+	case trainer.SessionCompleted:
+		result := &Result{Completion: boolean(true), Duration: isoDuration(event.ActiveTime)}
+		return []Statement{b.statement(event, "completed", Completed, course, result, nil)}
+	case trainer.SessionAbandoned:
+		result := &Result{Completion: boolean(false), Duration: isoDuration(event.ActiveTime)}
+		return []Statement{b.statement(event, "terminated", Terminated, course, result, nil)}
+	}
+
+	if event.Exercise == nil || event.Progress == nil {
+		return nil
+	}
+	exercise := b.exercise(*event.Exercise)
+	parents := []Activity{course}
+	key := event.Exercise.ID + "/"
+
+	switch event.Type {
+	case trainer.ExerciseStarted:
+		return []Statement{b.statement(event, key+"attempted", Attempted, exercise, nil, parents)}
+
+	case trainer.AnswerSubmitted:
+		if event.Attempt == nil {
+			return nil
+		}
+		attempt := event.Attempt
+		challenge := b.challenge(*event.Exercise, attempt.Challenge)
+		result := &Result{Success: boolean(attempt.Passed), Response: attempt.Answer}
+		if extensions := b.attemptExtensions(*attempt); len(extensions) > 0 {
+			result.Extensions = extensions
+		}
+		key += fmt.Sprintf("%d/%d/answered", attempt.Challenge, attempt.Attempt)
+		return []Statement{b.statement(event, key, Answered, challenge, result, []Activity{exercise, course})}
+
+	case trainer.ExerciseCompleted:
+		progress := event.Progress
+		score := &Score{Scaled: progress.Score / 100, Raw: progress.Score, Min: 0, Max: 100}
+		completed := &Result{Score: score, Completion: boolean(true), Duration: isoDuration(progress.TimeSpent)}
+		passed := progress.Score >= b.passingScore()
+		verb := Failed
+		if passed {
+			verb = Passed
+		}
+		graded := &Result{Score: score, Success: boolean(passed), Duration: isoDuration(progress.TimeSpent)}
+		return []Statement{
+			b.statement(event, key+"completed", Completed, exercise, completed, parents),
+			b.statement(event, key+"graded", verb, exercise, graded, parents),
+		}
+	}
+	return nil
+}
+
+// statement assembles a statement about an event, keyed within its session
+func (b Builder) statement(event trainer.Event, key string, verb Verb, object Activity, result *Result, parents []Activity) Statement {
+	context := &Context{
+		Registration: nameUUID("registration/" + event.UserID + "/" + event.SessionID),
+		Platform:     "Go Trainer",
+		Extensions:   map[string]any{b.base() + "/extensions/session": event.SessionID},
+	}
+	if len(parents) > 0 {
+		context.ContextActivities = &ContextActivities{Parent: parents[:1], Grouping: parents[1:]}
+	}
+	return Statement{
+		ID:        nameUUID("statement/" + event.UserID + "/" + event.SessionID + "/" + key),
+		Actor:     b.actor(event.UserID),
+		Verb:      verb,
+		Object:    object,
+		Result:    result,
+		Context:   context,
+		Timestamp: event.At.UTC(),
+	}
+}
+
+// actor identifies a learner by their trainer user ID
+func (b Builder) actor(userID string) Agent {
+	homePage := b.HomePage
+	if homePage == "" {
+		homePage = b.base()
+	}
+	return Agent{ObjectType: "Agent", Name: b.LearnerName, Account: &Account{HomePage: homePage, Name: userID}}
+}
+
+// course is the activity of training through the curriculum
+func (b Builder) course() Activity {
+	return activity(b.base()+"/course", "Go Trainer", "", CourseType)
+}
+
+// exercise is the activity of working through one exercise
+func (b Builder) exercise(exercise models.Exercise) Activity {
+	return activity(b.base()+"/exercises/"+exercise.ID, exercise.Title, exercise.Description, ModuleType)
+}
+
+// challenge is the activity of answering one of an exercise's challenges
+func (b Builder) challenge(exercise models.Exercise, index int) Activity {
+	name := fmt.Sprintf("%s: challenge %d", exercise.Title, index+1)
+	description := ""
+	if index >= 0 && index < len(exercise.Challenges) {
+		description = exercise.Challenges[index].Description
+	}
+	return activity(fmt.Sprintf("%s/exercises/%s/challenges/%d", b.base(), exercise.ID, index+1), name, description, InteractionType)
+}
+
+// attemptExtensions records why an answer failed
+func (b Builder) attemptExtensions(attempt models.AttemptRecord) map[string]any {
+	extensions := make(map[string]any)
+	if attempt.CompileError {
+		extensions[b.base()+"/extensions/compile-error"] = true
+	}
+	if len(attempt.FailedTests) > 0 {
+		extensions[b.base()+"/extensions/failed-tests"] = attempt.FailedTests
+	}
+	return extensions
+}
+
+// base returns the activity ID prefix
+func (b Builder) base() string {
+	if b.ActivityBase == "" {
+		return DefaultActivityBase
+	}
+	return strings.TrimSuffix(b.ActivityBase, "/")
+}
+
+// passingScore returns the score that passes an exercise
+func (b Builder) passingScore() float64 {
+	if b.PassingScore == 0 {
+		return DefaultPassingScore
+	}
+	return b.PassingScore
+}
+
+// activity describes an activity in American English, the xAPI default
+func activity(id, name, description, activityType string) Activity {
+	definition := &ActivityDefinition{Name: map[string]string{"en-US": name}, Type: activityType}
+	if description != "" {
+		definition.Description = map[string]string{"en-US": description}
+	}
+	return Activity{ObjectType: "Activity", ID: id, Definition: definition}
+}
+
+// boolean returns a pointer to b, for optional result fields
+func boolean(b bool) *bool {
+	return &b
+}
+
+// stamp keys events that happen more than once in a session by their time
+func stamp(at time.Time) string {
+	return at.UTC().Format(time.RFC3339Nano)
+}
+
+// SessionEvents reconstructs the events of a saved session from its
+// progress, for exporting sessions trained before recording was set up.
+// Only the last pause is known; exercises are looked up in available and
+// reported by ID if no longer offered.
+func SessionEvents(session *models.TrainingSession, available []models.Exercise) []trainer.Event {
+	byID := make(map[string]models.Exercise, len(available))
+	for _, exercise := range available {
+		byID[exercise.ID] = exercise
+	}
+
+	base := trainer.Event{UserID: session.UserID, SessionID: session.SessionID}
+	at := func(eventType trainer.EventType, when time.Time) trainer.Event {
+		event := base
+		event.Type = eventType
+		event.At = when
+		return event
+	}
+
+	events := []trainer.Event{at(trainer.SessionStarted, session.StartTime)}
+	for i := range session.Progress {
+		progress := session.Progress[i]
+		if progress.ExerciseID == "" {
+			continue
+		}
+		exercise, ok := byID[progress.ExerciseID]
+		if !ok {
+			exercise = models.Exercise{ID: progress.ExerciseID, Title: progress.ExerciseID}
+		}
+		exerciseEvent := func(eventType trainer.EventType, when time.Time) trainer.Event {
+			event := at(eventType, when)
+			event.Exercise = &exercise
+			event.Progress = &progress
+			return event
+		}
+
+		events = append(events, exerciseEvent(trainer.ExerciseStarted, progress.StartTime))
+		for j := range progress.AttemptHistory {
+			attempt := progress.AttemptHistory[j]
+			event := exerciseEvent(trainer.AnswerSubmitted, attempt.SubmittedAt)
+			event.Attempt = &attempt
+			events = append(events, event)
+		}
+		if progress.CompletedAt != nil {
+			events = append(events, exerciseEvent(trainer.ExerciseCompleted, *progress.CompletedAt))
+		}
+	}
+
+	var end trainer.Event
+	switch session.Status {
+	case models.SessionPaused:
+		paused := session.LastActivity
+		if session.PausedAt != nil {
+			paused = *session.PausedAt
+		}
+		end = at(trainer.SessionPaused, paused)
+	case models.SessionCompleted:
+		end = at(trainer.SessionCompleted, session.LastActivity)
+	case models.SessionAbandoned:
+		end = at(trainer.SessionAbandoned, session.LastActivity)
+	}
+	if end.Type != "" {
+		end.ActiveTime = session.ActiveTime
+		events = append(events, end)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At.Before(events[j].At) })
+	return events
+}
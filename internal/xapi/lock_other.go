@@ -0,0 +1,22 @@
+//go:build !unix
+
+package xapi
+
+import "sync"
+
+// lockFlush does nothing where flock is unavailable: a lock file left by a
+// crash would stop every later flush, so only the flushes of one client
+// are kept apart
+func lockFlush(path string) (func(), error) {
+	return func() {}, nil
+}
+
+// spoolMu stands in for lockSpool's file lock within one process
+var spoolMu sync.Mutex
+
+// lockSpool keeps appends and flushes of this process apart where flock
+// is unavailable
+func lockSpool(path string) (func(), error) {
+	spoolMu.Lock()
+	return spoolMu.Unlock, nil
+}
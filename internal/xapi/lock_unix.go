@@ -0,0 +1,51 @@
+//go:build unix
+
+package xapi
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// lockFlush takes a non-blocking exclusive flock on the lock file at path,
+// failing with ErrFlushing while another flush holds it. The kernel
+// releases it if the process dies, so crashes leave no stale locks.
+func lockFlush(path string) (func(), error) {
+	unlock, err := flock(path, syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return nil, ErrFlushing
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock buffer: %w", err)
+	}
+	return unlock, nil
+}
+
+// lockSpool waits for an exclusive flock on the lock file at path. It is
+// only held to append to a spool or move it aside, so waits are short.
+func lockSpool(path string) (func(), error) {
+	unlock, err := flock(path, syscall.LOCK_EX)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock spool: %w", err)
+	}
+	return unlock, nil
+}
+
+// flock opens the lock file at path and locks it as how says
+func flock(path string, how int) (func(), error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), how); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,93 @@
+package xapi
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// Recorder listens to a trainer and sends the statements its events record
+// to a sink. Sending happens in the background, so a slow LRS never holds
+// up training; statements that pile up meanwhile go in one batch.
+type Recorder struct {
+	builder Builder
+	sink    Sink
+
+	mu      sync.Mutex
+	queue   []Statement
+	pending chan struct{} // Signalled when statements are queued
+	closed  bool
+	errs    []error
+	done    chan struct{}
+}
+
+// NewRecorder creates a recorder sending statements to sink. Close it once
+// training ends to send what's left.
+func NewRecorder(builder Builder, sink Sink) *Recorder {
+	r := &Recorder{
+		builder: builder,
+		sink:    sink,
+		pending: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Event implements trainer.Listener
+func (r *Recorder) Event(event trainer.Event) {
+	statements := r.builder.Statements(event)
+	if len(statements) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.queue = append(r.queue, statements...)
+	select {
+	case r.pending <- struct{}{}:
+	default:
+	}
+}
+
+// Close sends the statements still queued and stops the recorder,
+// returning any errors sending statements
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.pending)
+	}
+	r.mu.Unlock()
+
+	<-r.done
+	return errors.Join(r.errs...)
+}
+
+// run sends queued statements until the recorder is closed
+func (r *Recorder) run() {
+	defer close(r.done)
+	for range r.pending {
+		r.send()
+	}
+	r.send()
+}
+
+// send sends the statements queued so far
+func (r *Recorder) send() {
+	r.mu.Lock()
+	statements := r.queue
+	r.queue = nil
+	r.mu.Unlock()
+
+	if len(statements) == 0 {
+		return
+	}
+	if err := r.sink.Send(statements); err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
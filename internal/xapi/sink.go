@@ -0,0 +1,329 @@
+package xapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives statements
+type Sink interface {
+	Send(statements []Statement) error
+}
+
+// Sinks sends statements to every sink in turn
+type Sinks []Sink
+
+// Send implements Sink
+func (s Sinks) Send(statements []Statement) error {
+	var failures []error
+	for _, sink := range s {
+		if err := sink.Send(statements); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+// Spool appends statements to a file of newline-delimited JSON, one
+// statement per line. Appends hold the lock file at Path + ".spool.lock",
+// so a flush moving the file aside can't take it mid-write.
+type Spool struct {
+	Path string
+}
+
+// lock waits for the spool's lock
+func (s *Spool) lock() (func(), error) {
+	return lockSpool(s.Path + ".spool.lock")
+}
+
+// Send implements Sink
+func (s *Spool) Send(statements []Statement) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	var lines bytes.Buffer
+	for _, statement := range statements {
+		data, err := json.Marshal(statement)
+		if err != nil {
+			return fmt.Errorf("failed to marshal statement: %w", err)
+		}
+		lines.Write(append(data, '\n'))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	file, err := os.OpenFile(s.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open spool: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(lines.Bytes()); err != nil {
+		return fmt.Errorf("failed to write spool: %w", err)
+	}
+	return file.Sync()
+}
+
+// ReadSpool reads the statements in a spool file. A missing file holds no
+// statements, and a last line cut short by a crash is ignored.
+func ReadSpool(path string) ([]Statement, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	var statements []Statement
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var statement Statement
+		if err := json.Unmarshal([]byte(line), &statement); err != nil {
+			if i == len(lines)-1 {
+				break // A write cut short, with no newline yet
+			}
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// RejectedError is returned when an LRS refuses statements as invalid, so
+// sending them again would not help
+type RejectedError struct {
+	Status int
+	Body   string
+}
+
+// Error implements error
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("LRS rejected statements: %d %s: %s", e.Status, http.StatusText(e.Status), e.Body)
+}
+
+// ErrFlushing is returned by Flush while another client is sending the
+// buffered statements
+var ErrFlushing = errors.New("another trainer is sending the buffered statements")
+
+// batchSize limits the statements sent in one request
+const batchSize = 100
+
+// Client posts statements to a Learning Record Store. Requests that fail
+// in a way that may pass later are retried with exponential backoff; if
+// the LRS still can't be reached the statements are kept in Buffer and
+// sent, oldest first, before the next statements.
+type Client struct {
+	Endpoint   string // LRS base URL; statements go to Endpoint/statements
+	Username   string // For HTTP basic authentication, if set
+	Password   string
+	HTTPClient *http.Client
+	Retries    int           // Extra tries after a failure that may pass
+	Backoff    time.Duration // Wait before the first retry, doubling each time
+	Buffer     *Spool        // Holds statements while the LRS can't be reached; nil to fail instead
+
+	mu sync.Mutex // Held while flushing; a lock file beside Buffer keeps other processes out too
+}
+
+// NewClient creates a client for the LRS at endpoint with default retries,
+// buffering statements it can't send in buffer
+func NewClient(endpoint string, buffer *Spool) *Client {
+	return &Client{
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		Retries:    3,
+		Backoff:    500 * time.Millisecond,
+		Buffer:     buffer,
+	}
+}
+
+// Send implements Sink. Statements the LRS can't take now are buffered
+// rather than reported; statements it rejects are reported and dropped.
+// Statements already buffered get one try without retries, so a down LRS
+// doesn't hold up every send; if it fails, or another client is sending
+// them, the new statements are buffered behind them straight away.
+func (c *Client) Send(statements []Statement) error {
+	var rejected *RejectedError
+	if err := c.flush(0); err != nil && !errors.As(err, &rejected) {
+		return c.buffer(statements, err)
+	}
+
+	err := c.post(statements, c.Retries)
+	if err != nil && !errors.As(err, &rejected) {
+		return c.buffer(statements, err)
+	}
+	return err
+}
+
+// moveAside renames the buffer to sending once no statement is being
+// appended to it
+func (c *Client) moveAside(sending string) error {
+	unlock, err := c.Buffer.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return os.Rename(c.Buffer.Path, sending)
+}
+
+// buffer keeps statements that couldn't be sent, failing with the reason
+// if there is no buffer
+func (c *Client) buffer(statements []Statement, reason error) error {
+	if c.Buffer == nil {
+		return reason
+	}
+	return c.Buffer.Send(statements)
+}
+
+// Flush sends the statements waiting in the buffer. Statements the LRS
+// rejects are dropped and reported. Only one client flushes a buffer at a
+// time; the others get ErrFlushing.
+func (c *Client) Flush() error {
+	return c.flush(c.Retries)
+}
+
+// flush sends the buffered statements, retrying each batch up to retries
+// times
+func (c *Client) flush(retries int) error {
+	if c.Buffer == nil {
+		return nil
+	}
+	// Statements being sent are moved aside, so new ones can be buffered
+	// meanwhile; a flush cut short leaves them there for the next one
+	sending := c.Buffer.Path + ".sending"
+	if !exists(c.Buffer.Path) && !exists(sending) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	unlock, err := lockFlush(c.Buffer.Path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var failures []error
+	for {
+		if _, err := os.Stat(sending); errors.Is(err, os.ErrNotExist) {
+			err := c.moveAside(sending)
+			if errors.Is(err, os.ErrNotExist) {
+				return errors.Join(failures...)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to flush buffer: %w", err)
+			}
+		}
+
+		statements, err := ReadSpool(sending)
+		if err != nil {
+			return err
+		}
+		for start := 0; start < len(statements); start += batchSize {
+			err := c.post(statements[start:min(start+batchSize, len(statements))], retries)
+			var rejected *RejectedError
+			if errors.As(err, &rejected) {
+				failures = append(failures, err)
+			} else if err != nil {
+				return err
+			}
+		}
+		if err := os.Remove(sending); err != nil {
+			return fmt.Errorf("failed to flush buffer: %w", err)
+		}
+	}
+}
+
+// Pending counts the statements waiting in the buffer
+func (c *Client) Pending() (int, error) {
+	if c.Buffer == nil {
+		return 0, nil
+	}
+	pending := 0
+	for _, path := range []string{c.Buffer.Path + ".sending", c.Buffer.Path} {
+		statements, err := ReadSpool(path)
+		if err != nil {
+			return 0, err
+		}
+		pending += len(statements)
+	}
+	return pending, nil
+}
+
+// exists reports whether there is a file at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// post sends one batch of statements, retrying failures that may pass up
+// to retries times
+func (c *Client) post(statements []Statement, retries int) error {
+	if len(statements) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(statements)
+	if err != nil {
+		return fmt.Errorf("failed to marshal statements: %w", err)
+	}
+
+	wait := c.Backoff
+	for attempt := 0; ; attempt++ {
+		err := c.request(body)
+		var rejected *RejectedError
+		if err == nil || errors.As(err, &rejected) || attempt >= retries {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// request makes one POST of a batch of statements
+func (c *Client) request(body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.Endpoint, "/")+"/statements", bytes.NewReader(body))
+	if err != nil {
+		return &RejectedError{Body: err.Error()}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Experience-API-Version", Version)
+	if c.Username != "" {
+		request.SetBasicAuth(c.Username, c.Password)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to reach LRS: %w", err)
+	}
+	defer response.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+
+	switch {
+	case response.StatusCode/100 == 2, response.StatusCode == http.StatusConflict:
+		return nil // A conflict means the LRS already has statements with these IDs
+	case response.StatusCode == http.StatusTooManyRequests, response.StatusCode >= 500:
+		return fmt.Errorf("LRS unavailable: %s", response.Status)
+	}
+	return &RejectedError{Status: response.StatusCode, Body: strings.TrimSpace(string(detail))}
+}
@@ -0,0 +1,146 @@
+package xapi
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"time"
+)
+
+// Version is the xAPI version statements are sent as
+const Version = "1.0.3"
+
+// Statement records one learning experience: an actor, a verb and the
+// activity it applies to
+type Statement struct {
+	ID        string    `json:"id"`
+	Actor     Agent     `json:"actor"`
+	Verb      Verb      `json:"verb"`
+	Object    Activity  `json:"object"`
+	Result    *Result   `json:"result,omitempty"`
+	Context   *Context  `json:"context,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Agent identifies a learner by their account on a system
+type Agent struct {
+	ObjectType string   `json:"objectType"`
+	Name       string   `json:"name,omitempty"`
+	Account    *Account `json:"account,omitempty"`
+}
+
+// Account is a learner's user name on the system at HomePage
+type Account struct {
+	HomePage string `json:"homePage"`
+	Name     string `json:"name"`
+}
+
+// Verb is the action a statement records
+type Verb struct {
+	ID      string            `json:"id"`
+	Display map[string]string `json:"display"`
+}
+
+// adlVerb returns one of the ADL vocabulary's verbs
+func adlVerb(name string) Verb {
+	return Verb{ID: "http://adlnet.gov/expapi/verbs/" + name, Display: map[string]string{"en-US": name}}
+}
+
+// Verbs the trainer records, from the ADL vocabulary
+var (
+	Attempted  = adlVerb("attempted")
+	Answered   = adlVerb("answered")
+	Completed  = adlVerb("completed")
+	Passed     = adlVerb("passed")
+	Failed     = adlVerb("failed")
+	Suspended  = adlVerb("suspended")
+	Resumed    = adlVerb("resumed")
+	Terminated = adlVerb("terminated")
+)
+
+// Activity types from the ADL vocabulary
+const (
+	CourseType      = "http://adlnet.gov/expapi/activities/course"
+	ModuleType      = "http://adlnet.gov/expapi/activities/module"
+	InteractionType = "http://adlnet.gov/expapi/activities/cmi.interaction"
+)
+
+// Activity is what a statement's verb applies to
+type Activity struct {
+	ObjectType string              `json:"objectType"`
+	ID         string              `json:"id"`
+	Definition *ActivityDefinition `json:"definition,omitempty"`
+}
+
+// ActivityDefinition describes an activity
+type ActivityDefinition struct {
+	Name        map[string]string `json:"name,omitempty"`
+	Description map[string]string `json:"description,omitempty"`
+	Type        string            `json:"type,omitempty"`
+}
+
+// Result is the outcome of the experience a statement records
+type Result struct {
+	Score      *Score         `json:"score,omitempty"`
+	Success    *bool          `json:"success,omitempty"`
+	Completion *bool          `json:"completion,omitempty"`
+	Response   string         `json:"response,omitempty"`
+	Duration   string         `json:"duration,omitempty"` // ISO 8601, such as PT1H30M
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Score is a result's score; Scaled runs from -1 to 1
+type Score struct {
+	Scaled float64 `json:"scaled"`
+	Raw    float64 `json:"raw"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// Context places a statement within a registration and parent activities
+type Context struct {
+	Registration      string             `json:"registration,omitempty"`
+	ContextActivities *ContextActivities `json:"contextActivities,omitempty"`
+	Platform          string             `json:"platform,omitempty"`
+	Extensions        map[string]any     `json:"extensions,omitempty"`
+}
+
+// ContextActivities lists the activities a statement's object belongs to
+type ContextActivities struct {
+	Parent   []Activity `json:"parent,omitempty"`
+	Grouping []Activity `json:"grouping,omitempty"`
+}
+
+// namespace seeds the name-based UUIDs of statements and registrations
+var namespace = [16]byte{0x6f, 0x1c, 0x3a, 0x52, 0x9d, 0x0e, 0x4b, 0x7a, 0x8c, 0x21, 0x5e, 0x93, 0xd4, 0x07, 0xb6, 0x1f}
+
+// nameUUID returns the version 5 UUID for a name, so the same statement
+// built twice has the same ID and an LRS stores it once
+func nameUUID(name string) string {
+	hash := sha1.New()
+	hash.Write(namespace[:])
+	hash.Write([]byte(name))
+	sum := hash.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// isoDuration formats a duration as ISO 8601, to the hundredth of a second
+func isoDuration(d time.Duration) string {
+	d = max(d, 0).Round(10 * time.Millisecond)
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	seconds := (d % time.Minute).Seconds()
+
+	text := "PT"
+	if hours > 0 {
+		text += fmt.Sprintf("%dH", hours)
+	}
+	if minutes > 0 {
+		text += fmt.Sprintf("%dM", minutes)
+	}
+	if seconds > 0 || text == "PT" {
+		text += fmt.Sprintf("%gS", seconds)
+	}
+	return text
+}
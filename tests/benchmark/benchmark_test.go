@@ -20,7 +20,7 @@ func BenchmarkExerciseRegistry(b *testing.B) {
 func BenchmarkGetAllExercises(b *testing.B) {
 	registry := exercises.NewRegistry()
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		registry.GetAll()
 	}
@@ -30,9 +30,9 @@ func BenchmarkVariablesValidator(b *testing.B) {
 	exercise := exercises.GetVariablesExercise()
 	validator := exercise.Challenges[0].Validator
 	solution := `var name string = "John"`
-	
+
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		validator(solution)
 	}
@@ -42,9 +42,9 @@ func BenchmarkFunctionsValidator(b *testing.B) {
 	exercise := exercises.GetFunctionsExercise()
 	validator := exercise.Challenges[0].Validator
 	solution := `func add(a, b int) int { return a + b }`
-	
+
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		validator(solution)
 	}
@@ -60,9 +60,9 @@ func BenchmarkTrainerCreation(b *testing.B) {
 		AdaptivePacing: true,
 		CognitiveLoad:  models.Beginner,
 	}
-	
+
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		tempDir := b.TempDir()
 		sessionStorage := storage.NewFileSessionStorage(tempDir)
@@ -73,7 +73,7 @@ func BenchmarkTrainerCreation(b *testing.B) {
 func BenchmarkSessionSave(b *testing.B) {
 	tempDir := b.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	session := &models.TrainingSession{
 		UserID:       "bench-user",
 		SessionID:    "bench-session",
@@ -107,9 +107,9 @@ func BenchmarkSessionSave(b *testing.B) {
 			},
 		},
 	}
-	
+
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		session.SessionID = "bench-session-" + strconv.Itoa(i) // Make unique
 		sessionStorage.SaveSession(session)
@@ -119,7 +119,7 @@ func BenchmarkSessionSave(b *testing.B) {
 func BenchmarkSessionLoad(b *testing.B) {
 	tempDir := b.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	// Pre-create session to load
 	session := &models.TrainingSession{
 		UserID:       "bench-user",
@@ -145,10 +145,10 @@ func BenchmarkSessionLoad(b *testing.B) {
 			},
 		},
 	}
-	
+
 	sessionStorage.SaveSession(session)
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		sessionStorage.LoadSession("bench-load-session")
 	}
@@ -157,7 +157,7 @@ func BenchmarkSessionLoad(b *testing.B) {
 func BenchmarkListSessions(b *testing.B) {
 	tempDir := b.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	// Pre-create multiple sessions
 	for i := 0; i < 10; i++ {
 		session := &models.TrainingSession{
@@ -168,9 +168,9 @@ func BenchmarkListSessions(b *testing.B) {
 		}
 		sessionStorage.SaveSession(session)
 	}
-	
+
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		sessionStorage.ListSessions("bench-user")
 	}
@@ -179,10 +179,10 @@ func BenchmarkListSessions(b *testing.B) {
 func BenchmarkSessionResume(b *testing.B) {
 	tempDir := b.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	// Pre-create session to resume
 	session := &models.TrainingSession{
 		UserID:       "bench-user",
@@ -208,15 +208,141 @@ func BenchmarkSessionResume(b *testing.B) {
 			},
 		},
 	}
-	
+
 	sessionStorage.SaveSession(session)
 	b.ResetTimer()
-	
+
 	for i := 0; i < b.N; i++ {
 		// Reset session to paused state for each iteration
 		session.Status = models.SessionPaused
 		sessionStorage.SaveSession(session)
-		
+
 		trainer.ResumeSession("bench-resume-session", exerciseList, sessionStorage)
 	}
-}
\ No newline at end of file
+}
+
+// largeHistory is the number of sessions the store comparisons are run
+// against, spread over largeHistoryUsers users
+const (
+	largeHistory      = 2000
+	largeHistoryUsers = 50
+)
+
+// seedLargeHistory saves a team machine's worth of sessions, started an
+// hour apart, to sessionStorage
+func seedLargeHistory(b *testing.B, sessionStorage storage.SessionStorage) time.Time {
+	b.Helper()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	statuses := []models.SessionStatus{models.SessionCompleted, models.SessionPaused, models.SessionAbandoned}
+	for i := 0; i < largeHistory; i++ {
+		session := &models.TrainingSession{
+			UserID:    "user-" + strconv.Itoa(i%largeHistoryUsers),
+			SessionID: "session-" + strconv.Itoa(i),
+			StartTime: start.Add(time.Duration(i) * time.Hour),
+			Status:    statuses[i%len(statuses)],
+			Config: models.TrainerConfig{
+				MaxAttempts: 3,
+				TimeLimit:   time.Hour,
+				ShowHints:   true,
+			},
+			Progress: []models.LearningProgress{
+				{ExerciseID: "variables", StartTime: start, Attempts: 2, Score: 85.0, TimeSpent: 4 * time.Minute},
+				{ExerciseID: "functions", StartTime: start, Attempts: 3, Score: 70.0, TimeSpent: 6 * time.Minute},
+			},
+		}
+		if err := sessionStorage.SaveSession(session); err != nil {
+			b.Fatalf("Failed to save session: %v", err)
+		}
+	}
+	return start
+}
+
+// openLogStore opens a log store for a benchmark
+func openLogStore(b *testing.B) *storage.LogSessionStorage {
+	b.Helper()
+	logStorage, err := storage.OpenLogSessionStorage(b.TempDir())
+	if err != nil {
+		b.Fatalf("Failed to open log store: %v", err)
+	}
+	b.Cleanup(func() { logStorage.Close() })
+	return logStorage
+}
+
+func BenchmarkFileStoreListSessionsLargeHistory(b *testing.B) {
+	sessionStorage := storage.NewFileSessionStorage(b.TempDir())
+	seedLargeHistory(b, sessionStorage)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sessionStorage.ListSessions("user-7")
+	}
+}
+
+func BenchmarkLogStoreListSessionsLargeHistory(b *testing.B) {
+	sessionStorage := openLogStore(b)
+	seedLargeHistory(b, sessionStorage)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sessionStorage.ListSessions("user-7")
+	}
+}
+
+func BenchmarkFileStoreQueryLargeHistory(b *testing.B) {
+	sessionStorage := storage.NewFileSessionStorage(b.TempDir())
+	start := seedLargeHistory(b, sessionStorage)
+	query := storage.SessionQuery{Status: models.SessionPaused, From: start.AddDate(0, 0, 30), To: start.AddDate(0, 0, 37)}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sessionStorage.QuerySessions(query)
+	}
+}
+
+func BenchmarkLogStoreQueryLargeHistory(b *testing.B) {
+	sessionStorage := openLogStore(b)
+	start := seedLargeHistory(b, sessionStorage)
+	query := storage.SessionQuery{Status: models.SessionPaused, From: start.AddDate(0, 0, 30), To: start.AddDate(0, 0, 37)}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sessionStorage.QuerySessions(query)
+	}
+}
+
+func BenchmarkLogStoreSessionSave(b *testing.B) {
+	sessionStorage := openLogStore(b)
+	session := &models.TrainingSession{
+		UserID:    "bench-user",
+		SessionID: "bench-session",
+		StartTime: time.Now(),
+		Status:    models.SessionActive,
+		Progress: []models.LearningProgress{
+			{ExerciseID: "variables", StartTime: time.Now(), Attempts: 2, Score: 85.0},
+		},
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		sessionStorage.SaveSession(session)
+	}
+}
+
+func BenchmarkLogStoreOpenLargeHistory(b *testing.B) {
+	dir := b.TempDir()
+	sessionStorage, err := storage.OpenLogSessionStorage(dir)
+	if err != nil {
+		b.Fatalf("Failed to open log store: %v", err)
+	}
+	seedLargeHistory(b, sessionStorage)
+	sessionStorage.Close()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		reopened, err := storage.OpenLogSessionStorage(dir)
+		if err != nil {
+			b.Fatalf("Failed to open log store: %v", err)
+		}
+		reopened.Close()
+	}
+}
@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/adaptive"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+func TestAdaptiveSupportAndSkip(t *testing.T) {
+	beginner := adaptive.New(models.Beginner)
+	if _, ok := beginner.Support(adaptive.ChallengeResult{Attempts: 1, HintsUsed: 1, Solved: true}); !ok {
+		t.Error("A beginner who needed a hint should get an extra example")
+	}
+	advanced := adaptive.New(models.Advanced)
+	if _, ok := advanced.Support(adaptive.ChallengeResult{Attempts: 3, Solved: true}); ok {
+		t.Error("An advanced learner who solved the challenge should not get an extra example")
+	}
+
+	fluent := adaptive.ChallengeResult{Attempts: 1, Solved: true}
+	intermediate := adaptive.New(models.Intermediate)
+	if skip, _ := intermediate.Skip([]adaptive.ChallengeResult{fluent}, 3); skip != 0 {
+		t.Errorf("One fluent answer should not be enough at intermediate level, skipped %d", skip)
+	}
+	if skip, _ := intermediate.Skip([]adaptive.ChallengeResult{fluent, fluent}, 3); skip != 2 {
+		t.Errorf("Expected to skip to the final challenge, skipped %d", skip)
+	}
+	if skip, _ := intermediate.Skip([]adaptive.ChallengeResult{fluent, fluent}, 1); skip != 0 {
+		t.Errorf("The final challenge should never be skipped, skipped %d", skip)
+	}
+}
+
+func TestAdaptiveLevelChanges(t *testing.T) {
+	strong := adaptive.Performance{Score: 95, TimeRatio: 0.5}
+	weak := adaptive.Performance{ExerciseID: "functions", Score: 40, TimeRatio: 2}
+
+	engine := adaptive.New(models.Beginner)
+	if _, _, changed := engine.Adjust([]adaptive.Performance{strong}); changed {
+		t.Error("One strong exercise should not change the level")
+	}
+	if action, _, changed := engine.Adjust([]adaptive.Performance{strong, strong}); !changed || action != models.ActionLevelUp || engine.Level != models.Intermediate {
+		t.Fatalf("Expected to level up to intermediate, got %v at %v", action, engine.Level)
+	}
+	if _, _, changed := engine.Adjust([]adaptive.Performance{strong, strong, strong}); changed {
+		t.Error("Exercises counted towards the last change should not count again")
+	}
+
+	action, reason, changed := engine.Adjust([]adaptive.Performance{strong, strong, strong, weak})
+	if !changed || action != models.ActionLevelDown || engine.Level != models.Beginner {
+		t.Fatalf("Expected to level down to beginner, got %v at %v", action, engine.Level)
+	}
+	if !strings.Contains(reason, "functions") {
+		t.Errorf("Expected the reason to name the exercise, got %q", reason)
+	}
+}
+
+func TestAdaptivePacingInSession(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	list := []models.Exercise{pointExercise(), pointExercise(), pointExercise()}
+	for i := range list {
+		list[i].ID = fmt.Sprintf("points-%d", i+1)
+		list[i].SupportExamples = []models.Example{{Title: "Another Point", Code: "type P struct{ X int }", Explanation: "Fields go inside braces."}}
+	}
+
+	config := models.TrainerConfig{MaxAttempts: 3, AdaptivePacing: true, CognitiveLoad: models.Intermediate}
+	cltTrainer := trainer.NewCLTTrainer(list, config, "test-user", sessionStorage)
+
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: []string{
+		"",
+		"skip",                 // Not solved: a support example follows
+		answer, answer, answer, // Fluent, but only the final challenge remains by then
+		"",
+		answer, answer, // Two fluent answers: skip straight to the final challenge
+		answer,
+		"",
+		"pause",
+	}})
+	cltTrainer.Start()
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one paused session, got %d (%v)", len(sessions), err)
+	}
+
+	decisions := sessions[0].Decisions
+	if len(decisions) != 2 || decisions[0].Action != models.ActionExtraExample || decisions[1].Action != models.ActionSkipChallenges {
+		t.Fatalf("Expected a support example then a skip, got %+v", decisions)
+	}
+	if decision := decisions[0]; decision.Example != "Another Point" || decision.Reason == "" || decision.At.IsZero() {
+		t.Errorf("Expected an audited support example decision, got %+v", decision)
+	}
+	if decision := decisions[1]; decision.ExerciseID != "points-2" || decision.Challenge != 1 || decision.Skipped != 1 {
+		t.Errorf("Expected challenge 3 of points-2 to be skipped, got %+v", decision)
+	}
+	if !strings.Contains(output.String(), "Fields go inside braces.") {
+		t.Error("Expected the support example to be shown")
+	}
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/analytics"
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// completedProgress is an exercise completed at the given time
+func completedProgress(exerciseID string, at time.Time, score float64, minutes int, hints ...int) models.LearningProgress {
+	progress := models.LearningProgress{
+		ExerciseID:  exerciseID,
+		StartTime:   at.Add(-time.Duration(minutes) * time.Minute),
+		CompletedAt: &at,
+		Attempts:    len(hints),
+		Score:       score,
+		TimeSpent:   time.Duration(minutes) * time.Minute,
+	}
+	for i, used := range hints {
+		progress.Challenges = append(progress.Challenges, models.ChallengeProgress{Challenge: i, Attempts: 1, HintsUsed: used, Solved: true})
+		progress.HintsUsed += used
+	}
+	return progress
+}
+
+// analyticsHistory is three sessions over two weeks ending on Wednesday
+// 2026-06-10
+func analyticsHistory() (time.Time, []*models.TrainingSession) {
+	now := time.Date(2026, 6, 10, 18, 0, 0, 0, time.UTC)
+	lastWeek := now.AddDate(0, 0, -7)
+	return now, []*models.TrainingSession{
+		{
+			SessionID: "s1", UserID: "alice", Status: models.SessionCompleted, StartTime: lastWeek,
+			Progress: []models.LearningProgress{
+				completedProgress("variables", lastWeek.Add(time.Hour), 70, 10, 1, 0),
+				completedProgress("functions", lastWeek.Add(2*time.Hour), 50, 30, 1, 1),
+			},
+		},
+		{
+			SessionID: "s2", UserID: "alice", Status: models.SessionCompleted, StartTime: now.Add(-3 * time.Hour),
+			Progress: []models.LearningProgress{
+				completedProgress("variables", now.Add(-2*time.Hour), 90, 4, 0, 0),
+			},
+		},
+		{
+			SessionID: "s3", UserID: "alice", Status: models.SessionPaused, StartTime: now.Add(-time.Hour),
+			Progress: []models.LearningProgress{
+				{ExerciseID: "structs", StartTime: now.Add(-time.Hour), Attempts: 2, HintsUsed: 1, TimeSpent: 5 * time.Minute},
+				{},
+			},
+		},
+	}
+}
+
+func TestAnalyticsCompute(t *testing.T) {
+	now, sessions := analyticsHistory()
+	exercises := []models.Exercise{
+		{ID: "variables", Title: "Variables", EstimatedTime: 8},
+		{ID: "functions", Title: "Functions", EstimatedTime: 15},
+		{ID: "structs", Title: "Structs", EstimatedTime: 20},
+		{ID: "interfaces", Title: "Interfaces", EstimatedTime: 20},
+	}
+	report := analytics.Compute("alice", sessions, exercises, analytics.Options{Now: now, Period: analytics.Week, Periods: 3, Weakest: 2})
+
+	if report.Sessions != 3 || report.ByStatus[models.SessionCompleted] != 2 || report.ByStatus[models.SessionPaused] != 1 {
+		t.Errorf("Expected 3 sessions, 2 completed and 1 paused, got %d, %v", report.Sessions, report.ByStatus)
+	}
+	if report.ExercisesCompleted != 3 || math.Abs(report.AverageScore-70) > 1e-9 {
+		t.Errorf("Expected 3 completions averaging 70, got %d averaging %.2f", report.ExercisesCompleted, report.AverageScore)
+	}
+	if report.MinutesSpent != 49 {
+		t.Errorf("Expected 49 minutes spent, got %.1f", report.MinutesSpent)
+	}
+	// Hints on 3 of 6 challenges, plus the structs exercise saved without
+	// challenge detail counted as one hinted challenge
+	if math.Abs(report.HintDependency-4.0/7) > 1e-9 {
+		t.Errorf("Expected a hint dependency of 4/7, got %.3f", report.HintDependency)
+	}
+
+	// Weeks start on Monday: June 8th holds this week's completion, June
+	// 1st last week's two
+	if len(report.Timeline) != 3 {
+		t.Fatalf("Expected 3 weeks of timeline, got %d", len(report.Timeline))
+	}
+	for i, want := range []struct {
+		start string
+		count int
+	}{{"2026-05-25", 0}, {"2026-06-01", 2}, {"2026-06-08", 1}} {
+		if got := report.Timeline[i]; got.Start.Format("2006-01-02") != want.start || got.Completed != want.count {
+			t.Errorf("Timeline[%d]: expected %s with %d, got %s with %d", i, want.start, want.count, got.Start.Format("2006-01-02"), got.Completed)
+		}
+	}
+
+	if len(report.Exercises) != 4 {
+		t.Fatalf("Expected every exercise in the report, got %d", len(report.Exercises))
+	}
+	variables := report.Exercises[0]
+	if variables.ExerciseID != "variables" || variables.Completed != 2 || variables.ScoreTrend != 20 || variables.AverageScore != 80 {
+		t.Errorf("Expected variables completed twice, averaging 80 and up 20, got %+v", variables)
+	}
+	if len(variables.Scores) != 2 || variables.Scores[0] != 70 || variables.Scores[1] != 90 {
+		t.Errorf("Expected variables' scores oldest first, got %v", variables.Scores)
+	}
+	if variables.AverageMinutes != 7 || math.Abs(variables.TimeRatio-7.0/8) > 1e-9 {
+		t.Errorf("Expected 7 minutes against an 8 minute estimate, got %.1f (%.2fx)", variables.AverageMinutes, variables.TimeRatio)
+	}
+	if structs := report.Exercises[2]; structs.Started != 1 || structs.Completed != 0 || structs.HintDependency != 1 {
+		t.Errorf("Expected structs started once and hinted, got %+v", structs)
+	}
+	if interfaces := report.Exercises[3]; interfaces.Started != 0 || len(interfaces.Scores) != 0 {
+		t.Errorf("Expected interfaces never practiced, got %+v", interfaces)
+	}
+
+	if len(report.Weakest) != 2 || report.Weakest[0] != "functions" || report.Weakest[1] != "variables" {
+		t.Errorf("Expected functions then variables as weakest, got %v", report.Weakest)
+	}
+}
+
+func TestAnalyticsExercisesNoLongerOffered(t *testing.T) {
+	now, sessions := analyticsHistory()
+	report := analytics.Compute("alice", sessions, nil, analytics.Options{Now: now, Period: analytics.Month, Periods: 1})
+
+	if len(report.Exercises) != 3 || report.Exercises[0].Title != "variables" {
+		t.Errorf("Expected practiced exercises reported by ID, got %+v", report.Exercises)
+	}
+	if len(report.Timeline) != 1 || report.Timeline[0].Completed != 3 {
+		t.Errorf("Expected June to hold all 3 completions, got %+v", report.Timeline)
+	}
+	if len(report.Weakest) != 0 {
+		t.Errorf("Expected no weakest exercises when none are asked for, got %v", report.Weakest)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for _, name := range []string{"day", "week", "month"} {
+		if _, err := analytics.ParsePeriod(name); err != nil {
+			t.Errorf("Expected %q to parse, got %v", name, err)
+		}
+	}
+	if _, err := analytics.ParsePeriod("year"); err == nil {
+		t.Error("Expected an unknown period to fail")
+	}
+}
@@ -0,0 +1,563 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/analytics"
+	"github.com/cmyers78/claude/internal/cli"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/report"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/xapi"
+)
+
+// cliRun runs one trainer command line in home with stdin, returning its
+// exit code and output
+func cliRun(t *testing.T, home, stdin string, env map[string]string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	app := &cli.App{
+		Stdin:   strings.NewReader(stdin),
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+		Getenv:  func(key string) string { return env[key] },
+		HomeDir: home,
+	}
+	code := app.Run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+// effectiveConfig runs 'config' and decodes the configuration it prints
+func effectiveConfig(t *testing.T, home string, args ...string) cli.Config {
+	t.Helper()
+	code, out, errOut := cliRun(t, home, "", nil, append([]string{"config"}, args...)...)
+	if code != cli.ExitOK {
+		t.Fatalf("config failed with %d: %s", code, errOut)
+	}
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	var config cli.Config
+	if err := json.Unmarshal([]byte(strings.Join(lines, "\n")), &config); err != nil {
+		t.Fatalf("Failed to decode config output: %v\n%s", err, out)
+	}
+	return config
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	home := t.TempDir()
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"help", []string{"help"}, cli.ExitOK},
+		{"unknown command", []string{"bogus"}, cli.ExitUsage},
+		{"unknown flag", []string{"list", "--bogus"}, cli.ExitUsage},
+		{"extra argument", []string{"list", "extra"}, cli.ExitUsage},
+		{"bad level", []string{"config", "--level", "expert"}, cli.ExitUsage},
+		{"unknown exercise", []string{"practice", "nope"}, cli.ExitNotFound},
+		{"unknown start exercise", []string{"start", "--from", "nope"}, cli.ExitNotFound},
+		{"unknown user", []string{"--user", "nobody", "list"}, cli.ExitNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, errOut := cliRun(t, home, "", nil, tt.args...); code != tt.want {
+				t.Errorf("Expected exit code %d, got %d: %s", tt.want, code, errOut)
+			}
+		})
+	}
+}
+
+func TestCLIConfigFile(t *testing.T) {
+	home := t.TempDir()
+	if code, _, errOut := cliRun(t, home, "", nil, "config", "init"); code != cli.ExitOK {
+		t.Fatalf("config init failed: %s", errOut)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".claude-trainer", "config.json")); err != nil {
+		t.Fatalf("Expected config init to write the config file: %v", err)
+	}
+
+	// Settings the file leaves out keep their defaults
+	path := filepath.Join(home, "trainer.json")
+	os.WriteFile(path, []byte(`{"session_store": "log", "trainer": {"max_attempts": 5, "cognitive_load": "intermediate"}}`), 0644)
+	config := effectiveConfig(t, home, "--config", path)
+	if config.SessionStore != cli.LogStore || config.Trainer.MaxAttempts != 5 || config.Trainer.CognitiveLoad != models.Intermediate {
+		t.Errorf("Expected the config file's settings, got %+v", config)
+	}
+	if config.Trainer.TimeLimit != cli.DefaultTrainerConfig().TimeLimit || !config.Trainer.ShowHints {
+		t.Errorf("Expected defaults for settings the file leaves out, got %+v", config.Trainer)
+	}
+
+	// Flags override the file
+	config = effectiveConfig(t, home, "--config", path, "--max-attempts", "2", "--no-hints", "--time-factor", "1.5")
+	if config.Trainer.MaxAttempts != 2 || config.Trainer.ShowHints || config.Trainer.ExerciseTimeFactor != 1.5 {
+		t.Errorf("Expected flags to override the config file, got %+v", config.Trainer)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "config", "--time-factor", "0"); code != cli.ExitUsage {
+		t.Errorf("Expected --time-factor 0 to exit with %d, got %d", cli.ExitUsage, code)
+	}
+
+	for _, invalid := range []string{`{"session_store": "sqlite"}`, `{"trainer": {"exercise_time_factor": 0}}`} {
+		os.WriteFile(path, []byte(invalid), 0644)
+		if code, _, _ := cliRun(t, home, "", nil, "--config", path, "list"); code != cli.ExitUsage {
+			t.Errorf("Expected invalid config file %s to exit with %d, got %d", invalid, cli.ExitUsage, code)
+		}
+	}
+	os.WriteFile(path, []byte(`{"max_attempts": 5}`), 0644)
+	if code, _, _ := cliRun(t, home, "", map[string]string{"TRAINER_CONFIG": path}, "list"); code != cli.ExitUsage {
+		t.Errorf("Expected unknown config fields to exit with %d, got %d", cli.ExitUsage, code)
+	}
+}
+
+func TestCLIUserProfiles(t *testing.T) {
+	home := t.TempDir()
+	if code, _, errOut := cliRun(t, home, "", nil, "user", "add", "alice", "--name", "Alice", "--level", "advanced", "--max-attempts", "4"); code != cli.ExitOK {
+		t.Fatalf("user add failed: %s", errOut)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "user", "add", "alice"); code != cli.ExitError {
+		t.Errorf("Expected adding alice twice to fail, got %d", code)
+	}
+
+	config := effectiveConfig(t, home, "--user", "alice")
+	if config.Trainer.MaxAttempts != 4 || config.Trainer.CognitiveLoad != models.Advanced {
+		t.Errorf("Expected alice's settings, got %+v", config.Trainer)
+	}
+
+	if code, _, errOut := cliRun(t, home, "", nil, "user", "switch", "alice"); code != cli.ExitOK {
+		t.Fatalf("user switch failed: %s", errOut)
+	}
+	_, out, _ := cliRun(t, home, "", nil, "user", "list")
+	if !strings.Contains(out, "* alice") {
+		t.Errorf("Expected alice to be marked current:\n%s", out)
+	}
+	if config := effectiveConfig(t, home); config.Trainer.MaxAttempts != 4 {
+		t.Errorf("Expected to run as alice after switching, got %+v", config.Trainer)
+	}
+	if config := effectiveConfig(t, home, "--user", "default"); config.Trainer.MaxAttempts != 3 {
+		t.Errorf("Expected --user to override the current user, got %+v", config.Trainer)
+	}
+
+	// Settings alice didn't choose follow later edits to the config file
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"trainer": {"max_attempts": 6, "time_limit": "20m"}}`), 0644)
+	if config := effectiveConfig(t, home, "--config", path); config.Trainer.MaxAttempts != 4 || config.Trainer.TimeLimit != 20*time.Minute {
+		t.Errorf("Expected alice's attempts over the config file's time limit, got %+v", config.Trainer)
+	}
+	if code, _, errOut := cliRun(t, home, "", nil, "user", "set", "alice", "--mastery-threshold", "0"); code != cli.ExitOK {
+		t.Fatalf("user set failed: %s", errOut)
+	}
+	if config := effectiveConfig(t, home); config.Trainer.MasteryThreshold != 0 || config.Trainer.MaxAttempts != 4 {
+		t.Errorf("Expected alice's explicit zero threshold and earlier settings kept, got %+v", config.Trainer)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "user", "set", "alice", "--mastery-threshold", "1"); code != cli.ExitUsage {
+		t.Errorf("Expected a threshold of 1 to exit with %d, got %d", cli.ExitUsage, code)
+	}
+
+	if code, _, errOut := cliRun(t, home, "secret\nsecret\n", nil, "user", "set", "alice", "--passphrase"); code != cli.ExitOK {
+		t.Fatalf("user set failed: %s", errOut)
+	}
+	if code, _, _ := cliRun(t, home, "wrong\n", nil, "list"); code != cli.ExitDenied {
+		t.Errorf("Expected a wrong passphrase to exit with %d, got %d", cli.ExitDenied, code)
+	}
+	if code, _, errOut := cliRun(t, home, "secret\n", nil, "list"); code != cli.ExitOK {
+		t.Errorf("Expected the passphrase to open alice's profile, got %d: %s", code, errOut)
+	}
+}
+
+func TestCLIListJSONAndExercises(t *testing.T) {
+	home := t.TempDir()
+	if code, _, errOut := cliRun(t, home, "", nil, "user", "add", "bob"); code != cli.ExitOK {
+		t.Fatalf("user add failed: %s", errOut)
+	}
+	sessions := storage.NewFileSessionStorage(filepath.Join(home, ".claude-trainer", "users", "bob", "sessions"))
+	for _, id := range []string{"bob_1", "bob_2"} {
+		sessions.SaveSession(&models.TrainingSession{UserID: "bob", SessionID: id, Status: models.SessionPaused})
+	}
+
+	code, out, errOut := cliRun(t, home, "", map[string]string{"TRAINER_USER": "bob"}, "list", "--json")
+	if code != cli.ExitOK {
+		t.Fatalf("list failed: %s", errOut)
+	}
+	var listed []models.TrainingSession
+	if err := json.Unmarshal([]byte(out), &listed); err != nil || len(listed) != 2 {
+		t.Errorf("Expected 2 sessions as JSON, got %d, %v", len(listed), err)
+	}
+	if _, out, _ := cliRun(t, home, "", nil, "list", "--json"); strings.TrimSpace(out) != "[]" {
+		t.Errorf("Expected the default user to see none of bob's sessions, got %s", out)
+	}
+
+	_, out, _ = cliRun(t, home, "", nil, "exercises", "--json")
+	var exercises []map[string]any
+	if err := json.Unmarshal([]byte(out), &exercises); err != nil || len(exercises) != 5 || exercises[0]["id"] != "variables" {
+		t.Errorf("Expected the 5 built-in exercises as JSON, got %v, %v", exercises, err)
+	}
+}
+
+func TestCLIStartFromExercise(t *testing.T) {
+	home := t.TempDir()
+
+	// Closing stdin straight away pauses the new session
+	if code, _, errOut := cliRun(t, home, "", nil, "start", "--from", "functions", "--storage-dir", filepath.Join(home, "data")); code != cli.ExitOK {
+		t.Fatalf("start failed: %s", errOut)
+	}
+	_, out, _ := cliRun(t, home, "", nil, "--storage-dir", filepath.Join(home, "data"), "list", "--json")
+	var listed []models.TrainingSession
+	if err := json.Unmarshal([]byte(out), &listed); err != nil || len(listed) != 1 {
+		t.Fatalf("Expected one session, got %d, %v", len(listed), err)
+	}
+	if got := listed[0].Exercises; len(got) != 2 || got[0] != "functions" || got[1] != "structs" {
+		t.Errorf("Expected the session to cover functions and structs, got %v", got)
+	}
+	if listed[0].Status != models.SessionPaused {
+		t.Errorf("Expected the session to be paused, got %s", listed[0].Status)
+	}
+}
+
+// seedSessions saves sessions for the default user, each started the given
+// number of days ago
+func seedSessions(t *testing.T, home string, ages map[string]int, statuses map[string]models.SessionStatus) {
+	t.Helper()
+	sessions := storage.NewFileSessionStorage(filepath.Join(home, ".claude-trainer", "users", "default", "sessions"))
+	now := time.Now()
+	for id, days := range ages {
+		session := &models.TrainingSession{
+			UserID:       "default",
+			SessionID:    id,
+			Status:       statuses[id],
+			StartTime:    now.AddDate(0, 0, -days),
+			CurrentIndex: days % 5,
+		}
+		if err := sessions.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+}
+
+// listedIDs runs list --json with args and returns the session IDs printed
+func listedIDs(t *testing.T, home string, args ...string) []string {
+	t.Helper()
+	code, out, errOut := cliRun(t, home, "", nil, append([]string{"list", "--json"}, args...)...)
+	if code != cli.ExitOK {
+		t.Fatalf("list failed with %d: %s", code, errOut)
+	}
+	var listed []models.TrainingSession
+	if err := json.Unmarshal([]byte(out), &listed); err != nil {
+		t.Fatalf("Failed to decode list output: %v", err)
+	}
+	ids := []string{}
+	for _, session := range listed {
+		ids = append(ids, session.SessionID)
+	}
+	return ids
+}
+
+func TestCLISessionManagement(t *testing.T) {
+	home := t.TempDir()
+	seedSessions(t, home,
+		map[string]int{"a": 40, "b": 20, "c": 3, "d": 1},
+		map[string]models.SessionStatus{"a": models.SessionAbandoned, "b": models.SessionAbandoned, "c": models.SessionPaused, "d": models.SessionCompleted})
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"all oldest first", nil, "a b c d"},
+		{"status", []string{"--status", "abandoned"}, "a b"},
+		{"since age", []string{"--since", "7d"}, "c d"},
+		{"until age", []string{"--until", "7d"}, "a b"},
+		{"reversed", []string{"--reverse"}, "d c b a"},
+		{"by progress", []string{"--sort", "progress"}, "a b d c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(listedIDs(t, home, tt.args...), " "); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "list", "--status", "done"); code != cli.ExitUsage {
+		t.Errorf("Expected an unknown status to exit with %d, got %d", cli.ExitUsage, code)
+	}
+
+	// A dry run deletes nothing
+	code, out, _ := cliRun(t, home, "", nil, "delete", "--status", "abandoned", "--older-than", "30d", "--dry-run")
+	if code != cli.ExitOK || !strings.Contains(out, "Would delete a ") || strings.Contains(out, "Would delete b ") {
+		t.Errorf("Expected the dry run to select only a, got %d:\n%s", code, out)
+	}
+	if got := len(listedIDs(t, home)); got != 4 {
+		t.Errorf("Expected the dry run to keep all 4 sessions, got %d", got)
+	}
+
+	if code, _, errOut := cliRun(t, home, "", nil, "delete", "--status", "abandoned", "--older-than", "30d"); code != cli.ExitOK {
+		t.Fatalf("delete failed: %s", errOut)
+	}
+	if got := strings.Join(listedIDs(t, home), " "); got != "b c d" {
+		t.Errorf("Expected only a to be deleted, got %q", got)
+	}
+
+	// Deleting by ID carries on past unknown sessions but reports them
+	if code, _, _ := cliRun(t, home, "", nil, "delete", "b", "missing", "c"); code != cli.ExitNotFound {
+		t.Errorf("Expected an unknown session to exit with %d, got %d", cli.ExitNotFound, code)
+	}
+	if got := strings.Join(listedIDs(t, home), " "); got != "d" {
+		t.Errorf("Expected b and c to be deleted, got %q", got)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "delete", "d", "--status", "completed"); code != cli.ExitUsage {
+		t.Errorf("Expected IDs with filters to exit with %d, got %d", cli.ExitUsage, code)
+	}
+
+	if code, _, _ := cliRun(t, home, "", nil, "resume", "missing"); code != cli.ExitNotFound {
+		t.Errorf("Expected resuming an unknown session to exit with %d, got %d", cli.ExitNotFound, code)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "resume", "d"); code != cli.ExitError {
+		t.Errorf("Expected resuming a completed session to exit with %d, got %d", cli.ExitError, code)
+	}
+}
+
+func TestCLIResumeByID(t *testing.T) {
+	home := t.TempDir()
+	if code, _, errOut := cliRun(t, home, "", nil, "practice", "functions"); code != cli.ExitOK {
+		t.Fatalf("practice failed: %s", errOut)
+	}
+	ids := listedIDs(t, home)
+	if len(ids) != 1 {
+		t.Fatalf("Expected one paused session, got %v", ids)
+	}
+
+	code, out, errOut := cliRun(t, home, "", nil, "resume", ids[0])
+	if code != cli.ExitOK {
+		t.Fatalf("resume failed: %s", errOut)
+	}
+	if !strings.Contains(out, "Functions") {
+		t.Errorf("Expected the session to resume on functions:\n%s", out)
+	}
+}
+
+func TestCLIRetentionPolicy(t *testing.T) {
+	home := t.TempDir()
+	seedSessions(t, home,
+		map[string]int{"old": 100, "ancient": 400, "new": 1, "paused": 400},
+		map[string]models.SessionStatus{"old": models.SessionCompleted, "ancient": models.SessionCompleted, "new": models.SessionCompleted, "paused": models.SessionPaused})
+	config := filepath.Join(home, ".claude-trainer", "config.json")
+	os.WriteFile(config, []byte(`{"retention": {"archive_after": "90d", "delete_after": "1y"}}`), 0644)
+	if code, _, _ := cliRun(t, home, "", nil, "list"); code != cli.ExitUsage {
+		t.Errorf("Expected an invalid age to exit with %d, got %d", cli.ExitUsage, code)
+	}
+	os.WriteFile(config, []byte(`{"retention": {"archive_after": "90d", "delete_after": "52w"}}`), 0644)
+
+	// The dry run leaves sessions alone
+	cliRun(t, home, "", nil, "delete", "--status", "paused", "--older-than", "1000d", "--dry-run")
+	if got := len(listedIDs(t, home, "--archived")); got != 0 {
+		t.Errorf("Expected a dry run not to apply the policy, got %d archived", got)
+	}
+
+	if got := strings.Join(listedIDs(t, home), " "); got != "paused new" {
+		t.Errorf("Expected the policy to keep paused and new, got %q", got)
+	}
+	if got := strings.Join(listedIDs(t, home, "--archived"), " "); got != "old" {
+		t.Errorf("Expected old to be archived, got %q", got)
+	}
+}
+
+func TestCLIMigrateArchive(t *testing.T) {
+	home := t.TempDir()
+	if code, _, errOut := cliRun(t, home, "", nil, "user", "add", "bob"); code != cli.ExitOK {
+		t.Fatalf("user add failed: %s", errOut)
+	}
+	archive := filepath.Join(home, ".claude-trainer", "users", "bob", "archive")
+	os.MkdirAll(archive, 0755)
+	data, err := os.ReadFile(filepath.Join("testdata", "sessions", "v0-baseline.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	os.WriteFile(filepath.Join(archive, "v0-baseline.json"), data, 0644)
+
+	code, out, errOut := cliRun(t, home, "", nil, "migrate")
+	if code != cli.ExitOK {
+		t.Fatalf("migrate failed: %s", errOut)
+	}
+	if !strings.Contains(out, "from schema version 0") {
+		t.Errorf("Expected the archived session to be upgraded:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(archive, "v0-baseline.json.v0.bak")); err != nil {
+		t.Errorf("Expected a backup of the archived session: %v", err)
+	}
+}
+
+func TestCLIServeRefusesRemoteAddress(t *testing.T) {
+	home := t.TempDir()
+	code, _, errOut := cliRun(t, home, "", nil, "serve", "--addr", "0.0.0.0:0")
+	if code != cli.ExitUsage || !strings.Contains(errOut, "--allow-remote") {
+		t.Errorf("Expected listening on every interface to need --allow-remote, got %d: %s", code, errOut)
+	}
+}
+
+func TestCLIStats(t *testing.T) {
+	home := t.TempDir()
+	_, sessions := analyticsHistory()
+	dir := filepath.Join(home, ".claude-trainer", "users", "default")
+	live := storage.NewFileSessionStorage(filepath.Join(dir, "sessions"))
+	archive := storage.NewFileSessionStorage(filepath.Join(dir, "archive"))
+	for i, session := range sessions {
+		session.UserID = "default"
+		store := live
+		if i == 0 {
+			store = archive
+		}
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+
+	code, out, errOut := cliRun(t, home, "", nil, "stats", "--json", "--period", "month", "--periods", "2")
+	if code != cli.ExitOK {
+		t.Fatalf("stats failed: %s", errOut)
+	}
+	var report analytics.Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Failed to decode stats output: %v", err)
+	}
+	if report.Sessions != 3 || report.ExercisesCompleted != 3 || len(report.Timeline) != 2 {
+		t.Errorf("Expected archived sessions included and 2 months of timeline, got %+v", report)
+	}
+	if len(report.Weakest) == 0 || report.Weakest[0] != "functions" {
+		t.Errorf("Expected functions to be weakest, got %v", report.Weakest)
+	}
+
+	code, out, _ = cliRun(t, home, "", nil, "stats")
+	for _, want := range []string{"Exercises completed: 3", "Hint dependency: 57%", "Weakest modules", "trainer practice functions", "▁"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected stats to show %q:\n%s", want, out)
+		}
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "stats", "--period", "year"); code != cli.ExitUsage {
+		t.Errorf("Expected an unknown period to exit with %d, got %d", cli.ExitUsage, code)
+	}
+}
+
+func TestCLIXAPIExport(t *testing.T) {
+	home := t.TempDir()
+	session := xapiSession()
+	session.UserID = "default"
+	sessions := storage.NewFileSessionStorage(filepath.Join(home, ".claude-trainer", "users", "default", "sessions"))
+	if err := sessions.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	code, out, errOut := cliRun(t, home, "", nil, "xapi", "export", session.SessionID)
+	if code != cli.ExitOK {
+		t.Fatalf("xapi export failed with %d: %s", code, errOut)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 9 {
+		t.Fatalf("Expected 9 statements, one per line, got %d:\n%s", len(lines), out)
+	}
+	var first xapi.Statement
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Verb.ID != xapi.Attempted.ID || first.Actor.Account.Name != "default" {
+		t.Errorf("Expected the session's first statement, got %+v (%v)", first, err)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "xapi", "export", "missing"); code != cli.ExitNotFound {
+		t.Errorf("Expected an unknown session to exit with %d, got %d", cli.ExitNotFound, code)
+	}
+	if code, _, _ := cliRun(t, home, "", nil, "xapi", "export", "--send"); code != cli.ExitUsage {
+		t.Errorf("Expected --send without an xapi config to exit with %d, got %d", cli.ExitUsage, code)
+	}
+
+	// --send goes to the spool and LRS, buffering while the LRS is down
+	spool := filepath.Join(home, "statements.ndjson")
+	config := cli.DefaultConfig()
+	config.XAPI = cli.XAPI{Spool: spool, Endpoint: "https://lrs.example.com/xapi"}
+	if err := config.Save(filepath.Join(home, ".claude-trainer", "config.json")); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	lrs := newFakeLRS()
+	lrs.setOffline(true)
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		app := &cli.App{Stdin: strings.NewReader(""), Stdout: &stdout, Stderr: &stderr,
+			Getenv: func(string) string { return "" }, HomeDir: home, HTTPClient: lrs.client()}
+		code := app.Run(args)
+		return code, stdout.String() + stderr.String()
+	}
+
+	code, out = run("xapi", "export", "--send")
+	if code != cli.ExitOK || !strings.Contains(out, "9 xAPI statement(s) are buffered") {
+		t.Fatalf("Expected statements to be buffered, got %d:\n%s", code, out)
+	}
+	if spooled, err := xapi.ReadSpool(spool); err != nil || len(spooled) != 9 {
+		t.Errorf("Expected 9 spooled statements, got %d (%v)", len(spooled), err)
+	}
+
+	lrs.setOffline(false)
+	code, out = run("xapi", "flush")
+	if code != cli.ExitOK || !strings.Contains(out, "Sent 9 buffered statement(s).") || len(lrs.stored()) != 9 {
+		t.Errorf("Expected the buffer to be sent, got %d storing %d:\n%s", code, len(lrs.stored()), out)
+	}
+}
+
+func TestCLIReport(t *testing.T) {
+	home := t.TempDir()
+	session := reportSession()
+	session.UserID = "default"
+	session.Progress[0].ExerciseID = "variables"
+	store := storage.NewFileSessionStorage(filepath.Join(home, ".claude-trainer", "users", "default", "archive"))
+	if err := store.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	code, out, errOut := cliRun(t, home, "", nil, "report", "--session", "session-1", "--format", "md")
+	if code != cli.ExitOK {
+		t.Fatalf("report failed with %d: %s", code, errOut)
+	}
+	for _, want := range []string{"## Learning goals achieved", "Certificate of Completion", "| Variables and Types |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the archived session's report to contain %q:\n%s", want, out)
+		}
+	}
+
+	path := filepath.Join(home, "report.html")
+	if code, _, errOut := cliRun(t, home, "", nil, "report", "--session", "session-1", "--format", "pdf-ready-html", "--output", path); code != cli.ExitOK {
+		t.Fatalf("report --output failed: %s", errOut)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "@page") {
+		t.Errorf("Expected a pdf-ready report written to %s: %v", path, err)
+	}
+
+	key, err := report.LoadKey(filepath.Join(home, ".claude-trainer", "report.key"))
+	if err != nil {
+		t.Fatalf("Failed to load the certificate key: %v", err)
+	}
+	hash, _ := report.SessionHash(session, key)
+	if code, out, errOut := cliRun(t, home, "", nil, "report", "--session", "session-1", "--verify", hash); code != cli.ExitOK || !strings.Contains(out, "Hash matches") {
+		t.Errorf("Expected the certificate hash to verify, got %d: %s%s", code, out, errOut)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"wrong hash", []string{"--session", "session-1", "--verify", strings.Repeat("0", 64)}, cli.ExitError},
+		{"no session", nil, cli.ExitUsage},
+		{"unknown session", []string{"--session", "nope"}, cli.ExitNotFound},
+		{"unknown format", []string{"--session", "session-1", "--format", "pdf"}, cli.ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, errOut := cliRun(t, home, "", nil, append([]string{"report"}, tt.args...)...); code != tt.want {
+				t.Errorf("Expected exit code %d, got %d: %s", tt.want, code, errOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package unit
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/models"
+)
+
+// writePack writes files into a fresh pack directory
+func writePack(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+const packManifest = `{"name": "team", "title": "Team Modules", "exercises": ["errors.json"]}`
+
+const errorsExercise = `{
+  "id": "errors",
+  "title": "Error Handling",
+  "description": "Return and check errors",
+  "cognitive_level": "intermediate",
+  "exercise_type": "application",
+  "prerequisites": ["functions"],
+  "notes": "errors.md",
+  "examples": [{"title": "Returning an error", "code": ["func f() error {", "    return nil", "}"]}],
+  "challenges": [{
+    "description": "Create a function 'check' that takes an int and returns an error",
+    "template": ["package main", "", "// Write your function here", "", "func main() {}"],
+    "solution": ["func check(n int) error {", "    return nil", "}"],
+    "rules": [{"kind": "func", "name": "check", "params": ["int"], "results": ["error"]}]
+  }]
+}`
+
+const errorsNotes = `# Error Handling
+
+## Returning an error
+Functions report failure through a final error result.
+`
+
+func TestLoadContentPack(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"pack.json":   packManifest,
+		"errors.json": errorsExercise,
+		"errors.md":   errorsNotes,
+	})
+
+	registry, err := exercises.LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry failed: %v", err)
+	}
+
+	all := registry.GetAll()
+	if last := all[len(all)-1]; last.ID != "errors" {
+		t.Fatalf("Expected pack exercises after the built-in curriculum, got %q last", last.ID)
+	}
+
+	exercise, _ := registry.GetByID("errors")
+	if exercise.Examples[0].Explanation != "Functions report failure through a final error result." {
+		t.Errorf("Expected explanation from notes, got %q", exercise.Examples[0].Explanation)
+	}
+	if !strings.Contains(exercise.Examples[0].Code, "\n    return nil\n") {
+		t.Errorf("Expected code lines joined with newlines, got %q", exercise.Examples[0].Code)
+	}
+
+	challenge := exercise.Challenges[0]
+	if !challenge.Validator(challenge.Solution) {
+		t.Error("Solution should pass the rules from the pack")
+	}
+	if challenge.Validator("func check(n string) error {\n    return nil\n}") {
+		t.Error("Wrong parameter type should fail the rules from the pack")
+	}
+}
+
+func TestContentErrorsNameFileAndField(t *testing.T) {
+	tests := []struct {
+		name     string
+		exercise string
+		expected string
+	}{
+		{
+			name:     "unknown rule kind",
+			exercise: strings.Replace(errorsExercise, `"kind": "func"`, `"kind": "fn"`, 1),
+			expected: `errors.json: challenges[0].rules[0].kind: unknown rule kind "fn"`,
+		},
+		{
+			name:     "misspelled field",
+			exercise: strings.Replace(errorsExercise, `"description": "Return`, `"descripton": "Return`, 1),
+			expected: "errors.json: descripton: unknown field",
+		},
+		{
+			name:     "bad cognitive level",
+			exercise: strings.Replace(errorsExercise, `"intermediate"`, `"expert"`, 1),
+			expected: `errors.json: cognitive_level: unknown cognitive level "expert"`,
+		},
+		{
+			name:     "syntax error",
+			exercise: strings.Replace(errorsExercise, `"id": "errors",`, `"id": "errors"`, 1),
+			expected: "errors.json:3:3: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writePack(t, map[string]string{
+				"pack.json":   packManifest,
+				"errors.json": tt.exercise,
+				"errors.md":   errorsNotes,
+			})
+
+			_, err := exercises.LoadDir(dir)
+			var contentErr *exercises.ContentError
+			if !errors.As(err, &contentErr) {
+				t.Fatalf("Expected a ContentError, got %v", err)
+			}
+			if !strings.HasPrefix(err.Error(), tt.expected) {
+				t.Errorf("Expected error starting %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestContentPackRejectsDuplicateIDs(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"pack.json":   packManifest,
+		"errors.json": strings.Replace(errorsExercise, `"id": "errors"`, `"id": "variables"`, 1),
+		"errors.md":   errorsNotes,
+	})
+
+	if _, err := exercises.LoadRegistry(dir); err == nil || !strings.Contains(err.Error(), `exercise "variables" is already defined`) {
+		t.Errorf("Expected duplicate ID error, got %v", err)
+	}
+}
+
+func TestContentPackRejectsUnknownPrerequisites(t *testing.T) {
+	dir := writePack(t, map[string]string{
+		"pack.json":   packManifest,
+		"errors.json": strings.Replace(errorsExercise, `["functions"]`, `["funcs"]`, 1),
+		"errors.md":   errorsNotes,
+	})
+
+	var missing *exercises.MissingPrerequisiteError
+	if _, err := exercises.LoadRegistry(dir); !errors.As(err, &missing) || missing.Prerequisite != "funcs" {
+		t.Errorf("Expected unknown prerequisite error, got %v", err)
+	}
+}
+
+func TestExercisesAreCopies(t *testing.T) {
+	registry := exercises.NewRegistry()
+
+	// Callers such as concurrent sessions may change what they're given
+	changed := registry.GetAll()[0]
+	changed.Challenges[0].Hints[0] = "changed"
+	changed.Challenges[0].Validator = nil
+	changed.LearningGoals[0] = "changed"
+	builtin := exercises.GetVariablesExercise()
+	builtin.Challenges[0].Hints[0] = "changed"
+
+	for _, exercise := range []models.Exercise{registry.GetAll()[0], exercises.GetVariablesExercise()} {
+		challenge := exercise.Challenges[0]
+		if challenge.Hints[0] == "changed" || exercise.LearningGoals[0] == "changed" || challenge.Validator == nil {
+			t.Errorf("Expected %s to be unaffected by changes to another copy", exercise.ID)
+		}
+	}
+}
+
+func TestExamplesParse(t *testing.T) {
+	registry := exercises.NewRegistry()
+
+	// Examples leave out the package clause and imports. Each is either
+	// top-level declarations or statements, as if inside main.
+	parses := func(code string) error {
+		_, err := parser.ParseFile(token.NewFileSet(), "example.go", "package main\n\n"+code, parser.SkipObjectResolution)
+		if err == nil {
+			return nil
+		}
+		if _, body := parser.ParseFile(token.NewFileSet(), "example.go", "package main\n\nfunc main() {\n"+code+"\n}", parser.SkipObjectResolution); body == nil {
+			return nil
+		}
+		return err
+	}
+	for _, exercise := range registry.GetAll() {
+		for _, example := range slices.Concat(exercise.Examples, exercise.SupportExamples) {
+			if err := parses(example.Code); err != nil {
+				t.Errorf("%s example %q is not valid Go: %v", exercise.ID, example.Title, err)
+			}
+		}
+	}
+}
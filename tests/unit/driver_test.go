@@ -0,0 +1,432 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// scriptedInput replays canned answers. When they run out it reports eof,
+// or, if later is set, waits for more like a learner who stepped away.
+type scriptedInput struct {
+	lines []string
+	later chan string
+}
+
+func (s *scriptedInput) next() (string, bool) {
+	if len(s.lines) == 0 {
+		if s.later != nil {
+			line, ok := <-s.later
+			return line, !ok
+		}
+		return "", true
+	}
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+	return line, false
+}
+
+func (s *scriptedInput) ReadLine() (string, bool)      { return s.next() }
+func (s *scriptedInput) ReadAnswer() (string, bool)    { return s.next() }
+func (s *scriptedInput) ReadMultiline() (string, bool) { return s.next() }
+func (s *scriptedInput) Edit(string) (string, error)   { line, _ := s.next(); return line, nil }
+
+// recordingPresenter renders through a terminal into a buffer and keeps the
+// events a test wants to inspect
+type recordingPresenter struct {
+	*trainer.Terminal
+	retries  []int
+	warnings []trainer.TimeScope
+	timeUps  []trainer.TimeScope
+	summary  *trainer.Summary
+}
+
+func (r *recordingPresenter) TimeWarning(scope trainer.TimeScope, remaining time.Duration) {
+	r.warnings = append(r.warnings, scope)
+	r.Terminal.TimeWarning(scope, remaining)
+}
+
+func (r *recordingPresenter) TimeUp(scope trainer.TimeScope) {
+	r.timeUps = append(r.timeUps, scope)
+	r.Terminal.TimeUp(scope)
+}
+
+func (r *recordingPresenter) Retry(attempts int, hints bool) {
+	r.retries = append(r.retries, attempts)
+	r.Terminal.Retry(attempts, hints)
+}
+
+func (r *recordingPresenter) Results(summary trainer.Summary) {
+	r.summary = &summary
+	r.Terminal.Results(summary)
+}
+
+func TestScriptedSession(t *testing.T) {
+	config := models.TrainerConfig{MaxAttempts: 3, TimeLimit: time.Hour, ShowHints: true}
+	exercise := pointExercise()
+	exercise.Challenges[0].Hints = []string{"Give Point two int fields"}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, config, "test-user", storage.NewFileSessionStorage(t.TempDir()))
+
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	input := &scriptedInput{lines: []string{
+		"", // Ready for challenges
+		"type Point struct {\n\tX int\n\tZ int\n}", "hint", answer, // Challenge 1: wrong, hint, right
+		answer,          // Challenge 2
+		"skip",          // Challenge 3
+		"multi", answer, // Challenge 4
+	}}
+	cltTrainer.SetIO(presenter, input)
+	cltTrainer.Start()
+
+	if len(presenter.retries) != 1 || presenter.retries[0] != 1 {
+		t.Errorf("Expected one retry after the first attempt, got %v", presenter.retries)
+	}
+	if presenter.summary == nil {
+		t.Fatal("Expected results to be presented")
+	}
+	if len(presenter.summary.Completed) != 1 || presenter.summary.Attempts != 4 || presenter.summary.HintsUsed != 1 {
+		t.Errorf("Unexpected summary: %+v", presenter.summary)
+	}
+
+	for _, expected := range []string{"Challenge 4/4", "💡 Hint:", "⏭️  Skipped.", "✅ Points completed!"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+}
+
+func TestSessionTimeLimitPausesIdleLearner(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	config := models.TrainerConfig{
+		MaxAttempts:  3,
+		TimeLimit:    300 * time.Millisecond,
+		TimeWarnings: []time.Duration{200 * time.Millisecond},
+	}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, config, "test-user", sessionStorage)
+
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{})}
+	later := make(chan string)
+	defer close(later)
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: []string{""}, later: later})
+
+	done := make(chan struct{})
+	go func() {
+		cltTrainer.Start()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Session time limit did not interrupt the idle prompt")
+	}
+
+	if len(presenter.warnings) != 1 || presenter.warnings[0] != trainer.SessionTime {
+		t.Errorf("Expected one session warning, got %v", presenter.warnings)
+	}
+	if len(presenter.timeUps) != 1 || presenter.timeUps[0] != trainer.SessionTime {
+		t.Errorf("Expected the session to time out, got %v", presenter.timeUps)
+	}
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 || sessions[0].Status != models.SessionPaused {
+		t.Fatalf("Expected the session to be paused and saved, got %v (%v)", sessions, err)
+	}
+	if sessions[0].ActiveTime < config.TimeLimit {
+		t.Errorf("Expected active time of at least %v, got %v", config.TimeLimit, sessions[0].ActiveTime)
+	}
+}
+
+func TestExerciseTimeLimitMovesOn(t *testing.T) {
+	first, second := pointExercise(), pointExercise()
+	second.ID = "more-points"
+	first.EstimatedTime, second.EstimatedTime = 1, 60
+
+	// 1 minute estimate * 0.005 = 300ms for the first exercise
+	config := models.TrainerConfig{MaxAttempts: 3, ExerciseTimeFactor: 0.005}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{first, second}, config, "test-user", storage.NewFileSessionStorage(t.TempDir()))
+
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	later := make(chan string)
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: []string{""}, later: later})
+
+	// Once the first exercise times out, the learner presses Enter for the
+	// second exercise, then quits
+	go func() {
+		time.Sleep(time.Second)
+		later <- ""
+		later <- "quit"
+		close(later)
+	}()
+	cltTrainer.Start()
+
+	if len(presenter.timeUps) != 1 || presenter.timeUps[0] != trainer.ExerciseTime {
+		t.Fatalf("Expected the exercise to time out, got %v", presenter.timeUps)
+	}
+	if presenter.summary == nil || len(presenter.summary.Completed) != 1 {
+		t.Fatalf("Expected the timed out exercise to count as finished, got %+v", presenter.summary)
+	}
+	if !strings.Contains(output.String(), "left for this exercise") {
+		t.Error("Expected a remaining time indicator")
+	}
+}
+
+func TestPauseResumesAtChallenge(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	exercise := pointExercise()
+	exercise.Challenges[1].Hints = []string{"First hint", "Second hint"}
+	config := models.TrainerConfig{MaxAttempts: 3, ShowHints: true}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	wrong := "type Point struct {\n\tX int\n\tZ int\n}"
+
+	// Solve challenge 1, then pause partway through challenge 2
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, config, "test-user", sessionStorage)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}),
+		&scriptedInput{lines: []string{"", answer, wrong, "hint", "pause"}})
+	cltTrainer.Start()
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one paused session, got %v (%v)", sessions, err)
+	}
+	progress := sessions[0].Progress[0]
+	if progress.Attempts != 2 || progress.HintsUsed != 1 || len(progress.Challenges) != 2 {
+		t.Fatalf("Expected the paused challenge's work to be saved, got %+v", progress)
+	}
+	if paused := progress.Challenges[1]; paused.CompletedAt != nil || paused.Attempts != 1 || paused.HintsUsed != 1 {
+		t.Errorf("Expected challenge 2 to be saved unfinished, got %+v", paused)
+	}
+	if progress.AttemptHistory[1].Answer != wrong {
+		t.Errorf("Expected the submitted answer to be saved, got %q", progress.AttemptHistory[1].Answer)
+	}
+
+	resumed, err := trainer.ResumeSession(sessions[0].SessionID, []models.Exercise{exercise}, sessionStorage)
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	resumed.SetIO(presenter, &scriptedInput{lines: []string{"", "hint", answer, answer, answer}})
+	resumed.Start()
+
+	for _, expected := range []string{"challenge 2/4", "Second hint", "Challenge 4/4"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+	if strings.Contains(output.String(), "Challenge 1/4") {
+		t.Error("Expected the solved challenge not to be replayed")
+	}
+
+	summary := presenter.summary
+	if summary == nil || len(summary.Completed) != 1 {
+		t.Fatalf("Expected the exercise to be completed, got %+v", summary)
+	}
+	finished := summary.Completed[0].Progress
+	if finished.Attempts != 5 || finished.HintsUsed != 2 || len(finished.Challenges) != 4 {
+		t.Errorf("Expected totals to carry over the pause, got %+v", finished)
+	}
+	if second := finished.Challenges[1]; second.Attempts != 2 || second.HintsUsed != 2 || !second.Solved {
+		t.Errorf("Expected challenge 2 to combine both sittings, got %+v", second)
+	}
+}
+
+func TestHintsTurnedOff(t *testing.T) {
+	exercise := pointExercise()
+	exercise.Challenges[0].Hints = []string{"Give Point two int fields"}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	wrong := "type Point struct {\n\tX int\n\tZ int\n}"
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, models.TrainerConfig{MaxAttempts: 3}, "test-user", nil)
+
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: []string{"", "hint", wrong, wrong, answer, "quit"}})
+	cltTrainer.Start()
+
+	text := output.String()
+	if strings.Contains(text, "Give Point two int fields") || !strings.Contains(text, "Hints are turned off") {
+		t.Errorf("Expected the hint to be refused:\n%s", text)
+	}
+	if strings.Contains(text, "Type 'hint'") {
+		t.Errorf("Expected retries not to offer hints:\n%s", text)
+	}
+	if presenter.summary == nil || presenter.summary.HintsUsed != 0 {
+		t.Errorf("Expected no hints to be counted, got %+v", presenter.summary)
+	}
+}
+
+func TestQuitAbandonsSession(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, models.TrainerConfig{MaxAttempts: 3}, "test-user", sessionStorage)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", answer, "quit"}})
+	cltTrainer.Start()
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected the checkpointed session to be kept, got %v (%v)", sessions, err)
+	}
+	if sessions[0].Status != models.SessionAbandoned || !sessions[0].Progress[0].Challenges[0].Solved {
+		t.Errorf("Expected an abandoned session with its progress, got %+v", sessions[0])
+	}
+	if resumable, _ := trainer.ResumableSessions("test-user", sessionStorage); len(resumable) != 0 {
+		t.Errorf("Expected abandoned sessions not to be resumable, got %d", len(resumable))
+	}
+}
+
+func TestSignalPausesSession(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sending SIGINT to the test process requires a POSIX system")
+	}
+
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, models.TrainerConfig{MaxAttempts: 3}, "test-user", sessionStorage)
+	var output bytes.Buffer
+	later := make(chan string)
+	defer close(later)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &output), &scriptedInput{lines: []string{"", answer}, later: later})
+
+	done := make(chan struct{})
+	go func() {
+		cltTrainer.Start()
+		close(done)
+	}()
+
+	// The first challenge is checkpointed as soon as it is answered
+	var checkpoint *models.TrainingSession
+	for deadline := time.Now().Add(5 * time.Second); checkpoint == nil && time.Now().Before(deadline); {
+		if sessions, _ := sessionStorage.ListSessions("test-user"); len(sessions) == 1 {
+			checkpoint = sessions[0]
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if checkpoint == nil || checkpoint.Status != models.SessionActive || len(checkpoint.Progress[0].Challenges) != 1 {
+		t.Fatalf("Expected an active checkpoint after the first challenge, got %+v", checkpoint)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("Failed to send SIGINT: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGINT did not stop the session")
+	}
+
+	session, err := sessionStorage.LoadSession(checkpoint.SessionID)
+	if err != nil || session.Status != models.SessionPaused {
+		t.Fatalf("Expected SIGINT to save a paused session, got %+v (%v)", session, err)
+	}
+	if !strings.Contains(output.String(), "Interrupted") {
+		t.Error("Expected the interrupt to be acknowledged")
+	}
+}
+
+func TestLiveSessionNotResumable(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, models.TrainerConfig{MaxAttempts: 3}, "test-user", sessionStorage)
+	later := make(chan string)
+	defer close(later)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", answer}, later: later})
+
+	done := make(chan struct{})
+	go func() {
+		cltTrainer.Start()
+		close(done)
+	}()
+
+	var checkpoint *models.TrainingSession
+	for deadline := time.Now().Add(5 * time.Second); checkpoint == nil && time.Now().Before(deadline); {
+		if sessions, _ := sessionStorage.ListSessions("test-user"); len(sessions) == 1 {
+			checkpoint = sessions[0]
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if checkpoint == nil || checkpoint.Status != models.SessionActive {
+		t.Fatalf("Expected an active checkpoint after the first challenge, got %+v", checkpoint)
+	}
+
+	// A session still being trained looks like a crash on disk, but its
+	// trainer holds the lock
+	if resumable, err := trainer.ResumableSessions("test-user", sessionStorage); err != nil || len(resumable) != 0 {
+		t.Errorf("Expected a live session not to be offered, got %v (%v)", resumable, err)
+	}
+	if _, err := sessionStorage.LockSession(checkpoint.SessionID); !errors.Is(err, storage.ErrSessionInUse) {
+		t.Errorf("Expected the live session to be held, got %v", err)
+	}
+
+	later <- "pause"
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pause did not stop the session")
+	}
+	unlock, err := sessionStorage.LockSession(checkpoint.SessionID)
+	if err != nil {
+		t.Fatalf("Expected the session to be released when training ends: %v", err)
+	}
+	unlock()
+}
+
+func TestRecoverInterruptedSession(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	exercise := pointExercise()
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, models.TrainerConfig{MaxAttempts: 3}, "test-user", sessionStorage)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", answer, "pause"}})
+	cltTrainer.Start()
+
+	// Leave the session as a crash after its last checkpoint would
+	sessions, _ := sessionStorage.ListSessions("test-user")
+	crashed := sessions[0]
+	crashed.Status = models.SessionActive
+	crashed.PausedAt = nil
+	if err := sessionStorage.SaveSession(crashed); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	resumable, err := trainer.ResumableSessions("test-user", sessionStorage)
+	if err != nil || len(resumable) != 1 || resumable[0].Status != models.SessionActive {
+		t.Fatalf("Expected the interrupted session to be offered, got %v (%v)", resumable, err)
+	}
+	if _, err := trainer.ResumeSession(crashed.SessionID, []models.Exercise{exercise}, sessionStorage); err == nil {
+		t.Error("Expected ResumeSession to leave interrupted sessions to RecoverSession")
+	}
+
+	recovered, err := trainer.RecoverSession(crashed.SessionID, []models.Exercise{exercise}, sessionStorage)
+	if err != nil {
+		t.Fatalf("Failed to recover session: %v", err)
+	}
+	// An active session being recovered is held by this process
+	if others, _ := trainer.ResumableSessions("test-user", sessionStorage); len(others) != 0 {
+		t.Errorf("Expected a recovered session not to be offered again, got %d", len(others))
+	}
+
+	var output bytes.Buffer
+	presenter := &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}
+	recovered.SetIO(presenter, &scriptedInput{lines: []string{"", answer, answer, answer}})
+	recovered.Start()
+
+	if !strings.Contains(output.String(), "challenge 2/4") {
+		t.Error("Expected recovery to continue at the second challenge")
+	}
+	session, err := sessionStorage.LoadSession(crashed.SessionID)
+	if err != nil || session.Status != models.SessionCompleted {
+		t.Errorf("Expected the recovered session to be completed, got %+v (%v)", session, err)
+	}
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// prereqs builds bare exercises from an ID to prerequisites listing, kept in
+// the given declaration order
+func prereqs(ids []string, requires map[string][]string) []models.Exercise {
+	list := make([]models.Exercise, len(ids))
+	for i, id := range ids {
+		list[i] = models.Exercise{ID: id, Title: id, Prerequisites: requires[id]}
+	}
+	return list
+}
+
+func TestGraphOrder(t *testing.T) {
+	// Declared out of order: "maps" and "slices" both only need "basics"
+	graph, err := exercises.NewGraph(prereqs(
+		[]string{"generics", "maps", "basics", "slices"},
+		map[string][]string{
+			"generics": {"maps", "slices"},
+			"maps":     {"basics"},
+			"slices":   {"basics"},
+		},
+	))
+	if err != nil {
+		t.Fatalf("NewGraph failed: %v", err)
+	}
+
+	expected := []string{"basics", "maps", "slices", "generics"}
+	for i := 0; i < 5; i++ {
+		if order := graph.Order(); !reflect.DeepEqual(order, expected) {
+			t.Fatalf("Expected order %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestGraphRejectsBadPrerequisites(t *testing.T) {
+	_, err := exercises.NewGraph(prereqs([]string{"a", "b", "c"}, map[string][]string{
+		"a": {"c"},
+		"b": {"a"},
+		"c": {"b"},
+	}))
+	var cycle *exercises.CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("Expected a cycle error, got %v", err)
+	}
+	if expected := []string{"a", "c", "b", "a"}; !reflect.DeepEqual(cycle.Cycle, expected) {
+		t.Errorf("Expected cycle %v, got %v", expected, cycle.Cycle)
+	}
+
+	_, err = exercises.NewGraph(prereqs([]string{"a"}, map[string][]string{"a": {"missing"}}))
+	var missing *exercises.MissingPrerequisiteError
+	if !errors.As(err, &missing) || missing.Prerequisite != "missing" {
+		t.Errorf("Expected a missing prerequisite error, got %v", err)
+	}
+}
+
+func TestRegistryPrerequisiteQueries(t *testing.T) {
+	registry := exercises.NewRegistry()
+
+	ids := func(list []models.Exercise) []string {
+		var result []string
+		for _, exercise := range list {
+			result = append(result, exercise.ID)
+		}
+		return result
+	}
+
+	path, err := registry.PathTo("structs", []string{"variables"})
+	if err != nil {
+		t.Fatalf("PathTo failed: %v", err)
+	}
+	if expected := []string{"basic-types", "composite-types", "functions", "structs"}; !reflect.DeepEqual(ids(path), expected) {
+		t.Errorf("Expected path %v, got %v", expected, ids(path))
+	}
+	if _, err := registry.PathTo("goroutines", nil); err == nil {
+		t.Error("Expected an error for an unknown target")
+	}
+
+	unlocked := registry.Unlocks("basic-types", []string{"variables"})
+	if expected := []string{"composite-types"}; !reflect.DeepEqual(ids(unlocked), expected) {
+		t.Errorf("Expected %v to be unlocked, got %v", expected, ids(unlocked))
+	}
+
+	available := registry.GetByPrerequisites([]string{"variables", "basic-types"})
+	if expected := []string{"variables", "basic-types", "composite-types"}; !reflect.DeepEqual(ids(available), expected) {
+		t.Errorf("Expected %v, got %v", expected, ids(available))
+	}
+
+	if next, ok := registry.Next([]string{"variables"}); !ok || next.ID != "basic-types" {
+		t.Errorf("Expected basic-types next, got %q", next.ID)
+	}
+}
+
+func TestTrainerFollowsPrerequisites(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+
+	first, second := pointExercise(), pointExercise()
+	first.ID, first.Challenges = "advanced", first.Challenges[:1]
+	first.Prerequisites = []string{"basics", "elsewhere"} // "elsewhere" is outside this list
+	second.ID, second.Challenges = "basics", second.Challenges[:1]
+
+	config := models.TrainerConfig{MaxAttempts: 3, TimeLimit: time.Hour}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{first, second}, config, "test-user", sessionStorage)
+
+	input := "\n" + // Ready for "basics" challenges
+		"type Point struct { X, Y int }\n" +
+		"\n" + // Ready for "advanced" challenges
+		"pause\n"
+	withStdio(t, input, cltTrainer.Start)
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one paused session, got %d (%v)", len(sessions), err)
+	}
+	session := sessions[0]
+	if session.Progress[1].CompletedAt == nil {
+		t.Error("Expected basics to be completed first")
+	}
+	if session.CurrentIndex != 0 || session.Progress[0].ExerciseID != "advanced" {
+		t.Errorf("Expected to pause in advanced, got index %d", session.CurrentIndex)
+	}
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+// withStdio runs fn with os.Stdin fed from input and os.Stdout discarded
+func withStdio(t *testing.T, input string, fn func()) {
+	t.Helper()
+
+	stdinPath := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(stdinPath, []byte(input), 0600); err != nil {
+		t.Fatalf("Failed to write stdin: %v", err)
+	}
+	stdin, err := os.Open(stdinPath)
+	if err != nil {
+		t.Fatalf("Failed to open stdin: %v", err)
+	}
+	defer stdin.Close()
+
+	stdout, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatalf("Failed to create stdout: %v", err)
+	}
+	defer stdout.Close()
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, stdout
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	fn()
+}
+
+func pointExercise() models.Exercise {
+	template := "package main\n\nimport \"fmt\"\n\n// Define Point here\n\nfunc main() {\n\tfmt.Println(Point{1, 2})\n}"
+	challenge := models.Challenge{
+		Description: "Define a Point struct with X and Y",
+		Template:    template,
+		Solution:    "type Point struct {\n\tX int\n\tY int\n}",
+		Rules:       []validation.Rule{validation.Struct("Point").WithField("X", "int").WithField("Y", "int")},
+	}
+	challenge.Validator = validation.Validator(template, challenge.Rules...)
+
+	return models.Exercise{
+		ID:         "points",
+		Title:      "Points",
+		Challenges: []models.Challenge{challenge, challenge, challenge, challenge},
+	}
+}
+
+func TestMultiLineAnswers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("editor script requires a POSIX shell")
+	}
+
+	tempDir := t.TempDir()
+	sessionStorage := storage.NewFileSessionStorage(tempDir)
+
+	// The "editor" replaces the template with a complete solution
+	solution := filepath.Join(tempDir, "solution.go")
+	program := "package main\n\nimport \"fmt\"\n\ntype Point struct {\n\tX, Y int\n}\n\nfunc main() {\n\tfmt.Println(Point{1, 2})\n}\n"
+	if err := os.WriteFile(solution, []byte(program), 0600); err != nil {
+		t.Fatalf("Failed to write solution: %v", err)
+	}
+	editor := filepath.Join(tempDir, "editor.sh")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\ncp \""+solution+"\" \"$1\"\n"), 0700); err != nil {
+		t.Fatalf("Failed to write editor: %v", err)
+	}
+	t.Setenv("VISUAL", editor)
+
+	config := models.TrainerConfig{MaxAttempts: 3, TimeLimit: time.Hour, ShowHints: true}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, config, "test-user", sessionStorage)
+
+	input := "\n" + // Ready for challenges
+		"type Point struct {\n\tX int\n\tY int\n}\n" + // Open brace keeps reading
+		"multi\ntype Point struct {\n\tX, Y int\n}\n.\n" + // Explicit multi-line mode
+		"edit\n" + // Editor writes the whole program
+		"pause\n"
+
+	withStdio(t, input, cltTrainer.Start)
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one paused session, got %d (%v)", len(sessions), err)
+	}
+
+	progress := sessions[0].Progress[0]
+	if progress.Attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", progress.Attempts)
+	}
+	if len(progress.AttemptHistory) != 3 {
+		t.Fatalf("Expected 3 recorded attempts, got %d", len(progress.AttemptHistory))
+	}
+	for _, record := range progress.AttemptHistory {
+		if !record.Passed {
+			t.Errorf("Attempt on challenge %d should have passed", record.Challenge+1)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// openLogStore opens a log store in dir, closing it when the test ends
+func openLogStore(t *testing.T, dir string) *storage.LogSessionStorage {
+	t.Helper()
+	store, err := storage.OpenLogSessionStorage(dir)
+	if err != nil {
+		t.Fatalf("Failed to open log store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// saveLogSessions saves sessions for two users starting a day apart
+func saveLogSessions(t *testing.T, store storage.SessionStorage, start time.Time) {
+	t.Helper()
+	for i := 0; i < 6; i++ {
+		status := models.SessionPaused
+		if i%2 == 1 {
+			status = models.SessionCompleted
+		}
+		session := &models.TrainingSession{
+			UserID:    "user-" + strconv.Itoa(i%3),
+			SessionID: "session-" + strconv.Itoa(i),
+			StartTime: start.AddDate(0, 0, i),
+			Status:    status,
+			Progress:  []models.LearningProgress{{ExerciseID: "variables", Score: float64(i)}},
+		}
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+}
+
+func TestLogSessionStorage_SaveLoadAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	store := openLogStore(t, dir)
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	saveLogSessions(t, store, start)
+
+	// Overwrite one session; only the latest copy is returned
+	session, err := store.LoadSession("session-2")
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	session.Status = models.SessionAbandoned
+	session.CurrentIndex = 3
+	if err := store.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Failed to close log store: %v", err)
+	}
+
+	reopened := openLogStore(t, dir)
+	loaded, err := reopened.LoadSession("session-2")
+	if err != nil {
+		t.Fatalf("Failed to load session after reopening: %v", err)
+	}
+	if loaded.Status != models.SessionAbandoned || loaded.CurrentIndex != 3 {
+		t.Errorf("Expected the latest copy of session-2, got status %s at exercise %d", loaded.Status, loaded.CurrentIndex)
+	}
+	if len(loaded.Progress) != 1 || loaded.Progress[0].Score != 2 {
+		t.Errorf("Expected progress to round-trip, got %+v", loaded.Progress)
+	}
+	if _, err := reopened.LoadSession("missing"); err == nil {
+		t.Error("Expected an error loading a session that doesn't exist")
+	}
+}
+
+func TestLogSessionStorage_Query(t *testing.T) {
+	store := openLogStore(t, t.TempDir())
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	saveLogSessions(t, store, start)
+
+	tests := []struct {
+		name  string
+		query storage.SessionQuery
+		want  []string
+	}{
+		{"by user", storage.SessionQuery{UserID: "user-0"}, []string{"session-0", "session-3"}},
+		{"by status", storage.SessionQuery{Status: models.SessionCompleted}, []string{"session-1", "session-3", "session-5"}},
+		{"by user and status", storage.SessionQuery{UserID: "user-1", Status: models.SessionPaused}, []string{"session-4"}},
+		{"by date range", storage.SessionQuery{From: start.AddDate(0, 0, 2), To: start.AddDate(0, 0, 4)}, []string{"session-2", "session-3"}},
+		{"no match", storage.SessionQuery{UserID: "nobody"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions, err := store.QuerySessions(tt.query)
+			if err != nil {
+				t.Fatalf("Failed to query sessions: %v", err)
+			}
+			var got []string
+			for _, session := range sessions {
+				got = append(got, session.SessionID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+					break
+				}
+			}
+		})
+	}
+
+	// The file store answers the same queries
+	fileStore := storage.NewFileSessionStorage(t.TempDir())
+	saveLogSessions(t, fileStore, start)
+	sessions, err := fileStore.QuerySessions(storage.SessionQuery{UserID: "user-1", Status: models.SessionPaused})
+	if err != nil {
+		t.Fatalf("Failed to query file store: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].SessionID != "session-4" {
+		t.Errorf("Expected session-4 from the file store, got %d sessions", len(sessions))
+	}
+}
+
+func TestLogSessionStorage_DeleteAndCompact(t *testing.T) {
+	dir := t.TempDir()
+	store := openLogStore(t, dir)
+	saveLogSessions(t, store, time.Now())
+	for i := 0; i < 5; i++ {
+		session, _ := store.LoadSession("session-0")
+		session.CurrentIndex = i
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+	if err := store.DeleteSession("session-1"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, err := store.LoadSession("session-1"); err == nil {
+		t.Error("Expected deleted session to be gone")
+	}
+
+	before, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Failed to read stats: %v", err)
+	}
+	if err := store.Compact(); err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	after, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Failed to read stats: %v", err)
+	}
+	if after.Sessions != 5 || after.LogBytes != before.LiveBytes || after.LogBytes >= before.LogBytes {
+		t.Errorf("Expected compaction to keep %d live bytes of %d, got %+v", before.LiveBytes, before.LogBytes, after)
+	}
+
+	// Compaction survives reopening, and the store keeps working after it
+	store.Close()
+	reopened := openLogStore(t, dir)
+	session, err := reopened.LoadSession("session-0")
+	if err != nil || session.CurrentIndex != 4 {
+		t.Fatalf("Expected compacted session-0 at exercise 4, got %+v, %v", session, err)
+	}
+	if err := reopened.SaveSession(&models.TrainingSession{UserID: "user-0", SessionID: "session-9", Status: models.SessionActive}); err != nil {
+		t.Fatalf("Failed to save after compaction: %v", err)
+	}
+	sessions, err := reopened.ListSessions("user-0")
+	if err != nil || len(sessions) != 3 {
+		t.Errorf("Expected 3 sessions for user-0, got %d, %v", len(sessions), err)
+	}
+}
+
+func TestLogSessionStorage_RebuildsIndexAndRepairsLog(t *testing.T) {
+	dir := t.TempDir()
+	store := openLogStore(t, dir)
+	saveLogSessions(t, store, time.Now())
+	store.Close()
+
+	// A crash mid-append leaves a partial record, and the index is lost
+	logFile, err := os.OpenFile(filepath.Join(dir, "sessions.log"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	logFile.Write([]byte{0, 0, 1, 0, 42})
+	logFile.Close()
+	if err := os.Remove(filepath.Join(dir, "sessions.idx")); err != nil {
+		t.Fatalf("Failed to remove index: %v", err)
+	}
+
+	reopened := openLogStore(t, dir)
+	sessions, err := reopened.QuerySessions(storage.SessionQuery{})
+	if err != nil || len(sessions) != 6 {
+		t.Fatalf("Expected 6 sessions from the rebuilt index, got %d, %v", len(sessions), err)
+	}
+	if err := reopened.SaveSession(&models.TrainingSession{UserID: "user-0", SessionID: "session-6"}); err != nil {
+		t.Fatalf("Failed to save after repair: %v", err)
+	}
+	reopened.Close()
+	if _, err := openLogStore(t, dir).LoadSession("session-6"); err != nil {
+		t.Errorf("Expected the session saved after repair to be readable: %v", err)
+	}
+}
+
+func TestLogSessionStorage_SharedBetweenStores(t *testing.T) {
+	dir := t.TempDir()
+	first := openLogStore(t, dir)
+	second := openLogStore(t, dir)
+
+	saveLogSessions(t, first, time.Now())
+	if err := second.SaveSession(&models.TrainingSession{UserID: "user-0", SessionID: "session-6"}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	for _, store := range []*storage.LogSessionStorage{first, second} {
+		sessions, err := store.ListSessions("user-0")
+		if err != nil || len(sessions) != 3 {
+			t.Errorf("Expected each store to see 3 sessions for user-0, got %d, %v", len(sessions), err)
+		}
+	}
+
+	// A compaction by one store is picked up by the other
+	if err := first.Compact(); err != nil {
+		t.Fatalf("Failed to compact: %v", err)
+	}
+	if err := second.DeleteSession("session-6"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, err := first.LoadSession("session-6"); err == nil {
+		t.Error("Expected the deletion to be visible to the other store")
+	}
+}
+
+func TestLogSessionStorage_LockSession(t *testing.T) {
+	store := openLogStore(t, t.TempDir())
+	var _ storage.Locker = store
+
+	unlock, err := store.LockSession("session-0")
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+
+	// A lock held while the session is deleted still keeps others out
+	if err := store.DeleteSession("session-0"); err != nil {
+		t.Fatalf("Failed to delete locked session: %v", err)
+	}
+	if _, err := store.LockSession("session-0"); err == nil {
+		t.Error("Expected the lock to survive deleting the session")
+	}
+	if err := unlock(); err != nil {
+		t.Errorf("Failed to unlock session: %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/mastery"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+func TestMasteryModel(t *testing.T) {
+	model := mastery.New(mastery.DefaultParams, mastery.DefaultThreshold)
+	if p := model.Probability("maps"); p != mastery.DefaultParams.Init {
+		t.Errorf("Expected an unseen concept at the prior, got %.3f", p)
+	}
+
+	model.Observe("maps", true)
+	if !model.Mastered("maps") {
+		t.Errorf("Expected a correct answer to be mastery, got %.3f", model.Probability("maps"))
+	}
+	model.Record([]string{"structs"}, mastery.Outcome{HintsUsed: 1, Solved: true})
+	if model.Mastered("structs") {
+		t.Errorf("Expected a solve that needed a hint not to be mastery, got %.3f", model.Probability("structs"))
+	}
+	before := model.Probability("maps")
+	model.Observe("maps", false)
+	if model.Probability("maps") >= before {
+		t.Error("Expected a wrong answer to lower mastery")
+	}
+
+	// Hints and skips count against a concept; a clean solve counts for it
+	for _, tc := range []struct {
+		outcome mastery.Outcome
+		correct int
+		total   int
+	}{
+		{mastery.Outcome{Solved: true}, 1, 1},
+		{mastery.Outcome{WrongAttempts: 1, Solved: true}, 1, 2},
+		{mastery.Outcome{HintsUsed: 1, Solved: true}, 0, 1},
+		{mastery.Outcome{}, 0, 1},
+		{mastery.Outcome{WrongAttempts: 3}, 0, 3},
+	} {
+		model := mastery.New(mastery.DefaultParams, mastery.DefaultThreshold)
+		model.Record([]string{"slices"}, tc.outcome)
+		estimates := model.Estimates()
+		if len(estimates) != 1 || estimates[0].Correct != tc.correct || estimates[0].Observations != tc.total {
+			t.Errorf("%+v: expected %d/%d correct, got %+v", tc.outcome, tc.correct, tc.total, estimates)
+		}
+	}
+
+	restored := mastery.Restore(mastery.DefaultParams, mastery.DefaultThreshold, model.Estimates())
+	if restored.Probability("maps") != model.Probability("maps") {
+		t.Error("Expected restored estimates to match")
+	}
+	if weak := restored.Unmastered([]string{"maps", "maps", "channels"}); len(weak) != 2 {
+		t.Errorf("Expected maps and channels to be unmastered once each, got %v", weak)
+	}
+}
+
+// conceptPresenter records which concepts the trainer asked to practice
+type conceptPresenter struct {
+	*recordingPresenter
+	practiced [][]string
+}
+
+func (c *conceptPresenter) Practice(concepts []string) {
+	c.practiced = append(c.practiced, concepts)
+	c.recordingPresenter.Practice(concepts)
+}
+
+func TestTrainerPracticesUntilMastered(t *testing.T) {
+	exercise := pointExercise()
+	exercise.Challenges = exercise.Challenges[:2]
+	exercise.Challenges[0].Concepts = []string{"struct-types"}
+	exercise.Challenges[1].Concepts = []string{"struct-types", "struct-fields"}
+
+	// Above the default, so one correct answer isn't enough
+	const threshold = 0.95
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	config := models.TrainerConfig{MaxAttempts: 3, MasteryThreshold: threshold}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, config, "test-user", sessionStorage)
+
+	var output bytes.Buffer
+	presenter := &conceptPresenter{recordingPresenter: &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: []string{"", answer, answer, answer}})
+	var submitted []int
+	cltTrainer.AddListener(trainer.ListenerFunc(func(event trainer.Event) {
+		if event.Type == trainer.AnswerSubmitted {
+			submitted = append(submitted, event.Attempt.Challenge)
+		}
+	}))
+	cltTrainer.Start()
+
+	// struct-types is seen twice and mastered; struct-fields needs one more answer
+	if len(presenter.practiced) != 1 || strings.Join(presenter.practiced[0], ",") != "struct-fields" {
+		t.Fatalf("Expected one practice round for struct-fields, got %v", presenter.practiced)
+	}
+	summary := presenter.summary
+	if summary == nil || len(summary.Completed) != 1 || summary.Attempts != 2 {
+		t.Fatalf("Expected practice not to count towards the exercise, got %+v", summary)
+	}
+	if history := summary.Completed[0].Progress.AttemptHistory; len(history) != 3 || history[2].Challenge != 1 {
+		t.Errorf("Expected the practice answer in the attempt history, got %+v", history)
+	}
+	if fmt.Sprint(submitted) != "[0 1 1]" {
+		t.Errorf("Expected the practice answer to be submitted as an event, got challenges %v", submitted)
+	}
+	if len(summary.Mastery) != 2 {
+		t.Fatalf("Expected mastery for both concepts, got %+v", summary.Mastery)
+	}
+	for _, estimate := range summary.Mastery {
+		if estimate.Probability < threshold {
+			t.Errorf("Expected %s to be mastered, got %.3f", estimate.Concept, estimate.Probability)
+		}
+	}
+	if !strings.Contains(output.String(), "Concept Mastery") || !strings.Contains(output.String(), "struct-fields") {
+		t.Error("Expected results to report mastery per concept")
+	}
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 || len(sessions[0].Mastery) != 2 {
+		t.Errorf("Expected mastery to be saved with the session, got %v (%v)", sessions, err)
+	}
+}
+
+func TestPerfectLearnerSkipsPractice(t *testing.T) {
+	exercise := pointExercise()
+	for i := range exercise.Challenges {
+		exercise.Challenges[i].Concepts = []string{"struct-types", fmt.Sprintf("concept-%d", i)}
+	}
+
+	config := models.TrainerConfig{MaxAttempts: 3, MasteryThreshold: mastery.DefaultThreshold}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, config, "test-user", storage.NewFileSessionStorage(t.TempDir()))
+
+	var output bytes.Buffer
+	presenter := &conceptPresenter{recordingPresenter: &recordingPresenter{Terminal: trainer.NewTerminal(strings.NewReader(""), &output)}}
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	lines := []string{""}
+	for range exercise.Challenges {
+		lines = append(lines, answer)
+	}
+	cltTrainer.SetIO(presenter, &scriptedInput{lines: lines})
+	cltTrainer.Start()
+
+	// Each concept is answered correctly once; that is mastery at the default
+	if len(presenter.practiced) != 0 {
+		t.Errorf("Expected no practice for a learner answering everything correctly, got %v", presenter.practiced)
+	}
+	summary := presenter.summary
+	if summary == nil || len(summary.Completed) != 1 || summary.Attempts != len(exercise.Challenges) {
+		t.Fatalf("Expected the exercise finished in %d attempts, got %+v", len(exercise.Challenges), summary)
+	}
+	for _, estimate := range summary.Mastery {
+		if estimate.Probability < mastery.DefaultThreshold {
+			t.Errorf("Expected %s to be mastered, got %.3f", estimate.Concept, estimate.Probability)
+		}
+	}
+}
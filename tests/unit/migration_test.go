@@ -0,0 +1,147 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// copyFixtures copies the session fixtures for every schema version into a
+// fresh sessions directory
+func copyFixtures(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "sessions", "*.json"))
+	if err != nil || len(fixtures) == 0 {
+		t.Fatalf("No session fixtures found: %v", err)
+	}
+	for _, fixture := range fixtures {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.Base(fixture)), data, 0644); err != nil {
+			t.Fatalf("Failed to copy fixture: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestLoadSessionFixtures(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(copyFixtures(t))
+
+	baseline, err := sessionStorage.LoadSession("v0-baseline")
+	if err != nil {
+		t.Fatalf("Failed to load baseline session: %v", err)
+	}
+	if baseline.Config.MaxAttempts != 3 || baseline.Config.TimeLimit != time.Hour || !baseline.Config.ShowHints {
+		t.Errorf("Baseline config not migrated: %+v", baseline.Config)
+	}
+	if len(baseline.Progress) != 2 || baseline.Progress[0].ExerciseID != "variables" || baseline.Progress[0].TimeSpent != 450*time.Second ||
+		baseline.Progress[0].Score != 82.5 || baseline.Progress[0].HintsUsed != 1 || baseline.Progress[0].CompletedAt == nil {
+		t.Errorf("Baseline progress not migrated: %+v", baseline.Progress)
+	}
+	if baseline.Progress[1].CompletedAt != nil || baseline.PausedAt == nil || baseline.CurrentIndex != 1 {
+		t.Errorf("Baseline position not migrated: %+v", baseline)
+	}
+
+	history, err := sessionStorage.LoadSession("v0-attempt-history")
+	if err != nil {
+		t.Fatalf("Failed to load attempt history session: %v", err)
+	}
+	if history.ActiveTime != 330*time.Second || history.Config.ExerciseTimeFactor != 1.5 || history.Config.CognitiveLoad != models.Intermediate {
+		t.Errorf("Session fields not migrated: %+v", history)
+	}
+	if warnings := history.Config.TimeWarnings; len(warnings) != 2 || warnings[0] != 10*time.Minute || warnings[1] != time.Minute {
+		t.Errorf("Time warnings not migrated: %v", warnings)
+	}
+	if progress := history.Progress[0]; progress.CompileErrors != 1 || len(progress.AttemptHistory) != 3 || !progress.AttemptHistory[0].CompileError {
+		t.Errorf("Attempt history not migrated: %+v", progress)
+	}
+	if len(history.Decisions) != 1 || history.Decisions[0].Level != models.Advanced {
+		t.Errorf("Decisions not migrated: %+v", history.Decisions)
+	}
+
+	current, err := sessionStorage.LoadSession("v1")
+	if err != nil {
+		t.Fatalf("Failed to load current session: %v", err)
+	}
+	if current.Config.TimeLimit != 45*time.Minute || current.ActiveTime != 270*time.Second || current.Progress[0].TimeSpent != 4*time.Minute {
+		t.Errorf("Durations not read: %+v", current)
+	}
+	if len(current.Progress[0].Challenges) != 1 || len(current.Mastery) != 1 || current.Progress[0].AttemptHistory[1].Answer == "" {
+		t.Errorf("Current fields not read: %+v", current)
+	}
+
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 3 {
+		t.Errorf("Expected all three versions to be listed, got %d (%v)", len(sessions), err)
+	}
+}
+
+func TestMigrateSessionFiles(t *testing.T) {
+	dir := copyFixtures(t)
+	original, _ := os.ReadFile(filepath.Join(dir, "v0-baseline.json"))
+	sessionStorage := storage.NewFileSessionStorage(dir)
+	before, err := sessionStorage.LoadSession("v0-baseline")
+	if err != nil {
+		t.Fatalf("Failed to load baseline session: %v", err)
+	}
+
+	results, err := sessionStorage.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected the two version 0 files to be upgraded, got %+v", results)
+	}
+	for _, result := range results {
+		if result.From != 0 {
+			t.Errorf("Expected %s to come from version 0, got %d", result.SessionID, result.From)
+		}
+	}
+
+	backup, err := os.ReadFile(filepath.Join(dir, "v0-baseline.json.v0.bak"))
+	if err != nil || !bytes.Equal(backup, original) {
+		t.Errorf("Expected an exact backup of the original file (%v)", err)
+	}
+
+	var envelope struct {
+		SchemaVersion int             `json:"schema_version"`
+		Session       json.RawMessage `json:"session"`
+	}
+	upgraded, _ := os.ReadFile(filepath.Join(dir, "v0-baseline.json"))
+	if err := json.Unmarshal(upgraded, &envelope); err != nil || envelope.SchemaVersion != storage.SchemaVersion || len(envelope.Session) == 0 {
+		t.Errorf("Expected the file to be rewritten in schema version %d (%v)", storage.SchemaVersion, err)
+	}
+	after, err := sessionStorage.LoadSession("v0-baseline")
+	if err != nil || after.Progress[0].TimeSpent != before.Progress[0].TimeSpent || after.Config.TimeLimit != before.Config.TimeLimit {
+		t.Errorf("Expected the upgraded file to load the same session (%v)", err)
+	}
+
+	results, err = sessionStorage.Migrate()
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected a second migration to do nothing, got %+v (%v)", results, err)
+	}
+}
+
+func TestNewerSchemaVersionIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	future := []byte(`{"schema_version": 99, "session": {"session_id": "future"}}`)
+	if err := os.WriteFile(filepath.Join(dir, "future.json"), future, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := storage.NewFileSessionStorage(dir).LoadSession("future")
+	var versionErr *storage.VersionError
+	if !errors.As(err, &versionErr) || versionErr.Version != 99 {
+		t.Errorf("Expected a VersionError, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package unit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+func TestProfileIDs(t *testing.T) {
+	for _, id := range []string{"default", "alice", "bob.smith", "team-3_a"} {
+		if err := profile.ValidateID(id); err != nil {
+			t.Errorf("Expected %q to be a valid user, got %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "Alice", "../etc", ".hidden", "a b", "x/y", "abcdefghijklmnopqrstuvwxyz0123456789"} {
+		if err := profile.ValidateID(id); err == nil {
+			t.Errorf("Expected %q to be rejected", id)
+		}
+	}
+}
+
+func TestProfileSettingsAndPassphrase(t *testing.T) {
+	p, err := profile.New("alice", "Alice")
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+
+	// Without preferences the defaults apply, at the learner's level
+	defaults := models.TrainerConfig{MaxAttempts: 3, ShowHints: true, CognitiveLoad: models.Beginner}
+	p.Level = models.Intermediate
+	config, err := p.TrainerConfig(defaults)
+	if err != nil || config.MaxAttempts != 3 || !config.ShowHints || config.CognitiveLoad != models.Intermediate {
+		t.Errorf("Expected defaults at intermediate level, got %+v (%v)", config, err)
+	}
+
+	// Only the settings chosen are kept; the rest follow the defaults
+	chosen := models.TrainerConfig{MaxAttempts: 5, TimeLimit: 30 * time.Minute}
+	if err := p.Prefer(chosen, "max_attempts", "time_limit"); err != nil {
+		t.Fatalf("Failed to prefer settings: %v", err)
+	}
+	if err := p.Prefer(chosen, "mastery_threshold"); err != nil {
+		t.Fatalf("Failed to prefer a zero setting: %v", err)
+	}
+	defaults.MasteryThreshold = 0.75
+	config, err = p.TrainerConfig(defaults)
+	if err != nil || config.MaxAttempts != 5 || config.TimeLimit != 30*time.Minute || !config.ShowHints || config.MasteryThreshold != 0 || config.CognitiveLoad != models.Intermediate {
+		t.Errorf("Expected preferred settings over the defaults at intermediate level, got %+v (%v)", config, err)
+	}
+	if err := p.Prefer(chosen, "no_such_setting"); err == nil {
+		t.Error("Expected an unknown setting to be refused")
+	}
+
+	if p.Protected() || p.Unlock("anything") != nil {
+		t.Error("Expected an unprotected profile to open without a passphrase")
+	}
+	if err := p.SetPassphrase("correct horse"); err != nil {
+		t.Fatalf("Failed to set passphrase: %v", err)
+	}
+	if !p.Protected() {
+		t.Error("Expected profile to be protected")
+	}
+	if err := p.Unlock("correct horse"); err != nil {
+		t.Errorf("Expected the right passphrase to unlock, got %v", err)
+	}
+	if err := p.Unlock("wrong"); !errors.Is(err, profile.ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+	if err := p.SetPassphrase(""); err != nil || p.Protected() {
+		t.Errorf("Expected an empty passphrase to remove protection, got %v", err)
+	}
+}
+
+func TestFileProfileStorage(t *testing.T) {
+	profiles := storage.NewFileProfileStorage(t.TempDir())
+
+	if current, err := profiles.CurrentUser(); err != nil || current != profile.DefaultID {
+		t.Errorf("Expected the default user before any switch, got %q, %v", current, err)
+	}
+	var notFound *storage.ProfileNotFoundError
+	if _, err := profiles.LoadProfile("alice"); !errors.As(err, &notFound) {
+		t.Errorf("Expected ProfileNotFoundError, got %v", err)
+	}
+	if err := profiles.SetCurrentUser("alice"); err == nil {
+		t.Error("Expected switching to a missing user to fail")
+	}
+
+	for _, id := range []string{"bob", "alice"} {
+		p, _ := profile.New(id, "")
+		p.Prefer(models.TrainerConfig{MaxAttempts: 4}, "max_attempts")
+		if err := profiles.SaveProfile(p); err != nil {
+			t.Fatalf("Failed to save profile: %v", err)
+		}
+	}
+	list, err := profiles.ListProfiles()
+	if err != nil || len(list) != 2 || list[0].ID != "alice" || list[1].ID != "bob" {
+		t.Fatalf("Expected alice and bob, got %d profiles, %v", len(list), err)
+	}
+	if config, err := list[0].TrainerConfig(models.TrainerConfig{MaxAttempts: 3}); err != nil || config.MaxAttempts != 4 {
+		t.Errorf("Expected saved settings to round-trip, got %s (%v)", list[0].Config, err)
+	}
+
+	if err := profiles.SetCurrentUser("bob"); err != nil {
+		t.Fatalf("Failed to switch user: %v", err)
+	}
+	if current, _ := profiles.CurrentUser(); current != "bob" {
+		t.Errorf("Expected bob to be current, got %q", current)
+	}
+
+	// Each user's sessions are stored apart from everyone else's
+	for _, id := range []string{"alice", "bob"} {
+		sessions := storage.NewFileSessionStorage(profiles.SessionDir(id))
+		if err := sessions.SaveSession(&models.TrainingSession{UserID: id, SessionID: "shared-id", Status: models.SessionPaused}); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+	session, err := storage.NewFileSessionStorage(profiles.SessionDir("alice")).LoadSession("shared-id")
+	if err != nil || session.UserID != "alice" {
+		t.Errorf("Expected alice's own session, got %+v, %v", session, err)
+	}
+}
+
+func TestAdoptLegacyData(t *testing.T) {
+	root := t.TempDir()
+	sessionsDir := filepath.Join(root, "sessions")
+	reviewsDir := filepath.Join(root, "reviews")
+	legacy := storage.NewFileSessionStorage(sessionsDir)
+	if err := legacy.SaveSession(&models.TrainingSession{UserID: profile.DefaultID, SessionID: "old", Status: models.SessionPaused}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	os.MkdirAll(reviewsDir, 0755)
+	os.WriteFile(filepath.Join(reviewsDir, "default.json"), []byte(`{"user_id": "default", "cards": []}`), 0644)
+
+	profiles := storage.NewFileProfileStorage(filepath.Join(root, "users"))
+	adopted, err := profiles.AdoptLegacyData(sessionsDir, reviewsDir)
+	if err != nil || !adopted {
+		t.Fatalf("Expected legacy data to be adopted, got %v, %v", adopted, err)
+	}
+	if _, err := profiles.LoadProfile(profile.DefaultID); err != nil {
+		t.Errorf("Expected a default profile, got %v", err)
+	}
+	if _, err := storage.NewFileSessionStorage(profiles.SessionDir(profile.DefaultID)).LoadSession("old"); err != nil {
+		t.Errorf("Expected the old session in the default user's directory, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(profiles.ReviewDir(profile.DefaultID), "default.json")); err != nil {
+		t.Errorf("Expected the review schedule to move, got %v", err)
+	}
+
+	if adopted, err := profiles.AdoptLegacyData(sessionsDir, reviewsDir); err != nil || adopted {
+		t.Errorf("Expected a second adoption to do nothing, got %v, %v", adopted, err)
+	}
+}
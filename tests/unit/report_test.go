@@ -0,0 +1,225 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/report"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// testKey signs certificates in tests
+var testKey = bytes.Repeat([]byte{7}, report.KeySize)
+
+// reportSession is a completed session of two exercises, one of which is
+// no longer offered
+func reportSession() *models.TrainingSession {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	return &models.TrainingSession{
+		SessionID: "session-1", UserID: "alice", Status: models.SessionCompleted,
+		StartTime: start, LastActivity: start.Add(time.Hour), ActiveTime: 50 * time.Minute,
+		Progress: []models.LearningProgress{
+			completedProgress("variables", start.Add(20*time.Minute), 92.5, 20, 0, 1),
+			completedProgress("retired", start.Add(45*time.Minute), 70, 25, 2),
+		},
+	}
+}
+
+// reportExercises supplies titles and goals for reportSession
+func reportExercises() []models.Exercise {
+	return []models.Exercise{{
+		ID: "variables", Title: "Variables & <Types>", EstimatedTime: 15,
+		LearningGoals: []string{"Declare variables with var and :=", "Use *zero values*"},
+	}}
+}
+
+func TestReportBuild(t *testing.T) {
+	now := time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC)
+	doc, err := report.Build(reportSession(), reportExercises(), "Alice", now, testKey)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if doc.Completed != 2 || doc.Total != 2 || doc.Score != 81.25 || doc.HintsUsed != 3 || doc.TimeSpent != 50*time.Minute {
+		t.Errorf("Unexpected totals: %+v", doc)
+	}
+	if len(doc.Goals) != 2 || doc.Goals[0] != "Declare variables with var and :=" {
+		t.Errorf("Expected the completed exercise's goals, got %v", doc.Goals)
+	}
+	first, retired := doc.Exercises[0], doc.Exercises[1]
+	if first.Title != "Variables & <Types>" || first.Estimated != 15*time.Minute || first.Score != 92.5 {
+		t.Errorf("Unexpected exercise result: %+v", first)
+	}
+	if retired.Title != "retired" || len(retired.Goals) != 0 {
+		t.Errorf("Expected an exercise no longer offered to be reported by ID, got %+v", retired)
+	}
+	if !doc.Certificate.Awarded || len(doc.Certificate.Hash) != 64 {
+		t.Errorf("Expected a certificate with an HMAC-SHA256 hash, got %+v", doc.Certificate)
+	}
+
+	paused := reportSession()
+	paused.Status = models.SessionPaused
+	paused.Progress[1].CompletedAt = nil
+	doc, err = report.Build(paused, reportExercises(), "", now, testKey)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if doc.Certificate.Awarded || doc.Completed != 1 || doc.Learner != "alice" {
+		t.Errorf("Expected no certificate for a paused session, got %+v", doc)
+	}
+}
+
+func TestReportVerify(t *testing.T) {
+	session := reportSession()
+	hash, err := report.SessionHash(session, testKey)
+	if err != nil {
+		t.Fatalf("SessionHash failed: %v", err)
+	}
+	if ok, _ := report.Verify(session, strings.ToUpper(hash), testKey); !ok {
+		t.Error("Expected the session's own hash to verify")
+	}
+	if ok, _ := report.Verify(session, hash, bytes.Repeat([]byte{1}, report.KeySize)); ok {
+		t.Error("Expected another install's key to fail verification")
+	}
+	if ok, _ := report.Verify(session, "not hex", testKey); ok {
+		t.Error("Expected a malformed hash to fail verification")
+	}
+
+	session.Progress[0].Score = 100
+	if ok, _ := report.Verify(session, hash, testKey); ok {
+		t.Error("Expected a changed score to fail verification")
+	}
+}
+
+func TestReportVerifyArchived(t *testing.T) {
+	sessions := storage.NewFileSessionStorage(t.TempDir())
+	archive := storage.NewFileSessionStorage(t.TempDir())
+	if err := sessions.SaveSession(reportSession()); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	live, _ := sessions.LoadSession("session-1")
+	hash, err := report.SessionHash(live, testKey)
+	if err != nil {
+		t.Fatalf("SessionHash failed: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond) // Archiving stamps a later LastActivity
+	if err := storage.MoveSession("session-1", sessions, archive); err != nil {
+		t.Fatalf("Failed to archive session: %v", err)
+	}
+	archived, err := archive.LoadSession("session-1")
+	if err != nil {
+		t.Fatalf("Failed to load archived session: %v", err)
+	}
+	if archived.LastActivity.Equal(live.LastActivity) {
+		t.Fatal("Expected archiving to save the session again")
+	}
+	if ok, _ := report.Verify(archived, hash, testKey); !ok {
+		t.Error("Expected a certificate to verify against the archived session")
+	}
+}
+
+func TestReportLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trainer", "report.key")
+	key, err := report.LoadKey(path)
+	if err != nil || len(key) != report.KeySize {
+		t.Fatalf("Expected a new key, got %d bytes (%v)", len(key), err)
+	}
+	if again, err := report.LoadKey(path); err != nil || !bytes.Equal(again, key) {
+		t.Errorf("Expected the same key on the next load (%v)", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0077 != 0 {
+		t.Errorf("Expected the key to be private, got %v (%v)", info.Mode(), err)
+	}
+
+	os.WriteFile(path, []byte("short"), 0600)
+	if _, err := report.LoadKey(path); err == nil {
+		t.Error("Expected a damaged key to be rejected")
+	}
+}
+
+func TestReportRender(t *testing.T) {
+	doc, err := report.Build(reportSession(), reportExercises(), "Alice <admin>", time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC), testKey)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	render := func(format report.Format) string {
+		t.Helper()
+		tmpl, err := report.New(format)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", format, err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Render(&out, doc); err != nil {
+			t.Fatalf("Render(%s) failed: %v", format, err)
+		}
+		return out.String()
+	}
+
+	html := render(report.HTML)
+	for _, want := range []string{"<!DOCTYPE html>", "Alice &lt;admin&gt;", "Variables &amp; &lt;Types&gt;", "92.5", "Certificate of Completion", doc.Certificate.Hash, "<style>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Expected HTML report to contain %q", want)
+		}
+	}
+	for _, external := range []string{"<link", "<script", "src=", "http://", "https://"} {
+		if strings.Contains(html, external) {
+			t.Errorf("Expected a self-contained HTML report, found %q", external)
+		}
+	}
+	if strings.Contains(html, "@page") {
+		t.Error("Expected print rules only in the pdf-ready format")
+	}
+	if print := render(report.PrintHTML); !strings.Contains(print, "@page") || !strings.Contains(print, "Certificate of Completion") {
+		t.Error("Expected the pdf-ready report to add print rules to the HTML report")
+	}
+
+	md := render(report.Markdown)
+	for _, want := range []string{"# Go Fundamentals Progress Report", `Variables & \<Types\>`, `Use \*zero values\*`, "| 92.5 |", "## Certificate of Completion", "`" + doc.Certificate.Hash + "`"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected Markdown report to contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestReportCustomTemplate(t *testing.T) {
+	doc, err := report.Build(reportSession(), reportExercises(), "Alice", time.Now(), testKey)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	dir := t.TempDir()
+	block := filepath.Join(dir, "block.tmpl")
+	os.WriteFile(block, []byte(`{{define "title"}}Acme onboarding: {{.Learner}}{{end}}`), 0644)
+	whole := filepath.Join(dir, "whole.tmpl")
+	os.WriteFile(whole, []byte(`Certificate for {{.Learner}}: {{score .Score}}`), 0644)
+
+	tests := []struct {
+		name, path, want, unwanted string
+	}{
+		{"block override", block, "<title>Acme onboarding: Alice</title>", "<title>Go Fundamentals"},
+		{"whole template", whole, "Certificate for Alice: 81.2", "<!DOCTYPE html>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := report.Load(report.HTML, tt.path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			var out bytes.Buffer
+			if err := tmpl.Render(&out, doc); err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if !strings.Contains(out.String(), tt.want) || strings.Contains(out.String(), tt.unwanted) {
+				t.Errorf("Expected %q and not %q in:\n%s", tt.want, tt.unwanted, out.String())
+			}
+		})
+	}
+
+	if _, err := report.ParseFormat("pdf"); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/cli"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+)
+
+// saveAged saves a session started daysAgo days before now
+func saveAged(t *testing.T, store storage.SessionStorage, id string, status models.SessionStatus, now time.Time, daysAgo int) {
+	t.Helper()
+	session := &models.TrainingSession{
+		UserID:    "alice",
+		SessionID: id,
+		Status:    status,
+		StartTime: now.AddDate(0, 0, -daysAgo),
+	}
+	if err := store.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+}
+
+// sessionIDs lists the IDs of a user's stored sessions, sorted
+func sessionIDs(t *testing.T, store storage.SessionStorage, userID string) []string {
+	t.Helper()
+	sessions, err := store.ListSessions(userID)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	var ids []string
+	for _, session := range sessions {
+		ids = append(ids, session.SessionID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestRetentionPolicy_Apply(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	sessions := storage.NewFileSessionStorage(t.TempDir())
+	archive := storage.NewFileSessionStorage(t.TempDir())
+
+	saveAged(t, sessions, "recent", models.SessionCompleted, now, 10)
+	saveAged(t, sessions, "old", models.SessionCompleted, now, 100)
+	saveAged(t, sessions, "ancient", models.SessionCompleted, now, 400)
+	saveAged(t, sessions, "old-paused", models.SessionPaused, now, 400)
+	saveAged(t, archive, "archived-ancient", models.SessionCompleted, now, 500)
+	saveAged(t, archive, "archived-old", models.SessionCompleted, now, 200)
+
+	policy := storage.RetentionPolicy{ArchiveAfter: 90 * 24 * time.Hour, DeleteAfter: 365 * 24 * time.Hour}
+	result, err := policy.Apply("alice", sessions, archive, now)
+	if err != nil {
+		t.Fatalf("Failed to apply policy: %v", err)
+	}
+
+	if len(result.Archived) != 1 || result.Archived[0] != "old" {
+		t.Errorf("Expected only 'old' to be archived, got %v", result.Archived)
+	}
+	sort.Strings(result.Deleted)
+	if len(result.Deleted) != 2 || result.Deleted[0] != "ancient" || result.Deleted[1] != "archived-ancient" {
+		t.Errorf("Expected 'ancient' and 'archived-ancient' to be deleted, got %v", result.Deleted)
+	}
+	if got := sessionIDs(t, sessions, "alice"); len(got) != 2 || got[0] != "old-paused" || got[1] != "recent" {
+		t.Errorf("Expected paused and recent sessions to be kept, got %v", got)
+	}
+	if got := sessionIDs(t, archive, "alice"); len(got) != 2 || got[0] != "archived-old" || got[1] != "old" {
+		t.Errorf("Expected the archive to hold 'archived-old' and 'old', got %v", got)
+	}
+
+	// A zero policy keeps everything
+	result, err = storage.RetentionPolicy{}.Apply("alice", sessions, archive, now.AddDate(10, 0, 0))
+	if err != nil || len(result.Archived)+len(result.Deleted) != 0 {
+		t.Errorf("Expected a zero policy to do nothing, got %+v, %v", result, err)
+	}
+}
+
+func TestRemoveSession_InUse(t *testing.T) {
+	sessions := storage.NewFileSessionStorage(t.TempDir())
+	now := time.Now()
+	saveAged(t, sessions, "open", models.SessionActive, now, 0)
+
+	unlock, err := sessions.LockSession("open")
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+	if err := storage.RemoveSession(sessions, "open"); !errors.Is(err, storage.ErrSessionInUse) {
+		t.Errorf("Expected ErrSessionInUse removing a held session, got %v", err)
+	}
+	unlock()
+
+	if err := storage.RemoveSession(sessions, "open"); err != nil {
+		t.Fatalf("Failed to remove session: %v", err)
+	}
+	if _, err := sessions.LoadSession("open"); !errors.Is(err, storage.ErrSessionNotFound) {
+		t.Errorf("Expected the session to be gone, got %v", err)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"36h", 36 * time.Hour, false},
+		{"0d", 0, false},
+		{"-3d", 0, true},
+		{"1.5d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := cli.ParseAge(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAge(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if time.Duration(got) != tt.want {
+				t.Errorf("ParseAge(%q) = %v, want %v", tt.text, time.Duration(got), tt.want)
+			}
+		})
+	}
+
+	if got := cli.Age(30 * 24 * time.Hour).String(); got != "30d" {
+		t.Errorf("Expected whole days to format as days, got %q", got)
+	}
+}
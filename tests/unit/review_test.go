@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/review"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+func TestReviewSchedule(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	card := &review.Card{ExerciseID: "points", Challenge: 0}
+
+	expected := []int{1, 6, 15}
+	at := start
+	for i, interval := range expected {
+		card.Review(4, at)
+		if card.Interval != interval {
+			t.Fatalf("Review %d: expected interval %d, got %d", i+1, interval, card.Interval)
+		}
+		at = card.Due
+	}
+	if !card.Due.Equal(at) || card.IsDue(at.Add(-time.Hour)) || !card.IsDue(at) {
+		t.Errorf("Expected the card to fall due at %v, got %v", at, card.Due)
+	}
+
+	ease := card.Ease
+	card.Review(1, at)
+	if card.Interval != 1 || card.Repetitions != 0 || card.Lapses != 1 {
+		t.Errorf("Expected a lapse to reset the card, got %+v", card)
+	}
+	if card.Ease >= ease {
+		t.Errorf("Expected a lapse to lower ease from %.2f, got %.2f", ease, card.Ease)
+	}
+
+	for _, tc := range []struct {
+		attempts, hints int
+		solved          bool
+		quality         int
+	}{
+		{0, 0, false, 0},
+		{3, 1, false, 1},
+		{1, 0, true, 5},
+		{2, 0, true, 4},
+		{1, 1, true, 3},
+	} {
+		if q := review.Quality(tc.attempts, tc.hints, tc.solved); q != tc.quality {
+			t.Errorf("Quality(%d, %d, %v): expected %d, got %d", tc.attempts, tc.hints, tc.solved, tc.quality, q)
+		}
+	}
+}
+
+func TestDeckImportsSessionsOnce(t *testing.T) {
+	at := time.Now().Add(-48 * time.Hour)
+	session := &models.TrainingSession{
+		SessionID: "session-1",
+		Progress: []models.LearningProgress{{
+			ExerciseID: "points",
+			AttemptHistory: []models.AttemptRecord{
+				{Challenge: 0, Attempt: 1, SubmittedAt: at, Passed: true},
+				{Challenge: 1, Attempt: 1, SubmittedAt: at.Add(time.Minute)},
+				{Challenge: 1, Attempt: 2, SubmittedAt: at.Add(2 * time.Minute), Passed: true},
+			},
+		}},
+	}
+
+	deck := review.NewDeck("test-user")
+	if n := deck.Import(session); n != 2 {
+		t.Fatalf("Expected 2 challenges imported, got %d", n)
+	}
+	if n := deck.Import(session); n != 0 {
+		t.Errorf("Expected a second import to add nothing, got %d", n)
+	}
+
+	first, _ := deck.Card("points", 0)
+	second, _ := deck.Card("points", 1)
+	if first == nil || second == nil || first.Repetitions != 1 || second.Repetitions != 1 {
+		t.Fatalf("Expected one review per challenge, got %+v and %+v", first, second)
+	}
+	if due := deck.Due(time.Now()); len(due) != 2 {
+		t.Errorf("Expected both challenges due a day later, got %d", len(due))
+	}
+
+	// Attempts made after resuming are imported on the next pass
+	session.Progress[0].AttemptHistory = append(session.Progress[0].AttemptHistory,
+		models.AttemptRecord{Challenge: 2, Attempt: 1, SubmittedAt: at.Add(time.Hour), Passed: true})
+	if n := deck.Import(session); n != 1 {
+		t.Errorf("Expected only the new attempt to be imported, got %d", n)
+	}
+}
+
+func TestReviewSession(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	reviewStorage := storage.NewFileReviewStorage(t.TempDir())
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	exercise := pointExercise()
+
+	// Train one challenge, skip the next, then quit
+	config := models.TrainerConfig{MaxAttempts: 3}
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{exercise}, config, "test-user", sessionStorage)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}),
+		&scriptedInput{lines: []string{"", answer, "skip", "pause"}})
+	cltTrainer.Start()
+
+	sessions, err := trainer.ListUserSessions("test-user", sessionStorage)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one saved session, got %v (%v)", sessions, err)
+	}
+
+	deck, err := reviewStorage.LoadDeck("test-user")
+	if err != nil {
+		t.Fatalf("Failed to load an empty deck: %v", err)
+	}
+	if n := trainer.ImportSessions(deck, sessions); n != 1 {
+		t.Fatalf("Expected the answered challenge to be scheduled, got %d", n)
+	}
+
+	// Nothing is due until tomorrow
+	card, _ := deck.Card("points", 0)
+	card.Due = time.Now().Add(-time.Minute)
+
+	var output bytes.Buffer
+	reviewer := trainer.NewReviewer([]models.Exercise{exercise}, config, deck, reviewStorage)
+	reviewer.SetIO(trainer.NewTerminal(strings.NewReader(""), &output), &scriptedInput{lines: []string{answer}})
+	reviewed, err := reviewer.Run()
+	if err != nil || reviewed != 1 {
+		t.Fatalf("Expected one challenge reviewed, got %d (%v)", reviewed, err)
+	}
+	for _, expected := range []string{"Review 1/1: Points", "Next review: in 6 days"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q", expected)
+		}
+	}
+
+	saved, err := reviewStorage.LoadDeck("test-user")
+	if err != nil {
+		t.Fatalf("Failed to reload deck: %v", err)
+	}
+	card, ok := saved.Card("points", 0)
+	if !ok || card.Repetitions != 2 || card.Interval != 6 {
+		t.Errorf("Expected the review to be persisted, got %+v", card)
+	}
+	if len(saved.Due(time.Now())) != 0 {
+		t.Error("Expected nothing left to review")
+	}
+}
+
+func TestSaveDeckLeavesOnlyTheDeck(t *testing.T) {
+	dir := t.TempDir()
+	reviewStorage := storage.NewFileReviewStorage(dir)
+	deck := review.NewDeck("test-user")
+	imported := trainer.ImportSessions(deck, []*models.TrainingSession{{
+		UserID: "test-user", SessionID: "s1",
+		Progress: []models.LearningProgress{{
+			ExerciseID:     "points",
+			AttemptHistory: []models.AttemptRecord{{Challenge: 0, Attempt: 1, SubmittedAt: time.Now(), Passed: true}},
+		}},
+	}})
+	if imported != 1 {
+		t.Fatalf("Expected one card, got %d", imported)
+	}
+	for range 2 {
+		if err := reviewStorage.SaveDeck(deck); err != nil {
+			t.Fatalf("Failed to save deck: %v", err)
+		}
+	}
+
+	// The deck is written beside the old one and renamed over it
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || entries[0].Name() != "test-user.json" {
+		t.Fatalf("Expected only the deck file, got %v (%v)", entries, err)
+	}
+	if saved, err := reviewStorage.LoadDeck("test-user"); err != nil || len(saved.Cards) != len(deck.Cards) {
+		t.Errorf("Expected the saved deck back, got %+v (%v)", saved, err)
+	}
+}
@@ -0,0 +1,303 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/sandbox"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+func requireGoToolchain(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+}
+
+func TestSandboxRunsProgram(t *testing.T) {
+	requireGoToolchain(t)
+	runner := sandbox.NewRunner(sandbox.DefaultLimits)
+
+	result, err := runner.Run(context.Background(), "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Built || result.Stdout != "hi\n" || result.ExitCode != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestSandboxReportsBuildErrors(t *testing.T) {
+	requireGoToolchain(t)
+	runner := sandbox.NewRunner(sandbox.DefaultLimits)
+
+	result, err := runner.Run(context.Background(), "package main\n\nfunc main() {\n\tx := 1\n}\n")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Built {
+		t.Fatal("Expected build to fail")
+	}
+	if !strings.Contains(result.BuildOutput, "main.go:4") {
+		t.Errorf("Expected build output to point at main.go:4, got %q", result.BuildOutput)
+	}
+}
+
+func TestSandboxTimeout(t *testing.T) {
+	requireGoToolchain(t)
+	limits := sandbox.DefaultLimits
+	limits.RunTimeout = time.Second
+	runner := sandbox.NewRunner(limits)
+
+	result, err := runner.Run(context.Background(), "package main\n\nfunc main() {\n\tfor {\n\t}\n}\n")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.TimedOut {
+		t.Errorf("Expected infinite loop to time out, got %+v", result)
+	}
+
+	// A busy machine can cause a timeout, so a retry runs the program again
+	again, err := runner.Run(context.Background(), "package main\n\nfunc main() {\n\tfor {\n\t}\n}\n")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if again == result {
+		t.Error("Expected a timed-out result not to be cached")
+	}
+}
+
+func TestSandboxCacheSize(t *testing.T) {
+	requireGoToolchain(t)
+	runner := sandbox.NewRunner(sandbox.DefaultLimits)
+	runner.CacheSize = 1
+	program := func(n int) string {
+		return fmt.Sprintf("package main\n\nfunc main() {\n\tprintln(%d)\n}\n", n)
+	}
+	run := func(n int) *sandbox.Result {
+		t.Helper()
+		result, err := runner.Run(context.Background(), program(n))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return result
+	}
+
+	first := run(1)
+	if run(1) != first {
+		t.Error("Expected a repeated program to be served from the cache")
+	}
+	run(2)
+	if run(1) == first {
+		t.Error("Expected the cache to drop the oldest result once full")
+	}
+}
+
+func TestSandboxIsolation(t *testing.T) {
+	requireGoToolchain(t)
+	var warnings strings.Builder
+	runner := sandbox.NewRunner(sandbox.DefaultLimits)
+	runner.Warnings = &warnings
+
+	result, err := runner.Run(context.Background(), "package main\n\nfunc main() {}\n")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Isolated != (warnings.Len() == 0) {
+		t.Errorf("Expected a warning exactly when a program runs with network access; isolated %v, warning %q", result.Isolated, warnings.String())
+	}
+
+	strict := sandbox.NewRunner(sandbox.DefaultLimits)
+	strict.RequireIsolation = true
+	_, err = strict.Run(context.Background(), "package main\n\nfunc main() {}\n")
+	if result.Isolated && err != nil {
+		t.Errorf("Expected an isolated host to run programs when isolation is required, got %v", err)
+	}
+	if !result.Isolated && !errors.Is(err, sandbox.ErrIsolationUnavailable) {
+		t.Errorf("Expected ErrIsolationUnavailable when isolation is required but unavailable, got %v", err)
+	}
+}
+
+func TestOutputMismatchDiff(t *testing.T) {
+	requireGoToolchain(t)
+	exercise := exercises.GetFunctionsExercise()
+	challenge := exercise.Challenges[0]
+
+	// Structurally fine, but adds the wrong way
+	answer := "func add(a, b int) int {\n\treturn a + b + 1\n}"
+	result := validation.Evaluate(challenge.Template, answer, challenge.Checks()...)
+	if result.Passed() {
+		t.Fatal("Expected wrong output to fail")
+	}
+
+	var mismatch *validation.OutputMismatch
+	if len(result.Failures) != 1 || !errors.As(result.Failures[0], &mismatch) {
+		t.Fatalf("Expected an output mismatch, got %v", result.Failures)
+	}
+
+	if diff := mismatch.Diff(); diff != "- Result: 8\n+ Result: 9" {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := validation.Diff("a\nb\nc\n", "a\nc\nd")
+	expected := "  a\n- b\n  c\n+ d"
+	if diff != expected {
+		t.Errorf("Expected diff:\n%s\ngot:\n%s", expected, diff)
+	}
+}
+
+func TestHiddenTestsReportFailures(t *testing.T) {
+	requireGoToolchain(t)
+	exercise := exercises.GetStructsExercise()
+	challenge := exercise.Challenges[1]
+
+	if !challenge.IsTestDriven() {
+		t.Fatal("BankAccount challenge should carry hidden tests")
+	}
+
+	// Withdraw never refuses, so the insufficient funds test must fail
+	answer := `type BankAccount struct {
+    Owner   string
+    Balance float64
+}
+
+func (b *BankAccount) Deposit(amount float64) {
+    b.Balance += amount
+}
+
+func (b *BankAccount) Withdraw(amount float64) error {
+    b.Balance -= amount
+    return nil
+}
+
+func (b BankAccount) GetBalance() float64 {
+    return b.Balance
+}`
+	result := validation.Evaluate(challenge.Template, answer, validation.PassesTests(challenge.Tests))
+
+	var failures *validation.TestFailures
+	if len(result.Failures) != 1 || !errors.As(result.Failures[0], &failures) {
+		t.Fatalf("Expected hidden test failures, got %v", result.Failures)
+	}
+
+	names := failures.Report.FailedNames()
+	if len(names) != 1 || names[0] != "TestWithdrawInsufficientFunds" {
+		t.Fatalf("Expected TestWithdrawInsufficientFunds to fail, got %v", names)
+	}
+
+	messages := strings.Join(failures.Report.Failures[0].Messages, "\n")
+	if !strings.Contains(messages, "Withdraw(200) on balance 100 should return an error") {
+		t.Errorf("Expected failure message from the test, got %q", messages)
+	}
+}
+
+func TestHiddenTestsRequireTestsToRun(t *testing.T) {
+	requireGoToolchain(t)
+	exercise := exercises.GetStructsExercise()
+	challenge := exercise.Challenges[1]
+
+	// Exiting from init stops the test binary cleanly before any test runs
+	answer := `type BankAccount struct {
+    Owner   string
+    Balance float64
+}
+
+func (b *BankAccount) Deposit(amount float64) {}
+
+func (b *BankAccount) Withdraw(amount float64) error { return nil }
+
+func (b BankAccount) GetBalance() float64 { return 0 }
+
+func init() { os.Exit(0) }`
+	source := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\n" + answer + "\n\nfunc main() { fmt.Println() }\n"
+
+	report, err := sandbox.NewRunner(sandbox.DefaultLimits).Test(context.Background(), source, challenge.Tests)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if !report.Built {
+		t.Fatalf("Expected the program to build, got %s", report.BuildOutput)
+	}
+	if report.OK() {
+		t.Fatal("Expected a program that exits before its tests run to fail")
+	}
+	if len(report.Expected) == 0 || len(report.Failures) != len(report.Expected) {
+		t.Errorf("Expected every hidden test to be reported as not run, got %v of %v", report.FailedNames(), report.Expected)
+	}
+
+	if (&sandbox.TestReport{Built: true}).OK() {
+		t.Error("Expected a report with no hidden tests not to pass")
+	}
+}
+
+func TestHiddenTestsIgnoreSpoofedEvents(t *testing.T) {
+	requireGoToolchain(t)
+	exercise := exercises.GetStructsExercise()
+	challenge := exercise.Challenges[1]
+
+	// Withdraw never refuses, and the program claims every test passed
+	answer := `type BankAccount struct {
+    Owner   string
+    Balance float64
+}
+
+func (b *BankAccount) Deposit(amount float64) { b.Balance += amount }
+
+func (b *BankAccount) Withdraw(amount float64) error { b.Balance -= amount; return nil }
+
+func (b BankAccount) GetBalance() float64 { return b.Balance }
+
+func init() {
+    for _, name := range spoofed {
+        fmt.Printf("=== RUN   %s\n--- PASS: %s (0.00s)\n", name, name)
+    }
+    if exit {
+        fmt.Println("PASS")
+        os.Exit(0)
+    }
+}`
+	runner := sandbox.NewRunner(sandbox.DefaultLimits)
+	for _, exit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("exit=%v", exit), func(t *testing.T) {
+			report, err := runner.Test(context.Background(), spoofSource(answer, challenge.Tests, exit), challenge.Tests)
+			if err != nil {
+				t.Fatalf("Test failed: %v", err)
+			}
+			if !report.Built {
+				t.Fatalf("Expected the program to build, got %s", report.BuildOutput)
+			}
+			if report.OK() {
+				t.Fatalf("Expected spoofed events not to pass the hidden tests, passed %v", report.Passed)
+			}
+			for _, name := range report.Passed {
+				if name == "TestWithdrawInsufficientFunds" {
+					t.Errorf("Expected the failing test not to count as passed, got %v", report.Passed)
+				}
+			}
+		})
+	}
+}
+
+// spoofSource wraps an answer as a program that knows the hidden tests'
+// names and whether to exit from init
+func spoofSource(answer, tests string, exit bool) string {
+	var names []string
+	for _, line := range strings.Split(tests, "\n") {
+		if name, ok := strings.CutPrefix(line, "func "); ok && strings.HasPrefix(name, "Test") {
+			names = append(names, name[:strings.Index(name, "(")])
+		}
+	}
+	return fmt.Sprintf("package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nvar spoofed = %#v\n\nconst exit = %v\n\n%s\n\nfunc main() {}\n", names, exit, answer)
+}
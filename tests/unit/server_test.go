@@ -0,0 +1,325 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/cli"
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/profile"
+	"github.com/cmyers78/claude/internal/server"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+)
+
+// testLearners serves alice, and bob whose passphrase is "secret", each
+// with their own session storage
+type testLearners struct {
+	sessions map[string]storage.SessionStorage
+}
+
+func (l *testLearners) Learner(userID, passphrase string) (*server.Learner, error) {
+	sessions, ok := l.sessions[userID]
+	if !ok {
+		return nil, &storage.ProfileNotFoundError{UserID: userID}
+	}
+	if userID == "bob" && passphrase != "secret" {
+		return nil, profile.ErrWrongPassphrase
+	}
+	return &server.Learner{ID: userID, Config: cli.DefaultTrainerConfig(), Sessions: sessions}, nil
+}
+
+// startServer serves the built-in curriculum to alice and bob
+func startServer(t *testing.T) (*httptest.Server, *exercises.Registry) {
+	t.Helper()
+	registry := exercises.NewRegistry()
+	learners := &testLearners{sessions: map[string]storage.SessionStorage{
+		"alice": storage.NewFileSessionStorage(t.TempDir()),
+		"bob":   storage.NewFileSessionStorage(t.TempDir()),
+	}}
+	srv := server.New(registry, learners)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(func() {
+		srv.Shutdown(context.Background())
+		ts.Close()
+	})
+	return ts, registry
+}
+
+// call makes a request with a JSON body, decoding the response into out if
+// it's given, and returns the status
+func call(t *testing.T, ts *httptest.Server, method, path string, body any, out any, header ...string) int {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, ts.URL+path, reader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode %s %s response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// hasMessage reports whether a state carries a message of the given type
+func hasMessage(state server.State, messageType string) bool {
+	for _, message := range state.Messages {
+		if message.Type == messageType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServerExercises(t *testing.T) {
+	ts, registry := startServer(t)
+
+	var list []server.ExerciseInfo
+	if status := call(t, ts, "GET", "/api/exercises", nil, &list); status != http.StatusOK || len(list) != len(registry.GetAll()) {
+		t.Fatalf("Expected the whole curriculum, got %d with %d exercises", status, len(list))
+	}
+
+	first := registry.GetAll()[0]
+	resp, err := ts.Client().Get(ts.URL + "/api/exercises/" + first.ID)
+	if err != nil {
+		t.Fatalf("GET exercise failed: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var detail server.ExerciseDetail
+	if err := json.Unmarshal(data, &detail); err != nil || detail.ID != first.ID || len(detail.Examples) != len(first.Examples) {
+		t.Errorf("Unexpected exercise detail: %v %+v", err, detail)
+	}
+	if strings.Contains(string(data), first.Challenges[0].Solution) {
+		t.Error("Expected challenge solutions to be withheld")
+	}
+
+	if status := call(t, ts, "GET", "/api/exercises/missing", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown exercise, got %d", status)
+	}
+}
+
+func TestServerSession(t *testing.T) {
+	ts, registry := startServer(t)
+	exercise := registry.GetAll()[0]
+	challenge := exercise.Challenges[0]
+	sessions := "/api/users/alice/sessions"
+
+	var state server.State
+	if status := call(t, ts, "POST", sessions, server.CreateRequest{Exercises: []string{exercise.ID}}, &state); status != http.StatusCreated {
+		t.Fatalf("Expected 201 creating a session, got %d", status)
+	}
+	if state.Awaiting != server.AwaitingReady || state.Exercise == nil || len(state.Exercise.Examples) == 0 || state.Total != 1 {
+		t.Fatalf("Expected the exercise's examples first, got %+v", state)
+	}
+	session := sessions + "/" + state.SessionID
+
+	if status := call(t, ts, "POST", session+"/answer", server.AnswerRequest{Answer: "x"}, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 answering before the challenges, got %d", status)
+	}
+	if status := call(t, ts, "POST", session+"/commands", server.CommandRequest{Command: "dance"}, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an unknown command, got %d", status)
+	}
+
+	call(t, ts, "POST", session+"/commands", server.CommandRequest{Command: "ready"}, &state)
+	if state.Awaiting != server.AwaitingAnswer || state.Challenge == nil || state.Challenge.Number != 1 || state.Challenge.Template != challenge.Template {
+		t.Fatalf("Expected the first challenge, got %+v", state)
+	}
+
+	call(t, ts, "POST", session+"/answer", server.AnswerRequest{Answer: "this is not Go"}, &state)
+	if !hasMessage(state, server.MessageCompileErrors) || state.Awaiting != server.AwaitingAnswer {
+		t.Errorf("Expected compile errors for a wrong answer, got %+v", state.Messages)
+	}
+
+	call(t, ts, "POST", session+"/commands", server.CommandRequest{Command: "hint"}, &state)
+	if !hasMessage(state, server.MessageHint) || len(state.Challenge.Hints) != 1 || state.Challenge.HintsLeft != len(challenge.Hints)-1 {
+		t.Errorf("Expected the first hint, got %+v", state)
+	}
+
+	call(t, ts, "POST", session+"/answer", server.AnswerRequest{Answer: challenge.Solution}, &state)
+	if !hasMessage(state, server.MessageCorrect) {
+		t.Errorf("Expected the solution to be accepted, got %+v", state.Messages)
+	}
+
+	// Pause, and pick up where it left off
+	call(t, ts, "POST", session+"/pause", nil, &state)
+	if state.Running || state.Status != "paused" {
+		t.Errorf("Expected a paused session, got %+v", state)
+	}
+	if status := call(t, ts, "GET", session+"/current", nil, nil); status != http.StatusConflict {
+		t.Errorf("Expected 409 for a session not training, got %d", status)
+	}
+	var info server.SessionInfo
+	call(t, ts, "GET", session, nil, &info)
+	if info.Running || info.Status != "paused" {
+		t.Errorf("Expected the saved session to be paused, got %+v", info)
+	}
+
+	if status := call(t, ts, "POST", session+"/resume", nil, &state); status != http.StatusOK || !state.Running || !hasMessage(state, server.MessageResumed) {
+		t.Fatalf("Expected the session to resume, got %d %+v", status, state)
+	}
+	if state.Awaiting == server.AwaitingReady {
+		call(t, ts, "POST", session+"/commands", server.CommandRequest{Command: "ready"}, &state)
+	}
+	if state.Challenge == nil || state.Challenge.Number != 2 {
+		t.Fatalf("Expected to resume at the second challenge, got %+v", state.Challenge)
+	}
+	call(t, ts, "POST", session+"/commands", server.CommandRequest{Command: "skip"}, &state)
+	if state.Challenge == nil || state.Challenge.Number != 3 {
+		t.Errorf("Expected skip to move to the third challenge, got %+v", state.Challenge)
+	}
+
+	var list []server.SessionInfo
+	call(t, ts, "GET", sessions, nil, &list)
+	if len(list) != 1 || !list[0].Running {
+		t.Errorf("Expected one running session, got %+v", list)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	ts, _ := startServer(t)
+
+	tests := []struct {
+		name, method, path string
+		body               any
+		header             []string
+		want               int
+	}{
+		{"unknown learner", "GET", "/api/users/carol/sessions", nil, nil, http.StatusNotFound},
+		{"missing passphrase", "GET", "/api/users/bob/sessions", nil, nil, http.StatusForbidden},
+		{"passphrase", "GET", "/api/users/bob/sessions", nil, []string{server.PassphraseHeader, "secret"}, http.StatusOK},
+		{"unknown session", "POST", "/api/users/alice/sessions/missing/resume", nil, nil, http.StatusNotFound},
+		{"unknown exercise", "POST", "/api/users/alice/sessions", server.CreateRequest{From: "missing"}, nil, http.StatusNotFound},
+		{"bad request", "POST", "/api/users/alice/sessions", map[string]string{"exercise": "typo"}, nil, http.StatusBadRequest},
+		{"not training", "POST", "/api/users/alice/sessions/missing/answer", server.AnswerRequest{Answer: "x"}, nil, http.StatusConflict},
+		{"form post", "POST", "/api/users/alice/sessions", nil, []string{"Content-Type", "text/plain"}, http.StatusUnsupportedMediaType},
+		{"no content type", "POST", "/api/users/alice/sessions/missing/pause", nil, []string{"Content-Type", ""}, http.StatusUnsupportedMediaType},
+		{"foreign origin", "POST", "/api/users/alice/sessions", server.CreateRequest{}, []string{"Origin", "https://attacker.example"}, http.StatusForbidden},
+		{"opaque origin", "POST", "/api/users/alice/sessions", server.CreateRequest{}, []string{"Origin", "null"}, http.StatusForbidden},
+		{"own origin", "POST", "/api/users/alice/sessions/missing/resume", nil, []string{"Origin", ts.URL}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body json.RawMessage
+			status := call(t, ts, tt.method, tt.path, tt.body, &body, tt.header...)
+			if status != tt.want {
+				t.Errorf("Expected %d, got %d: %s", tt.want, status, body)
+			}
+			if status >= 400 && !strings.HasPrefix(string(body), `{"error":"`) {
+				t.Errorf("Expected an error message, got %s", body)
+			}
+		})
+	}
+}
+
+func TestServerConcurrentSessions(t *testing.T) {
+	ts, registry := startServer(t)
+	exercise := registry.GetAll()[0]
+
+	const sessions = 4
+	states := make([]server.State, sessions)
+	var wg sync.WaitGroup
+	for i := range states {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user := []string{"alice", "bob"}[i%2]
+			header := []string{server.PassphraseHeader, "secret"}
+			path := "/api/users/" + user + "/sessions"
+			call(t, ts, "POST", path, server.CreateRequest{Exercises: []string{exercise.ID}}, &states[i], header...)
+			call(t, ts, "POST", path+"/"+states[i].SessionID+"/commands", server.CommandRequest{Command: "ready"}, &states[i], header...)
+		}()
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, state := range states {
+		key := state.UserID + "/" + state.SessionID
+		if seen[key] {
+			t.Errorf("Expected every session to have its own ID, got %s twice", key)
+		}
+		seen[key] = true
+		if state.Awaiting != server.AwaitingAnswer || state.Challenge == nil || state.Challenge.Number != 1 {
+			t.Errorf("Expected each session at its first challenge, got %+v", state)
+		}
+	}
+}
+
+// panickingLearners serves alice with a listener that panics once she
+// submits an answer
+type panickingLearners struct {
+	testLearners
+}
+
+func (l *panickingLearners) Learner(userID, passphrase string) (*server.Learner, error) {
+	learner, err := l.testLearners.Learner(userID, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	learner.Attach = func(t *trainer.CLTTrainer) func() {
+		t.AddListener(trainer.ListenerFunc(func(event trainer.Event) {
+			if event.Type == trainer.AnswerSubmitted {
+				panic("listener failed")
+			}
+		}))
+		return nil
+	}
+	return learner, nil
+}
+
+func TestServerSessionPanic(t *testing.T) {
+	registry := exercises.NewRegistry()
+	sessions := storage.NewFileSessionStorage(t.TempDir())
+	srv := server.New(registry, &panickingLearners{testLearners{sessions: map[string]storage.SessionStorage{"alice": sessions}}})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	exercise := registry.GetAll()[0]
+
+	var state server.State
+	path := "/api/users/alice/sessions"
+	call(t, ts, "POST", path, server.CreateRequest{Exercises: []string{exercise.ID}}, &state)
+	path += "/" + state.SessionID
+	call(t, ts, "POST", path+"/commands", server.CommandRequest{Command: "ready"}, &state)
+	if status := call(t, ts, "POST", path+"/answer", server.AnswerRequest{Answer: exercise.Challenges[0].Solution}, &state); status != http.StatusOK {
+		t.Fatalf("Expected the failed session to be reported, got %d", status)
+	}
+	if state.Running || state.Status != models.SessionPaused || !hasMessage(state, server.MessageError) {
+		t.Errorf("Expected the session stopped and paused with an error, got %+v", state)
+	}
+
+	// The server carries on, and the session can be resumed
+	if status := call(t, ts, "GET", "/api/exercises", nil, nil); status != http.StatusOK {
+		t.Errorf("Expected the server to keep serving, got %d", status)
+	}
+	if saved, err := sessions.LoadSession(state.SessionID); err != nil || saved.Status != models.SessionPaused {
+		t.Errorf("Expected the session saved as paused, got %v (%v)", saved, err)
+	}
+}
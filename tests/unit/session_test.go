@@ -183,6 +183,19 @@ func TestFileSessionStorage_DeleteSession(t *testing.T) {
 		t.Errorf("Session file should be deleted")
 	}
 
+	// A lock held while the session is deleted still keeps others out
+	unlock, err := storage.LockSession("test-session-delete")
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+	defer unlock()
+	if err := storage.DeleteSession("test-session-delete"); err != nil {
+		t.Fatalf("Failed to delete locked session: %v", err)
+	}
+	if _, err := storage.LockSession("test-session-delete"); err == nil {
+		t.Error("Expected the lock to survive deleting the session")
+	}
+
 	// Test deleting non-existent session (should not error)
 	err = storage.DeleteSession("non-existent")
 	if err != nil {
@@ -211,7 +224,7 @@ func TestTrainingSession_StatusConstants(t *testing.T) {
 func TestTrainingSession_JSONSerialization(t *testing.T) {
 	now := time.Now()
 	pausedAt := now.Add(time.Minute)
-	
+
 	session := &models.TrainingSession{
 		UserID:       "test-user",
 		SessionID:    "test-session",
@@ -274,4 +287,71 @@ func TestTrainingSession_JSONSerialization(t *testing.T) {
 	if progress.TimeSpent != time.Minute*5 {
 		t.Errorf("Progress.TimeSpent mismatch: expected %v, got %v", time.Minute*5, progress.TimeSpent)
 	}
-}
\ No newline at end of file
+}
+
+func TestFileSessionStorage_AtomicSaveKeepsBackup(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionStorage := storage.NewFileSessionStorage(tempDir)
+	session := &models.TrainingSession{UserID: "test-user", SessionID: "atomic", CurrentIndex: 1, Status: models.SessionPaused}
+
+	if err := sessionStorage.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	session.CurrentIndex = 2
+	if err := sessionStorage.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tempDir, "*"))
+	hidden, _ := filepath.Glob(filepath.Join(tempDir, ".*"))
+	if len(files) != 2 || len(hidden) != 0 {
+		t.Errorf("Expected only the session and its backup, got %v %v", files, hidden)
+	}
+
+	// Corrupt the session as a crash mid-write would have
+	path := filepath.Join(tempDir, "atomic.json")
+	if err := os.WriteFile(path, []byte(`{"schema_version": 1, "sess`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := sessionStorage.LoadSession("atomic")
+	if err != nil {
+		t.Fatalf("Expected the session to be recovered from its backup: %v", err)
+	}
+	if recovered.CurrentIndex != 1 {
+		t.Errorf("Expected the previous good copy, got index %d", recovered.CurrentIndex)
+	}
+	if reloaded, err := sessionStorage.LoadSession("atomic"); err != nil || reloaded.CurrentIndex != 1 {
+		t.Errorf("Expected the session file to be restored, got %v (%v)", reloaded, err)
+	}
+
+	// A crash between keeping the backup and renaming the new file into place
+	if err := os.Rename(path, path+".bak"); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 || sessions[0].SessionID != "atomic" {
+		t.Errorf("Expected the session to be listed from its backup, got %v (%v)", sessions, err)
+	}
+
+	if err := sessionStorage.DeleteSession("atomic"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(tempDir, "atomic*")); len(files) != 0 {
+		t.Errorf("Expected every file of the session to be deleted, got %v", files)
+	}
+}
+
+func TestFileSessionStorage_CorruptWithoutBackup(t *testing.T) {
+	tempDir := t.TempDir()
+	sessionStorage := storage.NewFileSessionStorage(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sessionStorage.LoadSession("broken"); err == nil {
+		t.Error("Expected an error for a corrupt session without a backup")
+	}
+	if sessions, err := sessionStorage.ListSessions("test-user"); err != nil || len(sessions) != 0 {
+		t.Errorf("Expected the corrupt session to be skipped, got %v (%v)", sessions, err)
+	}
+}
@@ -1,6 +1,8 @@
 package unit
 
 import (
+	"bytes"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -14,7 +16,7 @@ import (
 func TestCLTTrainerCreation(t *testing.T) {
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	config := models.TrainerConfig{
 		MaxAttempts:    3,
 		TimeLimit:      time.Hour,
@@ -22,11 +24,11 @@ func TestCLTTrainerCreation(t *testing.T) {
 		AdaptivePacing: true,
 		CognitiveLoad:  models.Beginner,
 	}
-	
+
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
 	cltTrainer := trainer.NewCLTTrainer(exerciseList, config, "test-user", sessionStorage)
-	
+
 	if cltTrainer == nil {
 		t.Fatal("NewCLTTrainer returned nil")
 	}
@@ -40,11 +42,11 @@ func TestTrainerConfig(t *testing.T) {
 		AdaptivePacing: false,
 		CognitiveLoad:  models.Advanced,
 	}
-	
+
 	if config.MaxAttempts != 5 {
 		t.Errorf("Expected MaxAttempts 5, got %d", config.MaxAttempts)
 	}
-	
+
 	if config.CognitiveLoad != models.Advanced {
 		t.Error("Expected Advanced cognitive load")
 	}
@@ -58,11 +60,11 @@ func TestLearningProgress(t *testing.T) {
 		Score:      85.5,
 		HintsUsed:  1,
 	}
-	
+
 	if progress.ExerciseID != "variables" {
 		t.Error("Exercise ID not set correctly")
 	}
-	
+
 	if progress.Score != 85.5 {
 		t.Error("Score not set correctly")
 	}
@@ -72,7 +74,7 @@ func TestCognitiveLevels(t *testing.T) {
 	if models.Beginner >= models.Intermediate {
 		t.Error("Cognitive levels should be ordered")
 	}
-	
+
 	if models.Intermediate >= models.Advanced {
 		t.Error("Cognitive levels should be ordered")
 	}
@@ -80,11 +82,11 @@ func TestCognitiveLevels(t *testing.T) {
 
 func TestExerciseTypes(t *testing.T) {
 	exercise := exercises.GetVariablesExercise()
-	
+
 	if exercise.ExerciseType != models.Concept {
 		t.Error("Variables should be a concept exercise")
 	}
-	
+
 	functionsEx := exercises.GetFunctionsExercise()
 	if functionsEx.ExerciseType != models.Application {
 		t.Error("Functions should be an application exercise")
@@ -100,15 +102,15 @@ func TestProgressTracking(t *testing.T) {
 		HintsUsed:  2,
 		Score:      85.5,
 	}
-	
+
 	if progress.Attempts != 5 {
 		t.Error("Attempts field should be properly set")
 	}
-	
+
 	if progress.HintsUsed != 2 {
 		t.Error("HintsUsed field should be properly set")
 	}
-	
+
 	if progress.Score != 85.5 {
 		t.Error("Score field should be properly set")
 	}
@@ -118,7 +120,7 @@ func TestScoringComponents(t *testing.T) {
 	// Test that score calculation components are reasonable
 	// Note: Full scoring test would require creating a trainer instance
 	// This validates the scoring fields exist and can be set
-	
+
 	progress := models.LearningProgress{
 		ExerciseID: "variables",
 		StartTime:  time.Now().Add(-5 * time.Minute),
@@ -127,19 +129,19 @@ func TestScoringComponents(t *testing.T) {
 		Score:      78.5,
 		TimeSpent:  5 * time.Minute,
 	}
-	
+
 	completedAt := time.Now()
 	progress.CompletedAt = &completedAt
-	
+
 	// Verify all analytics fields are properly tracked
 	if progress.Score < 0 || progress.Score > 100 {
 		t.Error("Score should be between 0 and 100")
 	}
-	
+
 	if progress.TimeSpent <= 0 {
 		t.Error("TimeSpent should be positive")
 	}
-	
+
 	if progress.CompletedAt == nil {
 		t.Error("CompletedAt should be set for completed exercises")
 	}
@@ -149,7 +151,7 @@ func TestTerminalFormatting(t *testing.T) {
 	// Test that code formatting works for terminal display
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	config := models.TrainerConfig{
 		MaxAttempts:    3,
 		TimeLimit:      time.Hour,
@@ -157,20 +159,20 @@ func TestTerminalFormatting(t *testing.T) {
 		AdaptivePacing: true,
 		CognitiveLoad:  models.Beginner,
 	}
-	
+
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
 	trainer := trainer.NewCLTTrainer(exerciseList, config, "test-user", sessionStorage)
-	
+
 	// Test formatting method exists and works
 	testCode := "package main\n\nfunc main() {}"
 	formatted := trainer.FormatCodeBlock(testCode) // Need to make this method public for testing
-	
+
 	// Check that formatting doesn't contain markdown fences
 	if strings.Contains(formatted, "```") {
 		t.Error("Formatted output should not contain markdown fences")
 	}
-	
+
 	// Check that it contains terminal-friendly borders
 	if !strings.Contains(formatted, "┌") || !strings.Contains(formatted, "└") {
 		t.Error("Formatted output should contain terminal borders")
@@ -180,10 +182,10 @@ func TestTerminalFormatting(t *testing.T) {
 func TestCLTTrainerWithSessionStorage(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	config := models.TrainerConfig{
 		MaxAttempts:    3,
 		TimeLimit:      time.Hour,
@@ -191,9 +193,9 @@ func TestCLTTrainerWithSessionStorage(t *testing.T) {
 		AdaptivePacing: true,
 		CognitiveLoad:  models.Beginner,
 	}
-	
+
 	trainer := trainer.NewCLTTrainer(exerciseList, config, "test-user", sessionStorage)
-	
+
 	if trainer == nil {
 		t.Fatal("NewCLTTrainer with session storage returned nil")
 	}
@@ -202,10 +204,10 @@ func TestCLTTrainerWithSessionStorage(t *testing.T) {
 func TestTrainerSessionResume(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	// Create a session to resume
 	now := time.Now()
 	session := &models.TrainingSession{
@@ -233,33 +235,33 @@ func TestTrainerSessionResume(t *testing.T) {
 			},
 		},
 	}
-	
+
 	// Save the session
 	err := sessionStorage.SaveSession(session)
 	if err != nil {
 		t.Fatalf("Failed to save test session: %v", err)
 	}
-	
+
 	// Test resuming the session
 	resumedTrainer, err := trainer.ResumeSession("test-session-resume", exerciseList, sessionStorage)
 	if err != nil {
 		t.Fatalf("Failed to resume session: %v", err)
 	}
-	
+
 	if resumedTrainer == nil {
 		t.Fatal("ResumeSession returned nil trainer")
 	}
-	
+
 	// Verify session status was updated to active
 	updatedSession, err := sessionStorage.LoadSession("test-session-resume")
 	if err != nil {
 		t.Fatalf("Failed to load updated session: %v", err)
 	}
-	
+
 	if updatedSession.Status != models.SessionActive {
 		t.Errorf("Session status should be active after resume, got %s", updatedSession.Status)
 	}
-	
+
 	if updatedSession.PausedAt != nil {
 		t.Error("PausedAt should be nil after resume")
 	}
@@ -268,10 +270,10 @@ func TestTrainerSessionResume(t *testing.T) {
 func TestResumeNonExistentSession(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	_, err := trainer.ResumeSession("non-existent", exerciseList, sessionStorage)
 	if err == nil {
 		t.Fatal("Expected error when resuming non-existent session")
@@ -281,10 +283,10 @@ func TestResumeNonExistentSession(t *testing.T) {
 func TestResumeNonPausedSession(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	// Create an active session (not paused)
 	session := &models.TrainingSession{
 		UserID:    "test-user",
@@ -299,12 +301,12 @@ func TestResumeNonPausedSession(t *testing.T) {
 			CognitiveLoad:  models.Beginner,
 		},
 	}
-	
+
 	err := sessionStorage.SaveSession(session)
 	if err != nil {
 		t.Fatalf("Failed to save test session: %v", err)
 	}
-	
+
 	// Try to resume non-paused session
 	_, err = trainer.ResumeSession("active-session", exerciseList, sessionStorage)
 	if err == nil {
@@ -315,7 +317,7 @@ func TestResumeNonPausedSession(t *testing.T) {
 func TestListUserSessions(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	// Create test sessions
 	sessions := []*models.TrainingSession{
 		{
@@ -337,30 +339,30 @@ func TestListUserSessions(t *testing.T) {
 			StartTime: time.Now(),
 		},
 	}
-	
+
 	// Save all sessions
 	for _, session := range sessions {
 		if err := sessionStorage.SaveSession(session); err != nil {
 			t.Fatalf("Failed to save session %s: %v", session.SessionID, err)
 		}
 	}
-	
+
 	// Test listing sessions for user1
 	user1Sessions, err := trainer.ListUserSessions("user1", sessionStorage)
 	if err != nil {
 		t.Fatalf("Failed to list sessions for user1: %v", err)
 	}
-	
+
 	if len(user1Sessions) != 2 {
 		t.Errorf("Expected 2 sessions for user1, got %d", len(user1Sessions))
 	}
-	
+
 	// Test listing sessions for non-existent user
 	noSessions, err := trainer.ListUserSessions("non-existent", sessionStorage)
 	if err != nil {
 		t.Fatalf("Failed to list sessions for non-existent user: %v", err)
 	}
-	
+
 	if len(noSessions) != 0 {
 		t.Errorf("Expected 0 sessions for non-existent user, got %d", len(noSessions))
 	}
@@ -369,10 +371,10 @@ func TestListUserSessions(t *testing.T) {
 func TestNewCLTTrainerFromSession(t *testing.T) {
 	tempDir := t.TempDir()
 	sessionStorage := storage.NewFileSessionStorage(tempDir)
-	
+
 	registry := exercises.NewRegistry()
 	exerciseList := registry.GetAll()
-	
+
 	// Create test session
 	session := &models.TrainingSession{
 		UserID:       "test-user",
@@ -392,15 +394,61 @@ func TestNewCLTTrainerFromSession(t *testing.T) {
 			{ExerciseID: "basic-types", Score: 88.0},
 		},
 	}
-	
+
 	// Create trainer from session
 	trainer := trainer.NewCLTTrainerFromSession(session, exerciseList, sessionStorage)
-	
+
 	if trainer == nil {
 		t.Fatal("NewCLTTrainerFromSession returned nil")
 	}
-	
+
 	// Verify trainer was created with session data
 	// Note: We can't directly access private fields, but we can test the functionality
 	// by ensuring the trainer can be created without error
-}
\ No newline at end of file
+}
+
+func TestResumeSessionInUse(t *testing.T) {
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	session := &models.TrainingSession{
+		UserID:    "test-user",
+		SessionID: "locked",
+		Config:    models.TrainerConfig{MaxAttempts: 3},
+		Progress:  make([]models.LearningProgress, 1),
+		Status:    models.SessionPaused,
+	}
+	if err := sessionStorage.SaveSession(session); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	unlock, err := sessionStorage.LockSession("locked")
+	if err != nil {
+		t.Fatalf("Failed to lock session: %v", err)
+	}
+	_, err = trainer.ResumeSession("locked", []models.Exercise{pointExercise()}, sessionStorage)
+	if !errors.Is(err, storage.ErrSessionInUse) {
+		t.Fatalf("Expected the session to be in use, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "another trainer process") {
+		t.Errorf("Expected a clear error, got %q", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("Failed to unlock session: %v", err)
+	}
+
+	resumed, err := trainer.ResumeSession("locked", []models.Exercise{pointExercise()}, sessionStorage)
+	if err != nil {
+		t.Fatalf("Expected the released session to resume: %v", err)
+	}
+	if _, err := sessionStorage.LockSession("locked"); !errors.Is(err, storage.ErrSessionInUse) {
+		t.Errorf("Expected the resumed session to be held, got %v", err)
+	}
+
+	resumed.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", "quit"}})
+	resumed.Start()
+	unlock, err = sessionStorage.LockSession("locked")
+	if err != nil {
+		t.Errorf("Expected the session to be released when training ends: %v", err)
+	} else {
+		unlock()
+	}
+}
@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/exercises"
+	"github.com/cmyers78/claude/internal/validation"
+)
+
+func TestSolutionsPassValidators(t *testing.T) {
+	registry := exercises.NewRegistry()
+
+	for _, exercise := range registry.GetAll() {
+		for i, challenge := range exercise.Challenges {
+			if challenge.Validator == nil {
+				t.Errorf("%s challenge %d has no validator", exercise.ID, i+1)
+				continue
+			}
+			if !challenge.Validator(challenge.Solution) {
+				result := validation.Evaluate(challenge.Template, challenge.Solution, challenge.Rules...)
+				t.Errorf("%s challenge %d: solution rejected: parse=%v failures=%v",
+					exercise.ID, i+1, result.ParseError, result.Failures)
+			}
+		}
+	}
+}
+
+func TestValidatorIgnoresComments(t *testing.T) {
+	exercise := exercises.GetVariablesExercise()
+
+	if exercise.Challenges[0].Validator(`// var name string = "John"`) {
+		t.Error("Code inside a comment should not satisfy the challenge")
+	}
+}
+
+func TestInferredTypeValidator(t *testing.T) {
+	exercise := exercises.GetVariablesExercise()
+	validator := exercise.Challenges[1].Validator
+
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{`var name = "string theory"`, true}, // "string" only inside the value
+		{`var name string = "John"`, false},  // explicit type
+		{`name := "John"`, false},            // short declaration
+	}
+
+	for _, tc := range testCases {
+		if result := validator(tc.input); result != tc.expected {
+			t.Errorf("Input %q: expected %v, got %v", tc.input, tc.expected, result)
+		}
+	}
+}
+
+func TestFuncRuleResults(t *testing.T) {
+	template := "package main\n\n// Your function here"
+	rule := validation.Func("divide").WithResults("int", "int").Using(token.QUO, token.REM)
+
+	good := validation.Evaluate(template, "func divide(a, b int) (int, int) {\n\treturn a / b, a % b\n}", rule)
+	if !good.Passed() {
+		t.Errorf("Expected divide to pass, got %v %v", good.ParseError, good.Failures)
+	}
+
+	bad := validation.Evaluate(template, "func divide(a, b int) int {\n\treturn a / b\n}", rule)
+	if bad.Passed() {
+		t.Error("Expected single-result divide to fail")
+	}
+}
+
+func TestMethodReceiverRule(t *testing.T) {
+	template := "package main\n\ntype Account struct{ Balance float64 }\n\n// Add Deposit here"
+	rule := validation.Method("Account", "Deposit").WithPointerReceiver()
+
+	if !validation.Evaluate(template, "func (a *Account) Deposit(x float64) { a.Balance += x }", rule).Passed() {
+		t.Error("Pointer receiver should pass")
+	}
+
+	result := validation.Evaluate(template, "func (a Account) Deposit(x float64) { a.Balance += x }", rule)
+	if result.Passed() || len(result.Failures) != 1 {
+		t.Fatalf("Value receiver should fail with one failure, got %v", result.Failures)
+	}
+}
+
+func TestSubmissionSplicing(t *testing.T) {
+	template := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// Your code here\n\tfmt.Println(name)\n}"
+
+	sub, err := validation.Parse(template, "name := \"Go\"")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if sub.Mode != validation.Statements {
+		t.Errorf("Expected statement splicing, got %v", sub.Mode)
+	}
+
+	if line := sub.AnswerLine(sub.Offset + 1); line != 1 {
+		t.Errorf("Expected first answer line to map to 1, got %d", line)
+	}
+
+	if line := sub.AnswerLine(1); line != 0 {
+		t.Errorf("Template line should map to 0, got %d", line)
+	}
+}
+
+func TestSolutionsCompile(t *testing.T) {
+	registry := exercises.NewRegistry()
+
+	for _, exercise := range registry.GetAll() {
+		for i, challenge := range exercise.Challenges {
+			if _, diagnostics := validation.Compile(challenge.Template, challenge.Solution); len(diagnostics) > 0 {
+				t.Errorf("%s challenge %d: solution does not compile: %v", exercise.ID, i+1, diagnostics)
+			}
+		}
+	}
+}
+
+func TestCompileReportsAnswerLines(t *testing.T) {
+	template := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// Your code here\n\tfmt.Println(total)\n}"
+
+	testCases := []struct {
+		answer  string
+		line    int
+		message string
+	}{
+		{"x := 1.5\nvar total int = x", 2, "cannot use x (variable of type float64) as int value"},
+		{"total := 1\ncount := 2", 2, "declared and not used: count"},
+		{"total := 1 +", 0, ""}, // syntax error is still reported
+	}
+
+	for _, tc := range testCases {
+		_, diagnostics := validation.Compile(template, tc.answer)
+		if len(diagnostics) == 0 {
+			t.Errorf("Answer %q: expected diagnostics", tc.answer)
+			continue
+		}
+		if tc.message == "" {
+			continue
+		}
+		first := diagnostics[0]
+		if first.Line != tc.line || !strings.Contains(first.Message, tc.message) {
+			t.Errorf("Answer %q: expected line %d %q, got %s", tc.answer, tc.line, tc.message, first)
+		}
+	}
+}
+
+func TestCompileUndefinedName(t *testing.T) {
+	exercise := exercises.GetVariablesExercise()
+	challenge := exercise.Challenges[0]
+
+	_, diagnostics := validation.Compile(challenge.Template, `var nmae string = "Go"`)
+	found := false
+	for _, d := range diagnostics {
+		if strings.Contains(d.Message, "undefined: name") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected 'undefined: name' diagnostic, got %v", diagnostics)
+	}
+}
@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cmyers78/claude/internal/web"
+)
+
+func TestWebFrontEnd(t *testing.T) {
+	ts := httptest.NewServer(web.Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		path, contentType string
+		want              []string
+	}{
+		{"/", "text/html", []string{`<script src="app.js"`, `href="style.css"`, `id="answer"`, `id="pause"`, `id="resume"`, `id="hint"`}},
+		{"/app.js", "javascript", []string{"'/api'", "X-Trainer-Passphrase", "function highlight("}},
+		{"/style.css", "text/css", []string{".editor", ".tok-keyword"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := ts.Client().Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatalf("GET %s failed: %v", tt.path, err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+
+			if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Header.Get("Content-Type"), tt.contentType) {
+				t.Fatalf("Expected %s, got %d %s", tt.contentType, resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+			if csp := resp.Header.Get("Content-Security-Policy"); !strings.Contains(csp, "default-src 'self'") {
+				t.Errorf("Expected a policy keeping the page to its own files, got %q", csp)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(body), want) {
+					t.Errorf("Expected %s to contain %q", tt.path, want)
+				}
+			}
+			// Nothing may come from another host: training rooms can be offline
+			for _, external := range []string{"http://", "https://", "//cdn", "@import"} {
+				if strings.Contains(string(body), external) {
+					t.Errorf("Expected %s to load nothing from other hosts, found %q", tt.path, external)
+				}
+			}
+		})
+	}
+}
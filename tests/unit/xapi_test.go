@@ -0,0 +1,508 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cmyers78/claude/internal/models"
+	"github.com/cmyers78/claude/internal/storage"
+	"github.com/cmyers78/claude/internal/trainer"
+	"github.com/cmyers78/claude/internal/xapi"
+)
+
+// fakeLRS is an in-process Learning Record Store. It checks statements as
+// a real LRS would, keeps each ID once, and can be told to fail requests
+// or be unreachable. Its client reaches it without a network.
+type fakeLRS struct {
+	mu         sync.Mutex
+	statements []xapi.Statement
+	seen       map[string]bool
+	requests   int
+	tries      int   // Requests made, including those failing offline
+	fail       []int // Statuses to answer the next requests with
+	offline    bool
+	held       chan chan struct{} // When set, each request is sent here and waits for it to be closed
+}
+
+func newFakeLRS() *fakeLRS {
+	return &fakeLRS{seen: make(map[string]bool)}
+}
+
+// failNext answers the next requests with these statuses
+func (l *fakeLRS) failNext(statuses ...int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fail = append(l.fail, statuses...)
+}
+
+func (l *fakeLRS) setOffline(offline bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.offline = offline
+}
+
+func (l *fakeLRS) stored() []xapi.Statement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]xapi.Statement(nil), l.statements...)
+}
+
+func (l *fakeLRS) client() *http.Client {
+	return &http.Client{Transport: l}
+}
+
+// RoundTrip serves requests in process, or fails like a network that's down
+func (l *fakeLRS) RoundTrip(request *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	l.tries++
+	offline, held := l.offline, l.held
+	l.mu.Unlock()
+	if held != nil {
+		release := make(chan struct{})
+		held <- release
+		<-release
+	}
+	if offline {
+		return nil, errors.New("connection refused")
+	}
+	recorder := httptest.NewRecorder()
+	l.ServeHTTP(recorder, request)
+	return recorder.Result(), nil
+}
+
+func (l *fakeLRS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.requests++
+	if len(l.fail) > 0 {
+		status := l.fail[0]
+		l.fail = l.fail[1:]
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/statements") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("X-Experience-API-Version") != xapi.Version {
+		http.Error(w, "missing X-Experience-API-Version", http.StatusBadRequest)
+		return
+	}
+
+	var statements []xapi.Statement
+	if err := json.NewDecoder(r.Body).Decode(&statements); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, s := range statements {
+		if s.ID == "" || s.Actor.Account == nil || s.Actor.Account.Name == "" || s.Verb.ID == "" || s.Object.ID == "" || s.Timestamp.IsZero() {
+			http.Error(w, "statement missing required properties", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ids := []string{}
+	for _, s := range statements {
+		ids = append(ids, s.ID)
+		if !l.seen[s.ID] {
+			l.seen[s.ID] = true
+			l.statements = append(l.statements, s)
+		}
+	}
+	json.NewEncoder(w).Encode(ids)
+}
+
+// verbNames lists the last part of each statement's verb ID
+func verbNames(statements []xapi.Statement) string {
+	var names []string
+	for _, s := range statements {
+		names = append(names, s.Verb.ID[strings.LastIndex(s.Verb.ID, "/")+1:])
+	}
+	return strings.Join(names, " ")
+}
+
+// xapiSession is a saved session that paused after completing one exercise
+// and answering one challenge of the next
+func xapiSession() *models.TrainingSession {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	done := start.Add(90 * time.Second)
+	paused := start.Add(5 * time.Minute)
+	return &models.TrainingSession{
+		SessionID: "session-1", UserID: "alice", Status: models.SessionPaused,
+		StartTime: start, PausedAt: &paused, ActiveTime: 4 * time.Minute,
+		Progress: []models.LearningProgress{
+			{
+				ExerciseID: "variables", StartTime: start, CompletedAt: &done, Score: 85, TimeSpent: 90 * time.Second,
+				AttemptHistory: []models.AttemptRecord{
+					{Challenge: 0, Attempt: 1, SubmittedAt: start.Add(30 * time.Second), Answer: "x = 1", CompileError: true},
+					{Challenge: 0, Attempt: 2, SubmittedAt: start.Add(time.Minute), Answer: "x := 1", Passed: true},
+				},
+			},
+			{
+				ExerciseID: "points", StartTime: done.Add(time.Second),
+				AttemptHistory: []models.AttemptRecord{
+					{Challenge: 0, Attempt: 1, SubmittedAt: done.Add(time.Minute), Answer: "type Point struct{}", FailedTests: []string{"TestFields"}},
+				},
+			},
+		},
+	}
+}
+
+func TestXAPISessionStatements(t *testing.T) {
+	available := []models.Exercise{{ID: "variables", Title: "Variables", Description: "Declare variables"}, pointExercise()}
+	builder := xapi.Builder{ActivityBase: "https://example.com/go/", LearnerName: "Alice"}
+
+	var statements []xapi.Statement
+	for _, event := range xapi.SessionEvents(xapiSession(), available) {
+		statements = append(statements, builder.Statements(event)...)
+	}
+	if got := verbNames(statements); got != "attempted attempted answered answered completed passed attempted answered suspended" {
+		t.Fatalf("Unexpected statements: %s", got)
+	}
+
+	course, completed, passed := statements[0], statements[4], statements[5]
+	if course.Object.ID != "https://example.com/go/course" || course.Actor.Account.Name != "alice" || course.Actor.Name != "Alice" {
+		t.Errorf("Unexpected course statement: %+v", course)
+	}
+	if completed.Object.ID != "https://example.com/go/exercises/variables" || completed.Result.Duration != "PT1M30S" {
+		t.Errorf("Unexpected completion: %+v %+v", completed.Object, completed.Result)
+	}
+	if score := passed.Result.Score; score == nil || score.Scaled != 0.85 || !*passed.Result.Success {
+		t.Errorf("Expected a passing score of 0.85, got %+v", passed.Result)
+	}
+	if parent := completed.Context.ContextActivities.Parent; len(parent) != 1 || parent[0].ID != course.Object.ID {
+		t.Errorf("Expected the exercise to belong to the course, got %+v", parent)
+	}
+
+	answered := statements[7]
+	if answered.Object.ID != "https://example.com/go/exercises/points/challenges/1" || *answered.Result.Success || answered.Result.Response != "type Point struct{}" {
+		t.Errorf("Unexpected answer statement: %+v %+v", answered.Object, answered.Result)
+	}
+	if failed := answered.Result.Extensions["https://example.com/go/extensions/failed-tests"]; failed == nil {
+		t.Errorf("Expected the failed tests to be recorded, got %v", answered.Result.Extensions)
+	}
+	if suspended := statements[8]; suspended.Result.Duration != "PT4M" || !suspended.Timestamp.Equal(*xapiSession().PausedAt) {
+		t.Errorf("Expected the pause with the active time, got %+v at %v", suspended.Result, suspended.Timestamp)
+	}
+
+	// Building again gives the same IDs, so an LRS keeps each statement once
+	ids := make(map[string]bool)
+	for _, event := range xapi.SessionEvents(xapiSession(), available) {
+		for _, s := range builder.Statements(event) {
+			ids[s.ID] = true
+		}
+	}
+	for _, s := range statements {
+		if !ids[s.ID] {
+			t.Fatalf("Expected statement IDs to be deterministic, %s changed", s.ID)
+		}
+	}
+	if len(ids) != len(statements) {
+		t.Errorf("Expected %d distinct IDs, got %d", len(statements), len(ids))
+	}
+
+	// A stricter passing score fails the same exercise
+	strict := xapi.Builder{PassingScore: 90}
+	event := xapi.SessionEvents(xapiSession(), available)[4]
+	if got := verbNames(strict.Statements(event)); event.Type != trainer.ExerciseCompleted || got != "completed failed" {
+		t.Errorf("Expected a score of 85 to fail a passing score of 90, got %s for %s", got, event.Type)
+	}
+}
+
+func TestXAPISpool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xapi", "statements.ndjson")
+	if statements, err := xapi.ReadSpool(path); err != nil || statements != nil {
+		t.Fatalf("Expected a missing spool to be empty, got %v (%v)", statements, err)
+	}
+
+	var statements []xapi.Statement
+	for _, event := range xapi.SessionEvents(xapiSession(), nil) {
+		statements = append(statements, xapi.Builder{}.Statements(event)...)
+	}
+	spool := &xapi.Spool{Path: path}
+	if err := spool.Send(statements[:4]); err != nil {
+		t.Fatalf("Failed to spool statements: %v", err)
+	}
+	if err := spool.Send(statements[4:]); err != nil {
+		t.Fatalf("Failed to spool statements: %v", err)
+	}
+
+	// A crash partway through a write leaves a torn last line
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open spool: %v", err)
+	}
+	file.WriteString(`{"id":"torn","actor":`)
+	file.Close()
+
+	read, err := xapi.ReadSpool(path)
+	if err != nil || len(read) != len(statements) {
+		t.Fatalf("Expected %d statements back, got %d (%v)", len(statements), len(read), err)
+	}
+	if read[0].ID != statements[0].ID || !read[0].Timestamp.Equal(statements[0].Timestamp) {
+		t.Errorf("Expected statements to round trip, got %+v", read[0])
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the spool to be private, got %v (%v)", info.Mode(), err)
+	}
+}
+
+func TestXAPIClientRetriesAndBuffers(t *testing.T) {
+	lrs := newFakeLRS()
+	client := xapi.NewClient("https://lrs.example.com/xapi/", &xapi.Spool{Path: filepath.Join(t.TempDir(), "buffer.ndjson")})
+	client.HTTPClient = lrs.client()
+	client.Backoff = time.Millisecond
+
+	var statements []xapi.Statement
+	for _, event := range xapi.SessionEvents(xapiSession(), nil) {
+		statements = append(statements, xapi.Builder{}.Statements(event)...)
+	}
+
+	// Temporary failures are retried
+	lrs.failNext(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	if err := client.Send(statements[:2]); err != nil {
+		t.Fatalf("Expected retries to get through, got %v", err)
+	}
+	if lrs.requests != 3 || len(lrs.stored()) != 2 {
+		t.Fatalf("Expected 3 requests storing 2 statements, got %d storing %d", lrs.requests, len(lrs.stored()))
+	}
+
+	// Offline, statements are buffered rather than lost
+	lrs.setOffline(true)
+	if err := client.Send(statements[2:5]); err != nil {
+		t.Fatalf("Expected statements to be buffered, got %v", err)
+	}
+	if err := client.Send(statements[5:]); err != nil {
+		t.Fatalf("Expected statements to be buffered, got %v", err)
+	}
+	if pending, err := client.Pending(); err != nil || pending != len(statements)-2 {
+		t.Fatalf("Expected %d buffered statements, got %d (%v)", len(statements)-2, pending, err)
+	}
+
+	// Back online, the buffer is sent in order and emptied
+	lrs.setOffline(false)
+	if err := client.Flush(); err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+	stored := lrs.stored()
+	if pending, _ := client.Pending(); pending != 0 || len(stored) != len(statements) {
+		t.Fatalf("Expected everything sent, got %d stored and %d pending", len(stored), pending)
+	}
+	for i := range stored {
+		if stored[i].ID != statements[i].ID {
+			t.Fatalf("Expected statements in order, got %s at %d", verbNames(stored), i)
+		}
+	}
+
+	// Sending again is harmless: the LRS already has these IDs
+	if err := client.Send(statements); err != nil || len(lrs.stored()) != len(statements) {
+		t.Errorf("Expected a resend to store nothing new, got %d (%v)", len(lrs.stored()), err)
+	}
+}
+
+func TestXAPIClientFlushesOnce(t *testing.T) {
+	lrs := newFakeLRS()
+	buffer := &xapi.Spool{Path: filepath.Join(t.TempDir(), "buffer.ndjson")}
+	newClient := func() *xapi.Client {
+		client := xapi.NewClient("https://lrs.example.com/xapi", buffer)
+		client.HTTPClient = lrs.client()
+		client.Backoff = time.Millisecond
+		return client
+	}
+	first, second := newClient(), newClient()
+
+	var statements []xapi.Statement
+	for _, event := range xapi.SessionEvents(xapiSession(), nil) {
+		statements = append(statements, xapi.Builder{}.Statements(event)...)
+	}
+
+	// While the LRS is down, buffered statements get one try per send
+	lrs.setOffline(true)
+	if err := first.Send(statements[:2]); err != nil {
+		t.Fatalf("Expected statements to be buffered, got %v", err)
+	}
+	lrs.tries = 0
+	if err := first.Send(statements[2:4]); err != nil {
+		t.Fatalf("Expected statements to be buffered, got %v", err)
+	}
+	if lrs.tries != 1 {
+		t.Errorf("Expected one try to flush before buffering, got %d", lrs.tries)
+	}
+
+	// A second client neither flushes nor sends while the first is flushing
+	lrs.setOffline(false)
+	lrs.held = make(chan chan struct{})
+	flushed := make(chan error)
+	go func() { flushed <- first.Flush() }()
+	release := <-lrs.held
+	if err := second.Flush(); !errors.Is(err, xapi.ErrFlushing) {
+		t.Errorf("Expected ErrFlushing during another flush, got %v", err)
+	}
+	if err := second.Send(statements[4:]); err != nil {
+		t.Errorf("Expected statements to be buffered during another flush, got %v", err)
+	}
+	lrs.mu.Lock()
+	lrs.held = nil
+	lrs.mu.Unlock()
+	close(release)
+	if err := <-flushed; err != nil {
+		t.Fatalf("Failed to flush: %v", err)
+	}
+
+	// The flush sends what was buffered meanwhile, each statement once
+	stored := lrs.stored()
+	if pending, _ := first.Pending(); pending != 0 || len(stored) != len(statements) || lrs.requests != 2 {
+		t.Fatalf("Expected everything sent in 2 requests, got %d stored in %d, %d pending", len(stored), lrs.requests, pending)
+	}
+	for i := range stored {
+		if stored[i].ID != statements[i].ID {
+			t.Fatalf("Expected statements in order, got %s at %d", verbNames(stored), i)
+		}
+	}
+}
+
+func TestXAPIAppendDuringFlush(t *testing.T) {
+	lrs := newFakeLRS()
+	buffer := &xapi.Spool{Path: filepath.Join(t.TempDir(), "buffer.ndjson")}
+	client := xapi.NewClient("https://lrs.example.com/xapi", buffer)
+	client.HTTPClient = lrs.client()
+
+	// Another process keeps buffering while this one flushes
+	template := xapi.Builder{}.Statements(xapi.SessionEvents(xapiSession(), nil)[0])[0]
+	const appends = 1000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < appends; i++ {
+			statement := template
+			statement.ID = fmt.Sprintf("%s-%d", template.ID, i)
+			if err := buffer.Send([]xapi.Statement{statement}); err != nil {
+				t.Errorf("Failed to buffer statement: %v", err)
+				return
+			}
+		}
+	}()
+	for flushing := true; flushing; {
+		select {
+		case <-done:
+			flushing = false
+		default:
+		}
+		if err := client.Flush(); err != nil {
+			t.Fatalf("Failed to flush: %v", err)
+		}
+	}
+
+	if stored := lrs.stored(); len(stored) != appends {
+		t.Errorf("Expected every appended statement to be sent, got %d of %d", len(stored), appends)
+	}
+}
+
+func TestXAPIClientRejected(t *testing.T) {
+	lrs := newFakeLRS()
+	buffer := &xapi.Spool{Path: filepath.Join(t.TempDir(), "buffer.ndjson")}
+	client := xapi.NewClient("https://lrs.example.com/xapi", buffer)
+	client.HTTPClient = lrs.client()
+	client.Backoff = time.Millisecond
+
+	invalid := xapi.Builder{}.Statements(xapi.SessionEvents(xapiSession(), nil)[0])
+	invalid[0].Verb.ID = ""
+	err := client.Send(invalid)
+	var rejected *xapi.RejectedError
+	if !errors.As(err, &rejected) || rejected.Status != http.StatusBadRequest {
+		t.Fatalf("Expected the LRS to reject the statement, got %v", err)
+	}
+	if lrs.requests != 1 {
+		t.Errorf("Expected a rejection not to be retried, got %d requests", lrs.requests)
+	}
+	if pending, _ := client.Pending(); pending != 0 {
+		t.Errorf("Expected a rejected statement not to be buffered, got %d pending", pending)
+	}
+
+	// Other servers check credentials
+	lrs.failNext(http.StatusUnauthorized)
+	if err := client.Send(xapi.Builder{}.Statements(xapi.SessionEvents(xapiSession(), nil)[0])); !errors.As(err, &rejected) || rejected.Status != http.StatusUnauthorized {
+		t.Errorf("Expected an authorization failure, got %v", err)
+	}
+}
+
+func TestXAPIRecorderTrainer(t *testing.T) {
+	lrs := newFakeLRS()
+	client := xapi.NewClient("https://lrs.example.com/xapi", &xapi.Spool{Path: filepath.Join(t.TempDir(), "buffer.ndjson")})
+	client.HTTPClient = lrs.client()
+	spool := &xapi.Spool{Path: filepath.Join(t.TempDir(), "statements.ndjson")}
+	sessionStorage := storage.NewFileSessionStorage(t.TempDir())
+	answer := "type Point struct {\n\tX int\n\tY int\n}"
+	wrong := "type Point struct {\n\tX int\n\tZ int\n}"
+
+	// Answer one challenge, get one wrong, then pause
+	recorder := xapi.NewRecorder(xapi.Builder{}, xapi.Sinks{spool, client})
+	cltTrainer := trainer.NewCLTTrainer([]models.Exercise{pointExercise()}, models.TrainerConfig{MaxAttempts: 3}, "test-user", sessionStorage)
+	cltTrainer.AddListener(recorder)
+	cltTrainer.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", answer, wrong, "pause"}})
+	cltTrainer.Start()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	if got := verbNames(lrs.stored()); got != "attempted attempted answered answered suspended" {
+		t.Fatalf("Unexpected statements for the first sitting: %s", got)
+	}
+
+	// Resume and finish
+	sessions, err := sessionStorage.ListSessions("test-user")
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one paused session, got %v (%v)", sessions, err)
+	}
+	resumed, err := trainer.ResumeSession(sessions[0].SessionID, []models.Exercise{pointExercise()}, sessionStorage)
+	if err != nil {
+		t.Fatalf("Failed to resume: %v", err)
+	}
+	recorder = xapi.NewRecorder(xapi.Builder{}, xapi.Sinks{spool, client})
+	resumed.AddListener(recorder)
+	resumed.SetIO(trainer.NewTerminal(strings.NewReader(""), &bytes.Buffer{}), &scriptedInput{lines: []string{"", answer, answer, answer}})
+	resumed.Start()
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+
+	stored := lrs.stored()
+	want := "attempted attempted answered answered suspended resumed answered answered answered completed passed completed"
+	if got := verbNames(stored); got != want {
+		t.Fatalf("Unexpected statements:\n got %s\nwant %s", got, want)
+	}
+	for _, s := range stored {
+		if s.Actor.Account.Name != "test-user" || s.Context.Extensions[xapi.DefaultActivityBase+"/extensions/session"] != sessions[0].SessionID {
+			t.Fatalf("Expected statements about the learner's session, got %+v %+v", s.Actor, s.Context)
+		}
+	}
+	if spooled, err := xapi.ReadSpool(spool.Path); err != nil || verbNames(spooled) != want {
+		t.Errorf("Expected the spool to hold the same statements, got %s (%v)", verbNames(spooled), err)
+	}
+
+	// Statements rebuilt from the saved session match those sent live
+	completed, err := sessionStorage.LoadSession(sessions[0].SessionID)
+	if err != nil {
+		t.Fatalf("Failed to load session: %v", err)
+	}
+	live := make(map[string]bool)
+	for _, s := range stored {
+		live[s.ID] = true
+	}
+	for _, event := range xapi.SessionEvents(completed, []models.Exercise{pointExercise()}) {
+		for _, s := range (xapi.Builder{}).Statements(event) {
+			if event.Type != trainer.SessionPaused && !live[s.ID] {
+				t.Errorf("Expected the exported %s statement to match one sent live", event.Type)
+			}
+		}
+	}
+}